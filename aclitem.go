@@ -0,0 +1,277 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// aclPrivileges are the privilege letters of aclitem values, in the order
+// the server writes them.
+const aclPrivileges = "arwdDxtXUCTcsAm"
+
+// ACLItem represents a PostgreSQL aclitem value, such as
+// "alice=arw*/postgres": the privileges granted to Grantee by Grantor. An
+// empty Grantee is PUBLIC. Privileges holds the privilege letters, such as
+// "arw" for INSERT, SELECT and UPDATE, and GrantOption those of them that
+// may be granted on to others. Use a *ACLItem to scan nullable columns.
+type ACLItem struct {
+	Grantee     string
+	Grantor     string
+	Privileges  string
+	GrantOption string
+}
+
+// Scan implements the sql.Scanner interface.
+func (a *ACLItem) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return a.scanString(string(src))
+	case string:
+		return a.scanString(src)
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to ACLItem", src)
+}
+
+func (a *ACLItem) scanString(src string) error {
+	v, err := parseACLItem(strings.TrimSpace(src))
+	if err != nil {
+		return parseError("ACLItem", src, -1, err)
+	}
+
+	*a = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (a ACLItem) Value() (driver.Value, error) {
+	if a.Grantor == "" {
+		return nil, fmt.Errorf("pg: invalid ACLItem: missing grantor")
+	}
+	for _, c := range []byte(a.Privileges + a.GrantOption) {
+		if strings.IndexByte(aclPrivileges, c) < 0 {
+			return nil, fmt.Errorf("pg: invalid ACLItem: unknown privilege %q", c)
+		}
+	}
+	for _, c := range []byte(a.GrantOption) {
+		if strings.IndexByte(a.Privileges, c) < 0 {
+			return nil, fmt.Errorf("pg: invalid ACLItem: grant option for %q without the privilege", c)
+		}
+	}
+
+	return a.String(), nil
+}
+
+// String returns a in the server's output format.
+func (a ACLItem) String() string {
+	b := appendACLName(nil, a.Grantee)
+	b = append(b, '=')
+	for _, c := range []byte(aclPrivileges) {
+		if a.Has(c) {
+			b = append(b, c)
+			if a.HasGrantOption(c) {
+				b = append(b, '*')
+			}
+		}
+	}
+	b = append(b, '/')
+	return string(appendACLName(b, a.Grantor))
+}
+
+// Has reports whether a grants the privilege with the letter priv, such as
+// 'r' for SELECT.
+func (a ACLItem) Has(priv byte) bool {
+	return strings.IndexByte(a.Privileges, priv) >= 0
+}
+
+// HasGrantOption reports whether a allows the grantee to grant the
+// privilege with the letter priv on to others.
+func (a ACLItem) HasGrantOption(priv byte) bool {
+	return strings.IndexByte(a.GrantOption, priv) >= 0
+}
+
+// IsPublic reports whether a grants privileges to PUBLIC.
+func (a ACLItem) IsPublic() bool {
+	return a.Grantee == ""
+}
+
+func parseACLItem(s string) (ACLItem, error) {
+	var a ACLItem
+	grantee, i, err := parseACLName(s, 0)
+	if err != nil {
+		return a, err
+	}
+	if i >= len(s) || s[i] != '=' {
+		return a, fmt.Errorf("expected %q at offset %d", '=', i)
+	}
+	a.Grantee = grantee
+
+	var privs, grant []byte
+	for i++; i < len(s) && s[i] != '/'; i++ {
+		switch c := s[i]; {
+		case c == '*' && len(privs) > 0:
+			grant = append(grant, privs[len(privs)-1])
+		case strings.IndexByte(aclPrivileges, c) >= 0:
+			privs = append(privs, c)
+		default:
+			return a, fmt.Errorf("invalid privilege %q at offset %d", c, i)
+		}
+	}
+	a.Privileges, a.GrantOption = string(privs), string(grant)
+
+	if i >= len(s) {
+		return a, fmt.Errorf("expected %q at offset %d", '/', i)
+	}
+	if a.Grantor, i, err = parseACLName(s, i+1); err != nil {
+		return a, err
+	}
+	if a.Grantor == "" {
+		return a, fmt.Errorf("missing grantor")
+	}
+	if i != len(s) {
+		return a, fmt.Errorf("unexpected %q at offset %d", s[i], i)
+	}
+	return a, nil
+}
+
+// parseACLName parses a role name starting at offset i, which may be
+// double-quoted with doubled quotes inside.
+func parseACLName(s string, i int) (string, int, error) {
+	if i >= len(s) || s[i] != '"' {
+		start := i
+		for i < len(s) && s[i] != '=' && s[i] != '/' {
+			i++
+		}
+		return s[start:i], i, nil
+	}
+
+	var name []byte
+	for i++; i < len(s); i++ {
+		if s[i] == '"' {
+			if i+1 < len(s) && s[i+1] == '"' {
+				i++
+			} else {
+				return string(name), i + 1, nil
+			}
+		}
+		name = append(name, s[i])
+	}
+	return "", i, fmt.Errorf("unterminated quoted name")
+}
+
+// appendACLName appends name, quoting it if it has characters other than
+// letters, digits and underscores.
+func appendACLName(b []byte, name string) []byte {
+	safe := true
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_') {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return append(b, name...)
+	}
+
+	b = append(b, '"')
+	b = append(b, strings.ReplaceAll(name, `"`, `""`)...)
+	return append(b, '"')
+}
+
+// ACLItemArray represents an aclitem[] value, such as the relacl column of
+// pg_class. Scan reuses the backing array of Items when it is large enough,
+// overwriting it even if it fails.
+type ACLItemArray struct {
+	Items []ACLItem
+
+	// Options tunes parsing. It may be nil.
+	Options *ParseOptions
+}
+
+func (a *ACLItemArray) setOptions(opts *ParseOptions) {
+	a.Options = opts
+}
+
+// Scan implements the sql.Scanner interface.
+func (a *ACLItemArray) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return a.scanBytes(src)
+	case string:
+		return a.scanBytes([]byte(src))
+	case nil:
+		a.Items = nil
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to ACLItemArray", src)
+}
+
+func (a *ACLItemArray) scanBytes(src []byte) error {
+	return a.scanArray(src, nil)
+}
+
+// scanArray is like scanBytes, parsing with scratch rather than a pooled
+// arrayScratch if it is not nil.
+func (a *ACLItemArray) scanArray(src []byte, scratch *arrayScratch) error {
+	if scratch == nil {
+		scratch = getArrayScratch()
+		defer putArrayScratch(scratch)
+	}
+
+	elems, err := scratch.parseLinear(src, []byte{','}, "ACLItemArray", a.Options)
+	if err != nil {
+		return err
+	}
+	if elems, err = a.Options.nulls(elems, "ACLItem"); err != nil {
+		return err
+	}
+
+	items := reuseSlice(a.Items, len(elems))
+	for i, v := range elems {
+		items[i] = ACLItem{}
+		if v == nil {
+			continue
+		}
+		if err := items[i].scanString(string(v)); err != nil {
+			return fmt.Errorf("pg: parsing array element index %d: %w", i, err)
+		}
+	}
+	a.Items = items
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (a ACLItemArray) Value() (driver.Value, error) {
+	if a.Items == nil {
+		return nil, nil
+	}
+
+	b, err := a.AppendValue(nil)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// AppendValue appends the text of a to dst, like Value but without
+// allocating a string. A NULL value appends nothing.
+func (a ACLItemArray) AppendValue(dst []byte) ([]byte, error) {
+	if a.Items == nil {
+		return dst, nil
+	}
+
+	dst = append(dst, '{')
+	for i, item := range a.Items {
+		v, err := item.Value()
+		if err != nil {
+			return dst, err
+		}
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendArrayElement(dst, []byte(v.(string)))
+	}
+	return append(dst, '}'), nil
+}
@@ -0,0 +1,61 @@
+package pg
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestACLItemScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(ACLItem), `alice=arw/postgres`, ""},
+		{new(ACLItem), `alice=r*w/postgres`, ""},
+		{new(ACLItem), `=r/postgres`, ""},
+		{new(ACLItem), `"Bob Smith"=arwdDxtm/"a""b"`, ""},
+		{new(ACLItem), `alice=wr/postgres`, `alice=rw/postgres`},
+		{new(ACLItem), `alice=/postgres`, ""},
+		{new(ACLItemArray), `{}`, ""},
+		{new(ACLItemArray), `{=r/postgres,alice=arw*/postgres}`, ""},
+		{new(ACLItemArray), `{"\"a b\"=r/postgres"}`, ""},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(ACLItem) },
+		``,
+		`alice`,
+		`alice=r`,
+		`alice=z/postgres`,
+		`alice=r/`,
+		`"alice=r/postgres`,
+		`alice=r/postgres/x`,
+	)
+	testScanInvalid(t, func() sql.Scanner { return new(ACLItemArray) },
+		`{alice=r}`,
+		`{alice=r/postgres,NULL}`,
+	)
+	testScanNull(t, new(ACLItemArray))
+}
+
+func TestACLItemScan(t *testing.T) {
+	var a ACLItem
+	if err := a.Scan("alice=ar*w/postgres"); err != nil {
+		t.Fatal(err)
+	}
+	want := ACLItem{Grantee: "alice", Grantor: "postgres", Privileges: "arw", GrantOption: "r"}
+	if !reflect.DeepEqual(a, want) {
+		t.Fatalf("Scan = %+v, want %+v", a, want)
+	}
+	if !a.Has('w') || a.Has('d') || !a.HasGrantOption('r') || a.HasGrantOption('a') || a.IsPublic() {
+		t.Error("wrong privileges reported")
+	}
+}
+
+func TestACLItemValueInvalid(t *testing.T) {
+	for _, a := range []ACLItem{
+		{Grantee: "alice", Privileges: "r"},
+		{Grantee: "alice", Grantor: "postgres", Privileges: "rz"},
+		{Grantee: "alice", Grantor: "postgres", Privileges: "r", GrantOption: "w"},
+	} {
+		if v, err := a.Value(); err == nil {
+			t.Errorf("Value of %+v = %v, want error", a, v)
+		}
+	}
+}
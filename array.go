@@ -0,0 +1,829 @@
+package pg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseArray parses the text representation of a one-dimensional array,
+// such as `{1,"b,c",NULL}`, into its elements. NULL elements are returned as
+// nil.
+func ParseArray(src []byte) ([][]byte, error) {
+	return scanLinearArray(src, []byte{','}, "array", nil)
+}
+
+// ArrayDelimiter is implemented by types whose arrays separate elements with
+// something other than a comma. Box is one, its arrays being separated by
+// semicolons. For slices of composite attributes, CompositeArray and arrays
+// of registered types, the delimiter is taken from the element type, or a
+// pointer to it, if it implements ArrayDelimiter, both when scanning and
+// when formatting values.
+type ArrayDelimiter interface {
+	// ArrayDelimiter returns the delimiter of arrays of the type, the
+	// typdelim of its pg_type entry.
+	ArrayDelimiter() string
+}
+
+// ParseArrayDims parses the text representation of an array of any number
+// of dimensions, whose elements are separated by delim, into its dimensions
+// and its elements in storage order. The number of elements is always the
+// product of the dimensions, as sub-arrays of differing lengths are
+// rejected with an error matching ErrDimensionMismatch. An empty array has
+// no dimensions. NULL elements are returned as nil.
+func ParseArrayDims(src []byte, delim byte) (dims []int, elems [][]byte, err error) {
+	return parseArray(src, []byte{delim}, "array", nil)
+}
+
+// FormatArray formats elems into the text representation of a
+// one-dimensional array, quoting elements where needed. A nil element is
+// written as NULL.
+func FormatArray(elems [][]byte) string {
+	size := 1 + len(elems)
+	for _, elem := range elems {
+		if elem == nil {
+			size += len("NULL")
+		} else {
+			size += len(elem) + 2 + bytes.Count(elem, []byte{'"'}) + bytes.Count(elem, []byte{'\\'})
+		}
+	}
+	return string(appendArray(make([]byte, 0, size), elems))
+}
+
+func appendArray(b []byte, elems [][]byte) []byte {
+	b = append(b, '{')
+	for i, elem := range elems {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if elem == nil {
+			b = append(b, "NULL"...)
+		} else {
+			b = appendArrayElement(b, elem)
+		}
+	}
+	return append(b, '}')
+}
+
+// NormalizeArray returns the text representation of an array, whose
+// elements are separated by delim, in the canonical form the server outputs
+// it: without whitespace between elements, with elements quoted only where
+// needed and NULL written in upper case. Elements themselves are left as
+// they are, so the elements of an array of composites are not normalized.
+// Dimensions are kept, and an empty array of any dimensions becomes {}.
+//
+// The output of NormalizeArray and FormatArray, and that of Value for the
+// array types in this package, is always accepted by ParseArray and Scan,
+// which return the same elements it was formatted from. Normalizing the
+// output of NormalizeArray again returns it unchanged.
+func NormalizeArray(src []byte, delim byte) (string, error) {
+	del := []byte{delim}
+	dims, elems, err := parseArray(src, del, "array", nil)
+	if err != nil {
+		return "", err
+	}
+	size := 2 + len(elems)
+	for _, elem := range elems {
+		size += len(elem) + 2 + bytes.Count(elem, []byte{'"'}) + bytes.Count(elem, []byte{'\\'})
+	}
+	return string(appendArrayDims(make([]byte, 0, size), dims, elems, del)), nil
+}
+
+// appendArrayDims appends elems as an array of the dimensions dims, with
+// elements separated by del.
+func appendArrayDims(b []byte, dims []int, elems [][]byte, del []byte) []byte {
+	b = append(b, '{')
+	switch len(dims) {
+	case 0:
+	case 1:
+		for i, elem := range elems {
+			if i > 0 {
+				b = append(b, del...)
+			}
+			if elem == nil {
+				b = append(b, "NULL"...)
+			} else {
+				b = appendArrayElementDelim(b, elem, del)
+			}
+		}
+	default:
+		stride := len(elems) / dims[0]
+		for i := 0; i < dims[0]; i++ {
+			if i > 0 {
+				b = append(b, del...)
+			}
+			b = appendArrayDims(b, dims[1:], elems[i*stride:(i+1)*stride], del)
+		}
+	}
+	return append(b, '}')
+}
+
+// reuseSlice returns s resliced to length n if its capacity allows, and a
+// new slice otherwise. A nil s is never reused, so that an empty result is
+// not nil.
+func reuseSlice[T any](s []T, n int) []T {
+	if s != nil && cap(s) >= n {
+		return s[:n]
+	}
+	return make([]T, n)
+}
+
+// Element type OIDs of the binary array format.
+const (
+	byteaOID   = 17
+	nameOID    = 19
+	textOID    = 25
+	bpcharOID  = 1042
+	varcharOID = 1043
+
+	// firstNormalOID is the first OID assigned to user-created objects,
+	// such as types created by extensions.
+	firstNormalOID = 16384
+)
+
+// maxArrayDims is the server's limit on the number of array dimensions.
+const maxArrayDims = 6
+
+// isBinaryArray reports whether src is in the binary array format rather
+// than the text format. Binary arrays start with a big-endian dimension
+// count, whose first byte is always zero, while text arrays start with '{'
+// or a dimension decoration.
+func isBinaryArray(src []byte) bool {
+	return len(src) >= 12 && src[0] == 0
+}
+
+// parseBinaryArray parses an array in the binary format into the OID of its
+// element type, its dimensions and the binary representation of its
+// elements. NULL elements are returned as nil. The elements alias src.
+// Errors are reported for the type named typ.
+func parseBinaryArray(src []byte, typ string, opts *ParseOptions) (oid uint32, dims []int, elems [][]byte, err error) {
+	if len(src) < 12 {
+		return 0, nil, nil, arrayParseErrorf(typ, src, -1, "header too short")
+	}
+	ndims := int32(binary.BigEndian.Uint32(src))
+	flags := int32(binary.BigEndian.Uint32(src[4:]))
+	oid = binary.BigEndian.Uint32(src[8:])
+	if ndims < 0 || ndims > maxArrayDims {
+		return 0, nil, nil, arrayParseErrorf(typ, src, -1, "invalid number of dimensions %d", ndims)
+	}
+	if flags != 0 && flags != 1 {
+		return 0, nil, nil, arrayParseErrorf(typ, src, -1, "invalid flags %d", flags)
+	}
+	if err := opts.checkDepth(typ, src, -1, int(ndims)); err != nil {
+		return 0, nil, nil, err
+	}
+
+	i := 12
+	if len(src) < i+8*int(ndims) {
+		return 0, nil, nil, arrayParseErrorf(typ, src, -1, "dimensions too short")
+	}
+	dims = make([]int, ndims)
+	n := 1
+	for d := range dims {
+		size := int32(binary.BigEndian.Uint32(src[i:]))
+		if size < 0 {
+			return 0, nil, nil, arrayParseErrorf(typ, src, -1, "invalid dimension size %d", size)
+		}
+		// The lower bound is not needed.
+		dims[d] = int(size)
+		// Every element takes at least four bytes, for its length. The
+		// check is made before multiplying so that n cannot overflow.
+		if size > 0 && n > len(src)/4/int(size) {
+			return 0, nil, nil, arrayParseErrorf(typ, src, -1, "too many elements")
+		}
+		n *= int(size)
+		i += 8
+	}
+	if ndims == 0 {
+		n = 0
+	}
+	if err := opts.checkElems(typ, src, -1, n, 0); err != nil {
+		return 0, nil, nil, err
+	}
+
+	elems = make([][]byte, n)
+	total := 0
+	for e := range elems {
+		if len(src) < i+4 {
+			return 0, nil, nil, arrayParseErrorf(typ, src, i, "element %d truncated", e)
+		}
+		size := int32(binary.BigEndian.Uint32(src[i:]))
+		i += 4
+		if size == -1 {
+			if flags == 0 {
+				return 0, nil, nil, arrayParseErrorf(typ, src, i-4, "unexpected NULL element %d", e)
+			}
+			continue
+		}
+		if size < 0 || len(src)-i < int(size) {
+			return 0, nil, nil, arrayParseErrorf(typ, src, i, "element %d truncated", e)
+		}
+		total += int(size)
+		if err := opts.checkElems(typ, src, i, n, total); err != nil {
+			return 0, nil, nil, err
+		}
+		elems[e] = src[i : i+int(size) : i+int(size)]
+		i += int(size)
+	}
+	if i != len(src) {
+		return 0, nil, nil, arrayParseErrorf(typ, src, i, "%d bytes of trailing data", len(src)-i)
+	}
+	return oid, dims, elems, nil
+}
+
+// scanLinearBinaryArray is like parseBinaryArray, but requires the array to
+// have at most one dimension.
+func scanLinearBinaryArray(src []byte, typ string, opts *ParseOptions) (oid uint32, elems [][]byte, err error) {
+	oid, dims, elems, err := parseBinaryArray(src, typ, opts)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(dims) > 1 {
+		return 0, nil, fmt.Errorf("%w: cannot convert ARRAY%s to %s", ErrDimensionMismatch, strings.Replace(fmt.Sprint(dims), " ", "][", -1), typ)
+	}
+	return oid, elems, nil
+}
+
+// isTextOID reports whether oid is a built-in string type, whose binary
+// representation is its text.
+func isTextOID(oid uint32) bool {
+	switch oid {
+	case textOID, varcharOID, bpcharOID, nameOID:
+		return true
+	}
+	return false
+}
+
+// appendBinaryArray appends elems as a one-dimensional array in the binary
+// format, with elements of the type oid. A nil element is written as NULL.
+func appendBinaryArray(b []byte, oid uint32, elems [][]byte) []byte {
+	var flags uint32
+	for _, elem := range elems {
+		if elem == nil {
+			flags = 1
+			break
+		}
+	}
+
+	if len(elems) == 0 {
+		b = appendUint32(b, 0)
+	} else {
+		b = appendUint32(b, 1)
+	}
+	b = appendUint32(b, flags)
+	b = appendUint32(b, oid)
+	if len(elems) > 0 {
+		b = appendUint32(b, uint32(len(elems)))
+		b = appendUint32(b, 1)
+	}
+	for _, elem := range elems {
+		if elem == nil {
+			b = appendUint32(b, 0xffffffff)
+			continue
+		}
+		b = appendUint32(b, uint32(len(elem)))
+		b = append(b, elem...)
+	}
+	return b
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// ArrayIterator reads the elements of the text representation of an array
+// one at a time, without copying them or building a slice of them. The
+// elements of multidimensional arrays are read in storage order; the
+// iterator does not check that sub-arrays have matching dimensions.
+// Unless its Options are strict, whitespace around braces, delimiters and
+// elements is skipped.
+//
+//	it := pg.ArrayIter(src, ',')
+//	for it.Next() {
+//		if !it.IsNull() && bytes.Equal(it.Bytes(), label) {
+//			n++
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type ArrayIterator struct {
+	// Options tunes parsing. It may be nil, and must be set before the
+	// first call to Next. The NULL policy does not apply, as NULL
+	// elements are reported by IsNull.
+	Options *ParseOptions
+
+	src     []byte
+	delim   byte
+	pos     int
+	depth   int
+	ndims   int
+	started bool
+	done    bool
+	elem    []byte
+	quoted  bool
+	escaped bool
+	n       int
+	size    int
+	err     error
+}
+
+// ArrayIter returns an iterator over the elements of the array src, whose
+// elements are separated by delim.
+func ArrayIter(src []byte, delim byte) *ArrayIterator {
+	return &ArrayIterator{src: src, delim: delim}
+}
+
+// Next advances to the next element. It returns false at the end of the
+// array or after an error, see Err.
+func (it *ArrayIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	src := it.src
+	if !it.started {
+		it.started = true
+		it.pos = it.skip(0)
+		if it.pos >= len(src) || src[it.pos] != '{' {
+			return it.fail(arrayParseErrorf("array", src, it.pos, "expected %q", '{'))
+		}
+		// The number of leading braces is the number of dimensions.
+		for ; it.pos < len(src) && (src[it.pos] == '{' || it.space(src[it.pos])); it.pos++ {
+			if src[it.pos] == '{' {
+				it.ndims++
+			}
+		}
+		if err := it.Options.checkDepth("array", src, it.pos, it.ndims); err != nil {
+			return it.fail(err)
+		}
+		it.depth = it.ndims
+		if it.pos < len(src) && src[it.pos] == '}' {
+			if it.close() {
+				return it.fail(arrayParseErrorf("array", src, it.pos, "unexpected %q", src[it.pos]))
+			}
+			return false
+		}
+	} else {
+		// Move past the delimiter or closing braces after the
+		// previous element.
+		if !it.close() {
+			return false
+		}
+		it.pos++
+		for ; it.pos < len(src); it.pos++ {
+			if src[it.pos] == '{' && it.depth < it.ndims {
+				it.depth++
+			} else if !it.space(src[it.pos]) {
+				break
+			}
+		}
+	}
+	if it.pos >= len(src) {
+		return it.fail(arrayParseErrorf("array", src, it.pos, "expected %q", '}'))
+	}
+
+	i := it.pos
+	switch {
+	case it.depth != it.ndims || src[i] == '{':
+		return it.fail(arrayParseErrorf("array", src, i, "unexpected %q", src[i]))
+	case src[i] == '"':
+		it.quoted, it.escaped = true, false
+		for i++; i < len(src) && src[i] != '"'; i++ {
+			if src[i] == '\\' {
+				it.escaped = true
+				i++
+			}
+		}
+		if i >= len(src) {
+			return it.fail(arrayParseErrorf("array", src, len(src), "expected %q", '}'))
+		}
+		it.elem = src[it.pos+1 : i : i]
+	default:
+		it.quoted, it.escaped = false, false
+		// last is the end of the element without trailing whitespace.
+		last := i
+		for ; i < len(src) && src[i] != it.delim && src[i] != '}'; i++ {
+			if src[i] == '\\' {
+				it.escaped = true
+				if i++; i == len(src) {
+					break
+				}
+				last = i + 1
+			} else if !it.space(src[i]) {
+				last = i + 1
+			}
+		}
+		if i >= len(src) {
+			return it.fail(arrayParseErrorf("array", src, len(src), "expected %q", '}'))
+		}
+		if i == it.pos {
+			return it.fail(arrayParseErrorf("array", src, i, "unexpected %q", src[i]))
+		}
+		it.elem = src[it.pos:last:last]
+		i--
+	}
+	it.n++
+	it.size += len(it.elem)
+	if err := it.Options.checkElems("array", src, it.pos, it.n, it.size); err != nil {
+		return it.fail(err)
+	}
+	it.pos = i + 1
+	return true
+}
+
+// space reports whether c is whitespace to be skipped.
+func (it *ArrayIterator) space(c byte) bool {
+	return !it.Options.strict() && isArraySpace(c)
+}
+
+// skip returns the offset of the first byte at or after i that is not
+// whitespace to be skipped.
+func (it *ArrayIterator) skip(i int) int {
+	if it.Options.strict() {
+		return i
+	}
+	return skipArraySpace(it.src, i)
+}
+
+// close consumes any closing braces at the current position, stopping
+// before a delimiter. It returns false at the end of the array.
+func (it *ArrayIterator) close() bool {
+	src := it.src
+	it.pos = it.skip(it.pos)
+	for it.pos < len(src) && src[it.pos] == '}' {
+		it.depth--
+		it.pos = it.skip(it.pos + 1)
+		if it.depth == 0 {
+			it.done, it.elem = true, nil
+			if it.pos != len(src) {
+				return it.fail(trailingDataError("array", src, it.pos))
+			}
+			return false
+		}
+	}
+	if it.pos >= len(src) {
+		return it.fail(arrayParseErrorf("array", src, it.pos, "expected %q", '}'))
+	}
+	if src[it.pos] != it.delim {
+		return it.fail(arrayParseErrorf("array", src, it.pos, "unexpected %q", src[it.pos]))
+	}
+	return true
+}
+
+// Bytes returns the raw text of the current element, without the quotes of
+// a quoted element but with any backslash escapes left in, see Append. It
+// aliases the source and is only valid until the source is modified.
+func (it *ArrayIterator) Bytes() []byte {
+	return it.elem
+}
+
+// Quoted reports whether the current element is quoted.
+func (it *ArrayIterator) Quoted() bool {
+	return it.quoted
+}
+
+// IsNull reports whether the current element is NULL, that is an unquoted
+// NULL in any case.
+func (it *ArrayIterator) IsNull() bool {
+	return !it.quoted && !it.escaped && isNullElement(it.elem)
+}
+
+// Append appends the value of the current element to dst, removing its
+// backslash escapes.
+func (it *ArrayIterator) Append(dst []byte) []byte {
+	if !it.escaped {
+		return append(dst, it.elem...)
+	}
+	for i := 0; i < len(it.elem); i++ {
+		if it.elem[i] == '\\' {
+			i++
+		}
+		dst = append(dst, it.elem[i])
+	}
+	return dst
+}
+
+// Err returns the error, if any, that was encountered while iterating.
+func (it *ArrayIterator) Err() error {
+	return it.err
+}
+
+func (it *ArrayIterator) fail(err error) bool {
+	it.done, it.elem = true, nil
+	it.err = err
+	return false
+}
+
+// ReadArray parses the text representation of an array read from r, with
+// elements separated by delim, calling fn with the value of each element in
+// storage order. NULL elements are passed as nil. The bytes passed to fn are
+// only valid until it returns, so that memory use is bounded by the largest
+// element rather than by the whole array. Parsing stops at the first error,
+// including one returned by fn. Whitespace around braces, delimiters and
+// elements is skipped. As with ParseArrayDims, all sub-arrays at the same
+// depth must have the same number of elements; a mismatch is only found
+// when a sub-array closes, after fn was called for its elements.
+func ReadArray(r io.Reader, delim byte, fn func(elem []byte) error) error {
+	return ReadArrayOptions(r, delim, nil, fn)
+}
+
+// ReadArrayOptions is like ReadArray, parsing with opts. The limit on the
+// total length of elements bounds the memory used for a single element as
+// well. The NULL policy does not apply, as NULL elements are passed to fn.
+func ReadArrayOptions(r io.Reader, delim byte, opts *ParseOptions, fn func(elem []byte) error) error {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	a := arrayReader{r: br, opts: opts}
+	return a.read(delim, fn)
+}
+
+type arrayReader struct {
+	r    io.ByteReader
+	opts *ParseOptions
+	off  int // offset of the next byte
+	buf  []byte
+	n    int // number of elements read
+	size int // total length of the elements read
+
+	// last holds the last bytes read, the one at offset i being at
+	// i%maxSnippet, as the snippet of errors.
+	last [maxSnippet]byte
+}
+
+// consume records c as read.
+func (a *arrayReader) consume(c byte) {
+	a.last[a.off%maxSnippet] = c
+	a.off++
+}
+
+// snippet returns the last bytes read, up to the next offset.
+func (a *arrayReader) snippet() []byte {
+	if a.off <= maxSnippet {
+		return append([]byte(nil), a.last[:a.off]...)
+	}
+	i := a.off % maxSnippet
+	return append(append(make([]byte, 0, maxSnippet), a.last[i:]...), a.last[:i]...)
+}
+
+// next returns the next byte, reporting a missing closing brace at the end
+// of the input.
+func (a *arrayReader) next() (byte, error) {
+	c, err := a.r.ReadByte()
+	if err == io.EOF {
+		return 0, arrayParseErrorf("array", a.snippet(), a.off, "expected %q", '}')
+	}
+	if err != nil {
+		return 0, err
+	}
+	a.consume(c)
+	return c, nil
+}
+
+// space reports whether c is whitespace to be skipped.
+func (a *arrayReader) space(c byte) bool {
+	return !a.opts.strict() && isArraySpace(c)
+}
+
+// skip returns the first byte from c on that is not whitespace to be
+// skipped.
+func (a *arrayReader) skip(c byte) (byte, error) {
+	var err error
+	for a.space(c) {
+		if c, err = a.next(); err != nil {
+			return 0, err
+		}
+	}
+	return c, nil
+}
+
+func (a *arrayReader) unexpected(c byte) error {
+	return arrayParseErrorf("array", a.snippet(), a.off-1, "unexpected %q", c)
+}
+
+func (a *arrayReader) read(delim byte, fn func(elem []byte) error) error {
+	var c byte
+	var err error
+	for {
+		c, err = a.r.ReadByte()
+		if err != nil || !a.space(c) {
+			break
+		}
+		a.consume(c)
+	}
+	if err == io.EOF || err == nil && c != '{' {
+		return arrayParseErrorf("array", a.snippet(), a.off, "expected %q", '{')
+	}
+	if err != nil {
+		return err
+	}
+	a.consume(c)
+
+	// The number of leading braces is the number of dimensions. starts
+	// holds the offset of the current sub-array at each depth.
+	var starts []int
+	for c == '{' || a.space(c) {
+		if c == '{' {
+			starts = append(starts, a.off-1)
+		}
+		if c, err = a.next(); err != nil {
+			return err
+		}
+	}
+	ndims := len(starts)
+	if err := a.opts.checkDepth("array", a.snippet(), a.off-1, ndims); err != nil {
+		return err
+	}
+	depth := ndims
+	if c == '}' {
+		return a.close(c, depth)
+	}
+	// As in parse, the dimensions are those of the first sub-array at
+	// each depth, and counts holds the number of elements so far of the
+	// current sub-array at each depth.
+	dims := make([]int, 2*ndims)
+	counts := dims[ndims:]
+	dims = dims[:ndims:ndims]
+
+	for {
+		if depth != ndims || c == '{' {
+			return a.unexpected(c)
+		}
+
+		a.buf = a.buf[:0]
+		var elem []byte
+		if c == '"' {
+			for {
+				if c, err = a.next(); err != nil {
+					return err
+				}
+				if c == '"' {
+					break
+				}
+				if c == '\\' {
+					if c, err = a.next(); err != nil {
+						return err
+					}
+				}
+				if err := a.append(c); err != nil {
+					return err
+				}
+			}
+			if c, err = a.next(); err != nil {
+				return err
+			}
+			if c, err = a.skip(c); err != nil {
+				return err
+			}
+			elem = a.buf
+			if elem == nil {
+				elem = []byte{}
+			}
+		} else {
+			// keep is the length of the element without trailing
+			// whitespace.
+			escaped, keep := false, 0
+			for c != delim && c != '}' {
+				literal := c == '\\'
+				if literal {
+					if c, err = a.next(); err != nil {
+						return err
+					}
+					escaped = true
+				}
+				if err := a.append(c); err != nil {
+					return err
+				}
+				if literal || !a.space(c) {
+					keep = len(a.buf)
+				}
+				if c, err = a.next(); err != nil {
+					return err
+				}
+			}
+			if len(a.buf) == 0 {
+				return a.unexpected(c)
+			}
+			if elem = a.buf[:keep]; !escaped && isNullElement(elem) {
+				elem = nil
+			}
+		}
+		a.n++
+		a.size += len(elem)
+		if err := a.checkElems(a.n, a.size); err != nil {
+			return err
+		}
+		if err := fn(elem); err != nil {
+			return err
+		}
+
+		for c == '}' {
+			n := counts[depth-1] + 1
+			if dims[depth-1] == 0 {
+				dims[depth-1] = n
+			} else if n != dims[depth-1] {
+				e := arrayParseErrorf("array", a.snippet(), starts[depth-1], "sub-array has %d elements, expected %d", n, dims[depth-1])
+				e.kind = ErrDimensionMismatch
+				return e
+			}
+			if depth--; depth == 0 {
+				return a.end()
+			}
+			if c, err = a.next(); err != nil {
+				return err
+			}
+			if c, err = a.skip(c); err != nil {
+				return err
+			}
+		}
+		if c != delim {
+			return a.unexpected(c)
+		}
+		counts[depth-1]++
+		if c, err = a.next(); err != nil {
+			return err
+		}
+		for c == '{' && depth < ndims || a.space(c) {
+			if c == '{' {
+				depth++
+				counts[depth-1] = 0
+				starts[depth-1] = a.off - 1
+			}
+			if c, err = a.next(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// append appends c to the current element, failing once the elements read
+// so far exceed the limit on their total length.
+func (a *arrayReader) append(c byte) error {
+	a.buf = append(a.buf, c)
+	if a.opts != nil && a.opts.MaxElementBytes > 0 {
+		return a.checkElems(a.n+1, a.size+len(a.buf))
+	}
+	return nil
+}
+
+// checkElems is like ParseOptions.checkElems for the input read so far. The
+// snippet is only taken once the limit is exceeded, as it is checked for
+// every byte of an element.
+func (a *arrayReader) checkElems(n, size int) error {
+	err := a.opts.checkElems("array", nil, a.off-1, n, size)
+	if e, ok := err.(*ParseError); ok {
+		e.Snippet = string(a.snippet())
+	}
+	return err
+}
+
+// close consumes the closing braces of an empty array, starting with c.
+func (a *arrayReader) close(c byte, depth int) error {
+	var err error
+	for c == '}' {
+		if depth--; depth == 0 {
+			return a.end()
+		}
+		if c, err = a.next(); err != nil {
+			return err
+		}
+		if c, err = a.skip(c); err != nil {
+			return err
+		}
+	}
+	return a.unexpected(c)
+}
+
+// end checks that only whitespace follows the final closing brace. The
+// error for any other data holds the start of that data as its snippet.
+func (a *arrayReader) end() error {
+	for {
+		c, err := a.r.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		a.consume(c)
+		if a.space(c) {
+			continue
+		}
+
+		off := a.off - 1
+		trailing := append(a.buf[:0], c)
+		for len(trailing) < maxSnippet {
+			if c, err = a.r.ReadByte(); err != nil {
+				break
+			}
+			trailing = append(trailing, c)
+		}
+		e := trailingDataError("array", trailing, 0)
+		e.Offset = off
+		return e
+	}
+}
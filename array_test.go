@@ -0,0 +1,517 @@
+package pg
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestParseArray(t *testing.T) {
+	tests := []struct {
+		src  string
+		want [][]byte
+	}{
+		{`{}`, [][]byte{}},
+		{`{a,b,c}`, [][]byte{[]byte("a"), []byte("b"), []byte("c")}},
+		{`{"a,b","c\"d","e\\f"}`, [][]byte{[]byte("a,b"), []byte(`c"d`), []byte(`e\f`)}},
+		{`{NULL,null,"NULL",""}`, [][]byte{nil, nil, []byte("NULL"), {}}},
+		{`{ a , "b c" }`, [][]byte{[]byte("a"), []byte("b c")}},
+		{"{\ta,\n\"b\"\r}", [][]byte{[]byte("a"), []byte("b")}},
+		{`{a b}`, [][]byte{[]byte("a b")}},
+		{`{a\,b}`, [][]byte{[]byte("a,b")}},
+	}
+	for _, tt := range tests {
+		got, err := ParseArray([]byte(tt.src))
+		if err != nil {
+			t.Errorf("ParseArray(%s): %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseArray(%s) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseArrayInvalid(t *testing.T) {
+	tests := []struct {
+		src  string
+		kind error
+	}{
+		{``, ErrInvalidArray},
+		{`a,b`, ErrInvalidArray},
+		{`{a,b`, ErrInvalidArray},
+		{`{"a}`, ErrInvalidArray},
+		{`{a,,b}`, ErrInvalidArray},
+		{`{a}}`, ErrInvalidArray},
+		{`{a} b`, ErrInvalidArray},
+		{`{{a},b}`, ErrInvalidArray},
+		{`{{a},{b,c}}`, ErrDimensionMismatch},
+		{`{{a},{b}}`, ErrDimensionMismatch},
+	}
+	for _, tt := range tests {
+		_, err := ParseArray([]byte(tt.src))
+		if !errors.Is(err, tt.kind) {
+			t.Errorf("ParseArray(%s) error = %v, want %v", tt.src, err, tt.kind)
+		}
+	}
+}
+
+func TestStringArrayScanErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		kind error
+	}{
+		{`{a,"b`, ErrInvalidArray},
+		{`{a,NULL}`, ErrNullElement},
+		{`{{a},{b}}`, ErrDimensionMismatch},
+		{`{{a},{b,c}}`, ErrDimensionMismatch},
+	}
+	for _, tt := range tests {
+		err := new(StringArray).Scan([]byte(tt.src))
+		if !errors.Is(err, tt.kind) {
+			t.Errorf("Scan(%s) error = %v, want %v", tt.src, err, tt.kind)
+		}
+		if err != nil && strings.HasPrefix(err.Error(), "pq:") {
+			t.Errorf("Scan(%s) error = %v, want a pg error", tt.src, err)
+		}
+	}
+
+	// Mismatched sub-arrays are also invalid arrays.
+	if _, err := ParseArray([]byte(`{{a},{b,c}}`)); !errors.Is(err, ErrInvalidArray) {
+		t.Errorf("error = %v, want %v", err, ErrInvalidArray)
+	}
+}
+
+func TestParseArrayDimsSpace(t *testing.T) {
+	dims, elems, err := ParseArrayDims([]byte(` { { a } , { "b" } } `), ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{2, 1}; !reflect.DeepEqual(dims, want) {
+		t.Errorf("dims = %v, want %v", dims, want)
+	}
+	if want := [][]byte{[]byte("a"), []byte("b")}; !reflect.DeepEqual(elems, want) {
+		t.Errorf("elems = %q, want %q", elems, want)
+	}
+
+	var got []string
+	err = ReadArray(strings.NewReader(` { { a } , { "b" } } `), ',', func(elem []byte) error {
+		got = append(got, string(elem))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadArray elements = %q, want %q", got, want)
+	}
+}
+
+func TestParseArrayNull(t *testing.T) {
+	got, err := ParseArray([]byte(`{NULL,null,Null,nUlL,"null",N\ULL,nulls,NUL}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]byte{nil, nil, nil, nil, []byte("null"), []byte("NULL"), []byte("nulls"), []byte("NUL")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	var elems [][]byte
+	err = ReadArray(strings.NewReader(`{null,"null"}`), ',', func(elem []byte) error {
+		if elem != nil {
+			elem = append([]byte{}, elem...)
+		}
+		elems = append(elems, elem)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]byte{nil, []byte("null")}; !reflect.DeepEqual(elems, want) {
+		t.Errorf("ReadArray elements = %q, want %q", elems, want)
+	}
+
+	it := ArrayIter([]byte(`{Null,"Null",N\ull}`), ',')
+	var nulls []bool
+	for it.Next() {
+		nulls = append(nulls, it.IsNull())
+	}
+	if want := []bool{true, false, false}; !reflect.DeepEqual(nulls, want) {
+		t.Errorf("IsNull = %v, want %v", nulls, want)
+	}
+
+	// Strings that would be read as NULL are quoted.
+	if got := FormatArray([][]byte{nil, []byte("null"), []byte("Null")}); got != `{NULL,"null","Null"}` {
+		t.Errorf("FormatArray = %s", got)
+	}
+}
+
+func TestParseOptionsLimits(t *testing.T) {
+	tests := []struct {
+		src  string
+		opts ParseOptions
+	}{
+		{`{a,b,c}`, ParseOptions{MaxElements: 2}},
+		{`{{{a}}}`, ParseOptions{MaxDepth: 2}},
+		{`{abc,def}`, ParseOptions{MaxElementBytes: 5}},
+	}
+	for _, tt := range tests {
+		a := StringArray{Options: &tt.opts}
+		err := a.Scan([]byte(tt.src))
+		if !errors.Is(err, ErrLimitExceeded) || !errors.Is(err, ErrInvalidArray) {
+			t.Errorf("Scan(%s) with %+v error = %v, want a limit error", tt.src, tt.opts, err)
+		}
+	}
+}
+
+func TestParseOptionsLimitsReached(t *testing.T) {
+	opts := &ParseOptions{MaxElements: 3, MaxDepth: 1, MaxElementBytes: 3}
+	a := StringArray{Options: opts}
+	if err := a.Scan([]byte(`{a,b,c}`)); err != nil {
+		t.Errorf("Scan at the limits: %v", err)
+	}
+
+	limited := func(err error) bool {
+		return errors.Is(err, ErrLimitExceeded)
+	}
+	src := []byte(`{a,b,c,d}`)
+	b, _ := StringArray{Strings: []string{"a", "b", "c", "d"}}.MarshalBinary()
+	if err := a.Scan(b); !limited(err) {
+		t.Errorf("binary Scan error = %v, want a limit error", err)
+	}
+	it := ArrayIter(src, ',')
+	it.Options = opts
+	for it.Next() {
+	}
+	if !limited(it.Err()) {
+		t.Errorf("ArrayIterator error = %v, want a limit error", it.Err())
+	}
+	err := ReadArrayOptions(bytes.NewReader(src), ',', opts, func([]byte) error { return nil })
+	if !limited(err) {
+		t.Errorf("ReadArray error = %v, want a limit error", err)
+	}
+
+	// The length of one element is limited while it is read.
+	err = ReadArrayOptions(strings.NewReader(`{"`+strings.Repeat("a", 100)), ',', opts, func([]byte) error { return nil })
+	if !limited(err) {
+		t.Errorf("ReadArray error = %v, want a limit error", err)
+	}
+}
+
+func TestParseArrayUnquotedEscapes(t *testing.T) {
+	tests := []struct {
+		src  string
+		want [][]byte
+	}{
+		{`{a\,b,c}`, [][]byte{[]byte("a,b"), []byte("c")}},
+		{`{a\}b}`, [][]byte{[]byte("a}b")}},
+		{`{a\\b}`, [][]byte{[]byte(`a\b`)}},
+		{`{\"a\"}`, [][]byte{[]byte(`"a"`)}},
+		{`{\{a\}}`, [][]byte{[]byte("{a}")}},
+		{`{a\ ,b}`, [][]byte{[]byte("a "), []byte("b")}},
+		{`{\NULL}`, [][]byte{[]byte("NULL")}},
+	}
+	for _, tt := range tests {
+		got, err := ParseArray([]byte(tt.src))
+		if err != nil {
+			t.Errorf("ParseArray(%s): %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseArray(%s) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+
+	for _, src := range []string{`{a\`, `{a\}`} {
+		if got, err := ParseArray([]byte(src)); err == nil {
+			t.Errorf("ParseArray(%s) = %q, want error", src, got)
+		}
+	}
+}
+
+func TestParseArrayDims(t *testing.T) {
+	tests := []struct {
+		src   string
+		delim byte
+		dims  []int
+		elems []string
+	}{
+		{`{}`, ',', nil, []string{}},
+		{`{{}}`, ',', nil, []string{}},
+		{`{1,2,3}`, ',', []int{3}, []string{"1", "2", "3"}},
+		{`{{1,2},{3,4},{5,6}}`, ',', []int{3, 2}, []string{"1", "2", "3", "4", "5", "6"}},
+		{`{{{a}},{{b}}}`, ',', []int{2, 1, 1}, []string{"a", "b"}},
+		{`{(0,0),(1,1);(2,2),(3,3)}`, ';', []int{2}, []string{"(0,0),(1,1)", "(2,2),(3,3)"}},
+	}
+	for _, tt := range tests {
+		dims, elems, err := ParseArrayDims([]byte(tt.src), tt.delim)
+		if err != nil {
+			t.Errorf("ParseArrayDims(%s): %v", tt.src, err)
+			continue
+		}
+		got := make([]string, len(elems))
+		for i, e := range elems {
+			got[i] = string(e)
+		}
+		if !reflect.DeepEqual(dims, tt.dims) || !reflect.DeepEqual(got, tt.elems) {
+			t.Errorf("ParseArrayDims(%s) = %v, %q, want %v, %q", tt.src, dims, got, tt.dims, tt.elems)
+		}
+	}
+}
+
+func TestReadArrayDims(t *testing.T) {
+	// ReadArray accepts the arrays ParseArrayDims accepts.
+	for _, src := range []string{
+		`{}`,
+		`{{}}`,
+		`{a,b,c}`,
+		`{{1,2},{3,4},{5,6}}`,
+		`{{{a}},{{b}}}`,
+		`{ { a , b } , { c , d } }`,
+		`{{a},{b,c}}`,
+		`{{a,b},{c}}`,
+		`{{a,b},{c,d},{e}}`,
+		`{{{a,b}},{{c}}}`,
+		`{{{a}},{{b},{c}}}`,
+		`{{a},b}`,
+		`{a,{b}}`,
+	} {
+		_, _, perr := ParseArrayDims([]byte(src), ',')
+		rerr := ReadArray(strings.NewReader(src), ',', func([]byte) error { return nil })
+		if (perr == nil) != (rerr == nil) {
+			t.Errorf("%s: ParseArrayDims error = %v, ReadArray error = %v", src, perr, rerr)
+		}
+		if errors.Is(perr, ErrDimensionMismatch) != errors.Is(rerr, ErrDimensionMismatch) {
+			t.Errorf("%s: ParseArrayDims error = %v, ReadArray error = %v, want the same kind", src, perr, rerr)
+		}
+	}
+
+	err := ReadArray(strings.NewReader(`{{1,2},{3}}`), ',', func([]byte) error { return nil })
+	var perr *ParseError
+	if !errors.As(err, &perr) || !errors.Is(err, ErrDimensionMismatch) || perr.Offset != 7 {
+		t.Errorf("ReadArray error = %v, want a dimension mismatch at offset 7", err)
+	}
+}
+
+func TestFormatArray(t *testing.T) {
+	tests := []struct {
+		elems [][]byte
+		want  string
+	}{
+		{[][]byte{}, `{}`},
+		{[][]byte{[]byte("a"), nil, []byte("NULL"), {}}, `{a,NULL,"NULL",""}`},
+		{[][]byte{[]byte("a,b"), []byte(`c"d`), []byte(`e\f`), []byte("g h")}, `{"a,b","c\"d","e\\f","g h"}`},
+	}
+	for _, tt := range tests {
+		got := FormatArray(tt.elems)
+		if got != tt.want {
+			t.Errorf("FormatArray(%q) = %s, want %s", tt.elems, got, tt.want)
+		}
+		back, err := ParseArray([]byte(got))
+		if err != nil || !reflect.DeepEqual(back, tt.elems) {
+			t.Errorf("ParseArray(%s) = %q, %v, want %q", got, back, err, tt.elems)
+		}
+	}
+}
+
+func TestNormalizeArray(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{`{}`, `{}`},
+		{`{ {} }`, `{}`},
+		{`{ a , "b" , null }`, `{a,b,NULL}`},
+		{`{"a b","",NULL,"NULL"}`, `{"a b","",NULL,"NULL"}`},
+		{`{{ 1 , 2 },{"3",4}}`, `{{1,2},{3,4}}`},
+		{`{a\,b,"c\\d"}`, `{"a,b","c\\d"}`},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeArray([]byte(tt.src), ',')
+		if err != nil {
+			t.Errorf("NormalizeArray(%s): %v", tt.src, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeArray(%s) = %s, want %s", tt.src, got, tt.want)
+		}
+		if again, err := NormalizeArray([]byte(got), ','); err != nil || again != got {
+			t.Errorf("NormalizeArray(%s) = %s, %v, want it unchanged", got, again, err)
+		}
+	}
+}
+
+func TestNormalizeArrayInvalid(t *testing.T) {
+	for _, src := range []string{``, `{a`, `{a,,b}`, `{{a},{b,c}}`, `{a} b`} {
+		if got, err := NormalizeArray([]byte(src), ','); err == nil {
+			t.Errorf("NormalizeArray(%s) = %s, want error", src, got)
+		}
+	}
+	if got, err := NormalizeArray([]byte(`{ a ; "b;c" }`), ';'); err != nil || got != `{a;"b;c"}` {
+		t.Errorf("NormalizeArray with ';' = %s, %v", got, err)
+	}
+}
+
+func TestArrayIterator(t *testing.T) {
+	it := ArrayIter([]byte(`{a,"b\"c",NULL,"NULL"}`), ',')
+	var got []string
+	for it.Next() {
+		if it.IsNull() {
+			got = append(got, "<NULL>")
+		} else {
+			got = append(got, string(it.Append(nil)))
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", `b"c`, "<NULL>", "NULL"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("elements = %q, want %q", got, want)
+	}
+}
+
+func TestArrayIteratorRaw(t *testing.T) {
+	it := ArrayIter([]byte(`{{a;"b;c"};{"d\\e";f\;g}}`), ';')
+	type elem struct {
+		raw    string
+		quoted bool
+		value  string
+	}
+	var got []elem
+	for it.Next() {
+		got = append(got, elem{string(it.Bytes()), it.Quoted(), string(it.Append(nil))})
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []elem{
+		{"a", false, "a"},
+		{"b;c", true, "b;c"},
+		{`d\\e`, true, `d\e`},
+		{`f\;g`, false, "f;g"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("elements = %+v, want %+v", got, want)
+	}
+	if it.Next() {
+		t.Error("Next after the end = true")
+	}
+}
+
+func TestArrayIteratorSpace(t *testing.T) {
+	tests := []struct {
+		src    string
+		strict bool
+		want   []string
+	}{
+		{` { { a, b c } , {"d" ,e}} `, false, []string{"a", "b c", "d", "e"}},
+		{`{ a, b c , d }`, true, []string{" a", " b c ", " d "}},
+	}
+	for _, tt := range tests {
+		it := ArrayIter([]byte(tt.src), ',')
+		it.Options = &ParseOptions{Strict: tt.strict}
+		var got []string
+		for it.Next() {
+			got = append(got, string(it.Bytes()))
+		}
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("strict %v: elements = %q, want %q", tt.strict, got, tt.want)
+		}
+	}
+}
+
+func TestArrayIteratorInvalid(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`a`,
+		`{`,
+		`{a`,
+		`{a,`,
+		`{"a`,
+		`{a,,b}`,
+		`{a}}`,
+		`{a} x`,
+		`{{a},b}`,
+		`{a,{b}}`,
+	} {
+		it := ArrayIter([]byte(src), ',')
+		for it.Next() {
+		}
+		if it.Err() == nil {
+			t.Errorf("%q: expected error", src)
+		}
+		if it.Next() {
+			t.Errorf("%q: Next after an error = true", src)
+		}
+	}
+}
+
+func TestReadArray(t *testing.T) {
+	var got []string
+	err := ReadArray(strings.NewReader(`{{a,"b c"},{NULL,"d\\e"}}`), ',', func(elem []byte) error {
+		if elem == nil {
+			got = append(got, "<NULL>")
+		} else {
+			got = append(got, string(elem))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b c", "<NULL>", `d\e`}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("elements = %q, want %q", got, want)
+	}
+
+	err = ReadArray(strings.NewReader(`{a,b`), ',', func([]byte) error { return nil })
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Offset != 4 || perr.Snippet != "{a,b" {
+		t.Fatalf("ReadArray error = %#v", err)
+	}
+}
+
+func TestReadArrayReader(t *testing.T) {
+	// A reader without ReadByte is buffered.
+	var got []string
+	r := iotest.OneByteReader(strings.NewReader(`{a,"b,c"}`))
+	err := ReadArray(r, ',', func(elem []byte) error {
+		got = append(got, string(elem))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b,c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("elements = %q, want %q", got, want)
+	}
+
+	// An error from fn stops parsing.
+	errStop := errors.New("stop")
+	n := 0
+	err = ReadArray(strings.NewReader(`{a,b,c}`), ',', func([]byte) error {
+		n++
+		return errStop
+	})
+	if err != errStop || n != 1 {
+		t.Errorf("got %v after %d calls, want %v after 1", err, n, errStop)
+	}
+}
+
+func TestReuseSlice(t *testing.T) {
+	s := make([]int, 2, 4)
+	if r := reuseSlice(s, 4); len(r) != 4 || &r[0] != &s[0] {
+		t.Error("slice with enough capacity was not reused")
+	}
+	if r := reuseSlice(s, 5); len(r) != 5 || &r[0] == &s[0] {
+		t.Error("slice without enough capacity was reused")
+	}
+	if r := reuseSlice([]int(nil), 0); r == nil {
+		t.Error("reuseSlice(nil, 0) = nil")
+	}
+}
@@ -0,0 +1,58 @@
+package pg
+
+import "database/sql"
+
+// arrayScanner is implemented by the array types that can parse their text
+// representation with a caller-provided arrayScratch.
+type arrayScanner interface {
+	scanArray(src []byte, scratch *arrayScratch) error
+}
+
+// BatchScanner scans the values of one column, row after row, into the same
+// destination of type T, keeping its parse buffers between rows. It
+// implements sql.Scanner, so it can be given to Rows.Scan directly.
+//
+//	bs := pg.NewBatchScanner[pg.StringArray]()
+//	for rows.Next() {
+//		if err := rows.Scan(&id, bs); err != nil {
+//			return err
+//		}
+//		process(id, bs.V.Strings)
+//	}
+//
+// A BatchScanner is not safe for concurrent use.
+type BatchScanner[T any, PT interface {
+	*T
+	sql.Scanner
+}] struct {
+	// V holds the value scanned last. Memory it refers to may be reused
+	// by the next Scan, so values that are kept must be copied.
+	V T
+
+	// Null reports whether the value scanned last was NULL.
+	Null bool
+
+	scratch arrayScratch
+}
+
+// NewBatchScanner returns a BatchScanner for values of type T.
+func NewBatchScanner[T any, PT interface {
+	*T
+	sql.Scanner
+}]() *BatchScanner[T, PT] {
+	return &BatchScanner[T, PT]{}
+}
+
+// Scan implements the sql.Scanner interface.
+func (b *BatchScanner[T, PT]) Scan(src interface{}) error {
+	b.Null = src == nil
+	if a, ok := interface{}(PT(&b.V)).(arrayScanner); ok {
+		switch src := src.(type) {
+		case []byte:
+			return a.scanArray(src, &b.scratch)
+		case string:
+			return a.scanArray([]byte(src), &b.scratch)
+		}
+	}
+	return PT(&b.V).Scan(src)
+}
@@ -0,0 +1,57 @@
+package pg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBatchScanner(t *testing.T) {
+	bs := NewBatchScanner[StringArray]()
+	rows := []interface{}{
+		[]byte(`{a,"b\"c"}`),
+		nil,
+		`{d}`,
+		[]byte(`{"e\\f",g,h}`),
+	}
+	want := [][]string{{"a", `b"c`}, nil, {"d"}, {`e\f`, "g", "h"}}
+	for i, src := range rows {
+		if err := bs.Scan(src); err != nil {
+			t.Fatalf("Scan(%v): %v", src, err)
+		}
+		if bs.Null != (src == nil) {
+			t.Errorf("row %d: Null = %v", i, bs.Null)
+		}
+		if !reflect.DeepEqual(bs.V.Strings, want[i]) {
+			t.Errorf("row %d: got %q, want %q", i, bs.V.Strings, want[i])
+		}
+	}
+
+	// The parse buffers are kept between rows.
+	if cap(bs.scratch.elems) == 0 || cap(bs.scratch.buf) == 0 {
+		t.Error("scratch buffers were not kept")
+	}
+	src := []byte(`{"e\\f",g,h}`)
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := bs.Scan(src); err != nil {
+			t.Fatal(err)
+		}
+	})
+	// Only the strings of the elements are allocated.
+	if allocs > 3 {
+		t.Errorf("Scan allocated %v times, want at most 3", allocs)
+	}
+
+	if err := bs.Scan(1); err == nil {
+		t.Error("Scan(1): expected error")
+	}
+}
+
+func TestBatchScannerNonArray(t *testing.T) {
+	bs := NewBatchScanner[UUID]()
+	if err := bs.Scan([]byte("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11")); err != nil {
+		t.Fatal(err)
+	}
+	if bs.Null || bs.V.String() != "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11" {
+		t.Errorf("got %v, Null %v", bs.V, bs.Null)
+	}
+}
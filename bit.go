@@ -0,0 +1,112 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// BitString represents a PostgreSQL bit or varbit value of Len bits, packed
+// into Bytes with the first bit in the most significant bit of the first
+// byte. Bits past Len in the last byte are zero. Use a *BitString to scan
+// nullable columns.
+type BitString struct {
+	Bytes []byte
+	Len   int
+}
+
+// ParseBitString parses a string of '0' and '1' characters, such as
+// "10110".
+func ParseBitString(s string) (BitString, error) {
+	b := BitString{Bytes: make([]byte, (len(s)+7)/8), Len: len(s)}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '1':
+			b.Bytes[i/8] |= 0x80 >> uint(i%8)
+		case '0':
+		default:
+			return BitString{}, parseErrorf("BitString", s, i, "%q is not a valid binary digit", s[i])
+		}
+	}
+	return b, nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (b *BitString) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return b.scanString(string(src))
+	case string:
+		return b.scanString(src)
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to BitString", src)
+}
+
+func (b *BitString) scanString(src string) error {
+	v, err := ParseBitString(src)
+	if err != nil {
+		return err
+	}
+
+	*b = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (b BitString) Value() (driver.Value, error) {
+	if b.Len < 0 || b.Len > len(b.Bytes)*8 {
+		return nil, fmt.Errorf("pg: invalid BitString: length %d does not match %d bytes", b.Len, len(b.Bytes))
+	}
+
+	return b.String(), nil
+}
+
+// String returns b as a string of '0' and '1' characters.
+func (b BitString) String() string {
+	s := make([]byte, b.Len)
+	for i := range s {
+		if b.Test(i) {
+			s[i] = '1'
+		} else {
+			s[i] = '0'
+		}
+	}
+	return string(s)
+}
+
+// Test reports whether bit i, counting from 0 at the left, is set. It
+// returns false if i is out of range.
+func (b BitString) Test(i int) bool {
+	if i < 0 || i >= b.Len || i/8 >= len(b.Bytes) {
+		return false
+	}
+	return b.Bytes[i/8]&(0x80>>uint(i%8)) != 0
+}
+
+// And returns the bitwise AND of b and o, which must have the same length,
+// like the server's & operator.
+func (b BitString) And(o BitString) (BitString, error) {
+	return b.combine(o, "AND", func(x, y byte) byte { return x & y })
+}
+
+// Or returns the bitwise OR of b and o, which must have the same length,
+// like the server's | operator.
+func (b BitString) Or(o BitString) (BitString, error) {
+	return b.combine(o, "OR", func(x, y byte) byte { return x | y })
+}
+
+func (b BitString) combine(o BitString, op string, f func(x, y byte) byte) (BitString, error) {
+	if b.Len != o.Len {
+		return BitString{}, fmt.Errorf("pg: cannot %s bit strings of different sizes", op)
+	}
+	n := (b.Len + 7) / 8
+	if len(b.Bytes) < n || len(o.Bytes) < n {
+		return BitString{}, fmt.Errorf("pg: invalid BitString: length %d does not match its bytes", b.Len)
+	}
+
+	r := BitString{Bytes: make([]byte, n), Len: b.Len}
+	for i := range r.Bytes {
+		r.Bytes[i] = f(b.Bytes[i], o.Bytes[i])
+	}
+	return r, nil
+}
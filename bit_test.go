@@ -0,0 +1,69 @@
+package pg
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+)
+
+func TestBitStringScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(BitString), ``, ""},
+		{new(BitString), `1`, ""},
+		{new(BitString), `10110`, ""},
+		{new(BitString), `0000000011111111`, ""},
+		{new(BitString), `101100111`, ""},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(BitString) },
+		`102`,
+		`1 0`,
+		`x`,
+	)
+	if err := new(BitString).Scan(nil); err == nil {
+		t.Error("expected error scanning NULL into BitString")
+	}
+}
+
+func TestBitStringBytes(t *testing.T) {
+	b, err := ParseBitString("101100111")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Len != 9 || !bytes.Equal(b.Bytes, []byte{0xb3, 0x80}) {
+		t.Errorf("got %x, length %d", b.Bytes, b.Len)
+	}
+	for i, want := range []bool{true, false, true, true, false, false, true, true, true, false} {
+		if got := b.Test(i); got != want {
+			t.Errorf("Test(%d) = %v", i, got)
+		}
+	}
+	if b.Test(-1) {
+		t.Error("Test(-1) = true")
+	}
+
+	if _, err := (BitString{Bytes: []byte{0}, Len: 9}).Value(); err == nil {
+		t.Error("expected error for a length past the bytes")
+	}
+}
+
+func TestBitStringOps(t *testing.T) {
+	x, _ := ParseBitString("1100")
+	y, _ := ParseBitString("1010")
+	for _, tt := range []struct {
+		op   func(BitString) (BitString, error)
+		want string
+	}{
+		{x.And, "1000"},
+		{x.Or, "1110"},
+	} {
+		got, err := tt.op(y)
+		if err != nil || got.String() != tt.want {
+			t.Errorf("got %s, %v, want %s", got, err, tt.want)
+		}
+	}
+
+	z, _ := ParseBitString("101")
+	if _, err := x.And(z); err == nil {
+		t.Error("expected error for different sizes")
+	}
+}
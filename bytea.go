@@ -0,0 +1,438 @@
+package pg
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// byteaChunkSize is the size of the buffers used by the streaming bytea
+// encoder and decoder.
+const byteaChunkSize = 32 * 1024
+
+// ByteaFormat is a text format of bytea values, as chosen on the server
+// with the bytea_output setting.
+type ByteaFormat int
+
+const (
+	// ByteaHex is the hex format, such as \xdeadbeef.
+	ByteaHex ByteaFormat = iota
+	// ByteaEscape is the traditional escape format, in which bytes that
+	// are not printable ASCII are written as \ooo in octal and
+	// backslashes are doubled.
+	ByteaEscape
+)
+
+// DecodeBytea decodes the text of a bytea value in either the hex or the
+// escape format.
+func DecodeBytea(src []byte) ([]byte, error) {
+	return parseBytea(src)
+}
+
+// DecodeByteaTo is like DecodeBytea, but writes the decoded bytes to w as it
+// goes rather than returning them, so that large values need not be held in
+// memory twice. It returns the number of bytes written. On error some of the
+// value may already have been written.
+func DecodeByteaTo(w io.Writer, src []byte) (int64, error) {
+	if len(src) >= 2 && src[0] == '\\' && src[1] == 'x' {
+		return decodeByteaHexTo(w, src)
+	}
+	return decodeByteaEscapeTo(w, src)
+}
+
+// byteaOctal decodes the escape at offset i of the escape format bytea src,
+// a backslash followed by three octal digits.
+func byteaOctal(src []byte, i int) (byte, error) {
+	if len(src)-i < 4 {
+		return 0, parseErrorf("Bytea", src, i, "incomplete escape sequence %q", src[i:])
+	}
+	v := 0
+	for _, c := range src[i+1 : i+4] {
+		if c < '0' || c > '7' {
+			return 0, parseErrorf("Bytea", src, i, "invalid escape sequence %q", src[i:i+4])
+		}
+		v = v*8 + int(c-'0')
+	}
+	if v > 0xff {
+		return 0, parseErrorf("Bytea", src, i, "octal escape %q out of range", src[i:i+4])
+	}
+	return byte(v), nil
+}
+
+// byteaHexError returns the error for the hex format bytea src, which failed
+// to decode.
+func byteaHexError(src []byte) error {
+	for i := 2; i < len(src); i++ {
+		if c := src[i]; !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return parseErrorf("Bytea", src, i, "invalid hex digit %q", c)
+		}
+	}
+	return parseErrorf("Bytea", src, len(src), "odd number of hex digits")
+}
+
+func decodeByteaHexTo(w io.Writer, src []byte) (int64, error) {
+	s := src[2:]
+	buf := make([]byte, byteaChunkSize)
+	var written int64
+	for len(s) > 0 {
+		chunk := s
+		if len(chunk) > 2*len(buf) {
+			chunk = chunk[:2*len(buf)]
+		}
+		n, err := hex.Decode(buf, chunk)
+		if err != nil {
+			return written, byteaHexError(src)
+		}
+		m, err := w.Write(buf[:n])
+		written += int64(m)
+		if err != nil {
+			return written, err
+		}
+		s = s[len(chunk):]
+	}
+	return written, nil
+}
+
+func decodeByteaEscapeTo(w io.Writer, src []byte) (int64, error) {
+	s := src
+	buf := make([]byte, 0, byteaChunkSize)
+	var written int64
+	flush := func() error {
+		n, err := w.Write(buf)
+		written += int64(n)
+		buf = buf[:0]
+		return err
+	}
+
+	for len(s) > 0 {
+		if s[0] != '\\' {
+			// Write runs of raw bytes straight from the source.
+			i := bytes.IndexByte(s, '\\')
+			if i == -1 {
+				i = len(s)
+			}
+			if err := flush(); err != nil {
+				return written, err
+			}
+			n, err := w.Write(s[:i])
+			written += int64(n)
+			if err != nil {
+				return written, err
+			}
+			s = s[i:]
+			continue
+		}
+
+		if len(s) >= 2 && s[1] == '\\' {
+			buf = append(buf, '\\')
+			s = s[2:]
+		} else {
+			c, err := byteaOctal(src, len(src)-len(s))
+			if err != nil {
+				return written, err
+			}
+			buf = append(buf, c)
+			s = s[4:]
+		}
+		if len(buf) == cap(buf) {
+			if err := flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, flush()
+}
+
+// NewByteaHexReader returns a reader of the text of the bytes read from r as
+// a bytea value in the hex format, such as \xdeadbeef. It can be used to
+// encode large values without holding them in memory.
+func NewByteaHexReader(r io.Reader) io.Reader {
+	return &byteaHexReader{r: r, out: []byte{'\\', 'x'}}
+}
+
+type byteaHexReader struct {
+	r   io.Reader
+	src []byte
+	buf []byte // backing array of out, reused for every chunk
+	out []byte // encoded bytes not yet returned
+	err error
+}
+
+func (h *byteaHexReader) Read(p []byte) (int, error) {
+	for len(h.out) == 0 {
+		if h.err != nil {
+			return 0, h.err
+		}
+		if h.src == nil {
+			h.src = make([]byte, byteaChunkSize)
+			h.buf = make([]byte, hex.EncodedLen(byteaChunkSize))
+		}
+		var n int
+		n, h.err = h.r.Read(h.src)
+		h.out = h.buf[:hex.EncodedLen(n)]
+		hex.Encode(h.out, h.src[:n])
+	}
+
+	n := copy(p, h.out)
+	h.out = h.out[n:]
+	return n, nil
+}
+
+// EncodeBytea returns the text of data as a bytea value in the given
+// format.
+func EncodeBytea(data []byte, format ByteaFormat) ([]byte, error) {
+	switch format {
+	case ByteaHex:
+		return appendByteaHex(nil, data), nil
+	case ByteaEscape:
+		return appendByteaEscape(nil, data), nil
+	}
+	return nil, fmt.Errorf("pg: unknown bytea format %d", format)
+}
+
+// Bytea represents a PostgreSQL bytea value. Values in either the hex or the
+// escape format are scanned, and values are sent in the hex format. A nil
+// Bytes is NULL.
+type Bytea struct {
+	Bytes []byte
+}
+
+// Scan implements the sql.Scanner interface.
+func (b *Bytea) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return b.scanBytes(src)
+	case string:
+		return b.scanBytes([]byte(src))
+	case nil:
+		b.Bytes = nil
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Bytea", src)
+}
+
+func (b *Bytea) scanBytes(src []byte) error {
+	v, err := DecodeBytea(src)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		v = []byte{}
+	}
+
+	b.Bytes = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (b Bytea) Value() (driver.Value, error) {
+	if b.Bytes == nil {
+		return nil, nil
+	}
+
+	// Bind as text, so that the server parses the hex format.
+	return string(appendByteaHex(nil, b.Bytes)), nil
+}
+
+// AppendValue appends the text of b in the hex format to dst, like Value
+// but without allocating a string. A NULL value appends nothing.
+func (b Bytea) AppendValue(dst []byte) ([]byte, error) {
+	if b.Bytes == nil {
+		return dst, nil
+	}
+	return appendByteaHex(dst, b.Bytes), nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. A non-NULL value is
+// encoded as a base64 string, like encoding/json encodes a []byte.
+func (b Bytea) MarshalJSON() ([]byte, error) {
+	if b.Bytes == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(b.Bytes)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (b *Bytea) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		b.Bytes = nil
+		return nil
+	}
+	var v []byte
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v == nil {
+		v = []byte{}
+	}
+
+	b.Bytes = v
+	return nil
+}
+
+// ByteaArray represents a one-dimensional array of bytea values. A nil
+// element is NULL, as is a nil Byteas. Scan reuses the backing array of
+// Byteas when it is large enough, overwriting it even if it fails.
+//
+// The server quotes bytea elements and doubles their backslashes, so that
+// the hex value \xdeadbeef appears in an array as "\\xdeadbeef". The
+// quoting is removed before each element is decoded, in either format.
+type ByteaArray struct {
+	Byteas [][]byte
+
+	// Options tunes parsing. It may be nil. NULL elements are always
+	// scanned as nil.
+	Options *ParseOptions
+}
+
+func (a *ByteaArray) setOptions(opts *ParseOptions) {
+	a.Options = opts
+}
+
+// Scan implements the sql.Scanner interface.
+func (a *ByteaArray) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return a.scanBytes(src)
+	case string:
+		return a.scanBytes([]byte(src))
+	case nil:
+		a.Byteas = nil
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to ByteaArray", src)
+}
+
+func (a *ByteaArray) scanBytes(src []byte) error {
+	return a.scanArray(src, nil)
+}
+
+// scanArray is like scanBytes, parsing with scratch rather than a pooled
+// arrayScratch if it is not nil.
+func (a *ByteaArray) scanArray(src []byte, scratch *arrayScratch) error {
+	if isBinaryArray(src) {
+		return a.scanBinary(src)
+	}
+	if scratch == nil {
+		scratch = getArrayScratch()
+		defer putArrayScratch(scratch)
+	}
+
+	elems, err := scratch.parseLinear(src, []byte{','}, "ByteaArray", a.Options)
+	if err != nil {
+		return err
+	}
+
+	bs := reuseSlice(a.Byteas, len(elems))
+	for i, v := range elems {
+		if v == nil {
+			bs[i] = nil
+			continue
+		}
+		if bs[i], err = DecodeBytea(v); err != nil {
+			return fmt.Errorf("pg: parsing array element index %d: %w", i, err)
+		}
+		if bs[i] == nil {
+			bs[i] = []byte{}
+		}
+	}
+	a.Byteas = bs
+	return nil
+}
+
+func (a *ByteaArray) scanBinary(src []byte) error {
+	oid, elems, err := scanLinearBinaryArray(src, "ByteaArray", a.Options)
+	if err != nil {
+		return err
+	}
+	if oid != byteaOID {
+		return fmt.Errorf("pg: cannot convert binary array of type OID %d to ByteaArray", oid)
+	}
+
+	// Copy all elements into one allocation, as src belongs to the driver.
+	n := 0
+	for _, v := range elems {
+		n += len(v)
+	}
+	buf := make([]byte, 0, n)
+	bs := reuseSlice(a.Byteas, len(elems))
+	for i, v := range elems {
+		bs[i] = nil
+		if v != nil {
+			start := len(buf)
+			buf = append(buf, v...)
+			bs[i] = buf[start:len(buf):len(buf)]
+		}
+	}
+	a.Byteas = bs
+	return nil
+}
+
+// Value implements the driver.Valuer interface. Elements are sent in the hex
+// format.
+func (a ByteaArray) Value() (driver.Value, error) {
+	if a.Byteas == nil {
+		return nil, nil
+	}
+
+	size := 1 + len(a.Byteas)
+	for _, v := range a.Byteas {
+		if v == nil {
+			size += len("NULL")
+		} else {
+			size += len(`"\\x"`) + 2*len(v)
+		}
+	}
+	b, _ := a.AppendValue(make([]byte, 0, size))
+	return string(b), nil
+}
+
+// AppendValue appends the text of a to dst, like Value but without
+// allocating a string. A NULL value appends nothing.
+func (a ByteaArray) AppendValue(dst []byte) ([]byte, error) {
+	if a.Byteas == nil {
+		return dst, nil
+	}
+
+	dst = append(dst, '{')
+	for i, v := range a.Byteas {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		if v == nil {
+			dst = append(dst, "NULL"...)
+			continue
+		}
+		// The backslash of \x is escaped inside the quotes.
+		dst = append(dst, '"', '\\')
+		dst = appendByteaHex(dst, v)
+		dst = append(dst, '"')
+	}
+	return append(dst, '}'), nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface, encoding
+// a as a bytea[] in the binary array format. A nil Byteas is encoded as an
+// empty array, as NULL has no binary representation.
+func (a ByteaArray) MarshalBinary() ([]byte, error) {
+	return appendBinaryArray(nil, byteaOID, a.Byteas), nil
+}
+
+func appendByteaEscape(b, v []byte) []byte {
+	for _, c := range v {
+		switch {
+		case c == '\\':
+			b = append(b, '\\', '\\')
+		case c >= 0x20 && c < 0x7f:
+			b = append(b, c)
+		default:
+			b = append(b, '\\', '0'+c>>6, '0'+c>>3&7, '0'+c&7)
+		}
+	}
+	return b
+}
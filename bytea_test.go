@@ -0,0 +1,369 @@
+package pg
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+func TestByteaArrayScan(t *testing.T) {
+	tests := []struct {
+		src  string
+		want [][]byte
+	}{
+		{`{}`, [][]byte{}},
+		{`{NULL}`, [][]byte{nil}},
+		{`{"\\x6869",NULL}`, [][]byte{[]byte("hi"), nil}},
+		{`{"\\x",NULL,"\\xDEADBEEF"}`, [][]byte{{}, nil, {0xde, 0xad, 0xbe, 0xef}}},
+		// The escape format, as output with bytea_output = escape.
+		{`{abc,"a b"}`, [][]byte{[]byte("abc"), []byte("a b")}},
+		{`{"\\001abc"}`, [][]byte{[]byte("\x01abc")}},
+		{`{"\\377\\000"}`, [][]byte{{0xff, 0x00}}},
+		// A backslash in the value is doubled by the escape format, and
+		// that again inside the quotes of the array.
+		{`{"a\\\\b"}`, [][]byte{[]byte(`a\b`)}},
+		{`{"\\\\001abc"}`, [][]byte{[]byte(`\001abc`)}},
+		// Unquoted elements are unescaped too.
+		{`{a\\\\b}`, [][]byte{[]byte(`a\b`)}},
+	}
+	for _, tt := range tests {
+		var a ByteaArray
+		if err := a.Scan([]byte(tt.src)); err != nil {
+			t.Errorf("Scan(%s): %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(a.Byteas, tt.want) {
+			t.Errorf("Scan(%s) = %q, want %q", tt.src, a.Byteas, tt.want)
+		}
+	}
+
+	var a ByteaArray
+	if err := a.Scan(nil); err != nil || a.Byteas != nil {
+		t.Errorf("Scan(nil) = %q, %v, want NULL", a.Byteas, err)
+	}
+}
+
+func TestByteaArrayScanInvalid(t *testing.T) {
+	for _, src := range []string{
+		`{"\\xzz"}`,
+		`{"\\xabc"}`,
+		`{"\\400"}`,
+		`{"\\12"}`,
+		`{"abc}`,
+		`{{"\\x00"}}`,
+	} {
+		var a ByteaArray
+		if err := a.Scan(src); err == nil {
+			t.Errorf("Scan(%s) = %q, want error", src, a.Byteas)
+		}
+	}
+}
+
+func TestByteaArrayValue(t *testing.T) {
+	a := ByteaArray{Byteas: [][]byte{[]byte("hi"), nil, {}, []byte(`\`)}}
+	v, err := a.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"\\x6869",NULL,"\\x","\\x5c"}`; v != want {
+		t.Fatalf("Value = %s, want %s", v, want)
+	}
+	var b ByteaArray
+	if err := b.Scan(v); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b.Byteas, a.Byteas) {
+		t.Fatalf("Scan(%s) = %q, want %q", v, b.Byteas, a.Byteas)
+	}
+}
+
+func TestByteaArrayBinary(t *testing.T) {
+	want := [][]byte{[]byte("hi"), nil, {}, {0xde, 0xad}}
+	b, err := ByteaArray{Byteas: want}.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a ByteaArray
+	if err := a.Scan(b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a.Byteas, want) {
+		t.Errorf("got %q, want %q", a.Byteas, want)
+	}
+
+	// A text[] cannot be scanned into a ByteaArray.
+	b, _ = StringArray{Strings: []string{"hi"}}.MarshalBinary()
+	if err := a.Scan(b); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestEncodeDecodeBytea(t *testing.T) {
+	data := []byte("a\\b\x00\xff\x7f ~")
+	for _, tt := range []struct {
+		format ByteaFormat
+		want   string
+	}{
+		{ByteaHex, `\x615c6200ff7f207e`},
+		{ByteaEscape, `a\\b\000\377\177 ~`},
+	} {
+		enc, err := EncodeBytea(data, tt.format)
+		if err != nil || string(enc) != tt.want {
+			t.Errorf("EncodeBytea(%d) = %s, %v, want %s", tt.format, enc, err, tt.want)
+			continue
+		}
+		dec, err := DecodeBytea(enc)
+		if err != nil || !bytes.Equal(dec, data) {
+			t.Errorf("DecodeBytea(%s) = %q, %v, want %q", enc, dec, err, data)
+		}
+	}
+
+	if _, err := EncodeBytea(data, ByteaFormat(2)); err == nil {
+		t.Error("expected error for an unknown format")
+	}
+	for _, src := range []string{`\xa`, `\xzz`, `\400`, `\12`, `\`} {
+		if v, err := DecodeBytea([]byte(src)); err == nil {
+			t.Errorf("DecodeBytea(%s) = %q, want error", src, v)
+		}
+	}
+}
+
+func TestDecodeByteaErrors(t *testing.T) {
+	tests := []struct {
+		src    string
+		offset int
+	}{
+		{`ab\`, 2},
+		{`ab\0`, 2},
+		{`\00`, 0},
+		{`a\400`, 1},
+		{`a\777`, 1},
+		{`a\08x`, 1},
+		{`a\x`, 1},
+		{`\x0g`, 3},
+		{`\xabc`, 5},
+	}
+	for _, tt := range tests {
+		_, err := DecodeBytea([]byte(tt.src))
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("DecodeBytea(%s) error = %v, want a ParseError", tt.src, err)
+			continue
+		}
+		if perr.Type != "Bytea" || perr.Offset != tt.offset {
+			t.Errorf("DecodeBytea(%s) error = %v, want offset %d", tt.src, err, tt.offset)
+		}
+		if _, err := DecodeByteaTo(io.Discard, []byte(tt.src)); err == nil {
+			t.Errorf("DecodeByteaTo(%s): expected error", tt.src)
+		}
+	}
+
+	// The largest octal escape is a byte.
+	if got, err := DecodeBytea([]byte(`\377\\`)); err != nil || !bytes.Equal(got, []byte{0xff, '\\'}) {
+		t.Errorf("DecodeBytea = %q, %v", got, err)
+	}
+}
+
+func TestByteaScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Bytea), `\x`, ""},
+		{new(Bytea), `\xdeadbeef`, ""},
+		{new(Bytea), `\xDEADBEEF`, `\xdeadbeef`},
+		{new(Bytea), `abc`, `\x616263`},
+		{new(Bytea), `\001\\`, `\x015c`},
+		{new(Bytea), ``, `\x`},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(Bytea) },
+		`\xabc`,
+		`\xgg`,
+		`\9`,
+	)
+	testScanNull(t, new(Bytea))
+
+	var b Bytea
+	if err := b.Scan([]byte(`\x`)); err != nil || b.Bytes == nil {
+		t.Errorf("Scan of an empty value = %#v, %v, want non-nil", b.Bytes, err)
+	}
+}
+
+func TestDecodeByteaTo(t *testing.T) {
+	data := make([]byte, 3*byteaChunkSize+17)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for _, format := range []ByteaFormat{ByteaHex, ByteaEscape} {
+		enc, _ := EncodeBytea(data, format)
+		var buf bytes.Buffer
+		n, err := DecodeByteaTo(&buf, enc)
+		if err != nil || n != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("format %d: DecodeByteaTo = %d, %v", format, n, err)
+		}
+	}
+
+	for _, src := range []string{`\xabc`, `\xgg`, `ab\9`} {
+		if _, err := DecodeByteaTo(io.Discard, []byte(src)); err == nil {
+			t.Errorf("DecodeByteaTo(%s): expected error", src)
+		}
+	}
+}
+
+func TestByteaHexReader(t *testing.T) {
+	data := make([]byte, 3*byteaChunkSize+17)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	want, _ := EncodeBytea(data, ByteaHex)
+
+	// Read in small pieces, so that chunks are returned over several
+	// calls.
+	r := NewByteaHexReader(iotest.HalfReader(bytes.NewReader(data)))
+	var got []byte
+	p := make([]byte, 1000)
+	for {
+		n, err := r.Read(p)
+		got = append(got, p[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("read %d bytes, want %d", len(got), len(want))
+	}
+
+	empty, err := io.ReadAll(NewByteaHexReader(bytes.NewReader(nil)))
+	if err != nil || string(empty) != `\x` {
+		t.Errorf("empty input = %q, %v", empty, err)
+	}
+
+	if err := iotest.TestReader(NewByteaHexReader(bytes.NewReader(data)), want); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestByteaHexReaderAllocs(t *testing.T) {
+	src := bytes.NewReader(make([]byte, 64*byteaChunkSize))
+	p := make([]byte, 4096)
+	allocs := testing.AllocsPerRun(10, func() {
+		src.Seek(0, io.SeekStart)
+		r := NewByteaHexReader(src)
+		for {
+			if _, err := r.Read(p); err != nil {
+				break
+			}
+		}
+	})
+	// The reader, its initial output and its two buffers; the buffers
+	// must not be reallocated for each chunk.
+	if allocs > 5 {
+		t.Errorf("%v allocations to encode %d chunks", allocs, 64)
+	}
+}
+
+func TestByteaJSON(t *testing.T) {
+	for _, tt := range []struct {
+		b    Bytea
+		data string
+	}{
+		{Bytea{Bytes: []byte("hi\x00")}, `"aGkA"`},
+		{Bytea{Bytes: []byte{}}, `""`},
+		{Bytea{}, `null`},
+	} {
+		data, err := json.Marshal(tt.b)
+		if err != nil || string(data) != tt.data {
+			t.Errorf("Marshal(%q) = %s, %v, want %s", tt.b.Bytes, data, err, tt.data)
+		}
+		got := Bytea{Bytes: []byte("old")}
+		if err := json.Unmarshal([]byte(tt.data), &got); err != nil || !reflect.DeepEqual(got, tt.b) {
+			t.Errorf("Unmarshal(%s) = %#v, %v, want %#v", tt.data, got.Bytes, err, tt.b.Bytes)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`"!"`), new(Bytea)); err == nil {
+		t.Error("expected error for invalid base64")
+	}
+}
+
+func FuzzParseBytea(f *testing.F) {
+	for _, s := range []string{
+		``,
+		`\x`,
+		`\xdeadbeef`,
+		`\xDEADBEEF`,
+		`\xabc`,
+		`\xzz`,
+		`abc`,
+		`a\\b`,
+		`\001\377`,
+		`\400`,
+		`\12`,
+		`\`,
+		"\x00\xff",
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, src []byte) {
+		// src as input of either format.
+		data, err := DecodeBytea(src)
+		var buf bytes.Buffer
+		_, errTo := DecodeByteaTo(&buf, src)
+		if (err == nil) != (errTo == nil) {
+			t.Fatalf("DecodeBytea(%q) error %v, DecodeByteaTo error %v", src, err, errTo)
+		}
+		if err == nil && !bytes.Equal(buf.Bytes(), data) {
+			t.Fatalf("DecodeBytea(%q) = %q, DecodeByteaTo wrote %q", src, data, buf.Bytes())
+		}
+
+		// src as the data of a value, and the output of DecodeBytea.
+		for _, v := range [][]byte{src, data} {
+			for _, format := range []ByteaFormat{ByteaHex, ByteaEscape} {
+				enc, err := EncodeBytea(v, format)
+				if err != nil {
+					t.Fatalf("EncodeBytea(%q, %d): %v", v, format, err)
+				}
+				got, err := DecodeBytea(enc)
+				if err != nil {
+					t.Fatalf("DecodeBytea(EncodeBytea(%q, %d) = %q): %v", v, format, enc, err)
+				}
+				if !bytes.Equal(got, v) {
+					t.Fatalf("EncodeBytea(%q, %d) = %q decoded as %q", v, format, enc, got)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkParseBytea(b *testing.B) {
+	data := make([]byte, 4096)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, format := range []struct {
+		name   string
+		format ByteaFormat
+	}{
+		{"hex", ByteaHex},
+		{"escape", ByteaEscape},
+	} {
+		src, err := EncodeBytea(data, format.format)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(format.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(src)))
+			for i := 0; i < b.N; i++ {
+				if _, err := DecodeBytea(src); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+)
+
+// Char represents a value of the internal single-byte "char" type, such as
+// the relkind column of pg_class. Use a *Char to scan nullable columns.
+type Char byte
+
+// Scan implements the sql.Scanner interface. Like the server, an empty
+// string is the zero byte and a backslash followed by three octal digits is
+// the byte they denote.
+func (c *Char) Scan(src interface{}) error {
+	var s []byte
+	switch src := src.(type) {
+	case []byte:
+		s = src
+	case string:
+		s = []byte(src)
+	default:
+		return fmt.Errorf("pg: cannot convert %T to Char", src)
+	}
+
+	switch {
+	case len(s) == 0:
+		*c = 0
+	case len(s) == 4 && s[0] == '\\':
+		v, err := strconv.ParseUint(string(s[1:]), 8, 8)
+		if err != nil {
+			return parseError("Char", s, -1, nil)
+		}
+		*c = Char(v)
+	default:
+		*c = Char(s[0])
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (c Char) Value() (driver.Value, error) {
+	return c.String(), nil
+}
+
+// String returns c as the byte itself if it is printable ASCII, an empty
+// string for the zero byte and \ooo in octal otherwise, all of which the
+// server accepts as input.
+func (c Char) String() string {
+	switch {
+	case c == 0:
+		return ""
+	case c >= 0x20 && c < 0x7f:
+		return string(rune(c))
+	}
+	return fmt.Sprintf("\\%03o", byte(c))
+}
@@ -0,0 +1,30 @@
+package pg
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestCharScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Char), `r`, ""},
+		{new(Char), ``, ""},
+		{new(Char), `\200`, ""},
+		{new(Char), `\001`, ""},
+		{new(Char), `\101`, `A`},
+		{new(Char), `abc`, `a`},
+		{new(Char), `\`, ""},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(Char) },
+		`\400`,
+		`\08a`,
+	)
+	if err := new(Char).Scan(nil); err == nil {
+		t.Error("expected error scanning NULL into Char")
+	}
+
+	var c Char
+	if err := c.Scan([]byte(`\377`)); err != nil || c != 0xff {
+		t.Errorf(`Scan(\377) = %d, %v`, c, err)
+	}
+}
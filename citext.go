@@ -0,0 +1,160 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Citext represents a value of the citext extension type, a string that
+// keeps its case but compares case-insensitively. Use a *Citext to scan
+// nullable columns.
+type Citext string
+
+// Scan implements the sql.Scanner interface.
+func (c *Citext) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		*c = Citext(src)
+		return nil
+	case string:
+		*c = Citext(src)
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Citext", src)
+}
+
+// Value implements the driver.Valuer interface.
+func (c Citext) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+// Equal reports whether c and o are equal ignoring case, like the citext =
+// operator.
+func (c Citext) Equal(o Citext) bool {
+	return c.Compare(o) == 0
+}
+
+// Compare compares c and o ignoring case, returning -1, 0 or 1. Like the
+// server it compares the lower-cased values, but byte-wise rather than by
+// collation.
+func (c Citext) Compare(o Citext) int {
+	return strings.Compare(strings.ToLower(string(c)), strings.ToLower(string(o)))
+}
+
+// CitextArray represents a one-dimensional array of citext values. Scan
+// reuses the backing array of Citexts when it is large enough.
+type CitextArray struct {
+	Citexts []Citext
+
+	// Options tunes parsing. It may be nil.
+	Options *ParseOptions
+}
+
+func (a *CitextArray) setOptions(opts *ParseOptions) {
+	a.Options = opts
+}
+
+// Scan implements the sql.Scanner interface.
+func (a *CitextArray) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return a.scanBytes(src)
+	case string:
+		return a.scanBytes([]byte(src))
+	case nil:
+		a.Citexts = nil
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to CitextArray", src)
+}
+
+func (a *CitextArray) scanBytes(src []byte) error {
+	return a.scanArray(src, nil)
+}
+
+// scanArray is like scanBytes, parsing with scratch rather than a pooled
+// arrayScratch if it is not nil.
+func (a *CitextArray) scanArray(src []byte, scratch *arrayScratch) error {
+	var elems [][]byte
+	var err error
+	if isBinaryArray(src) {
+		var oid uint32
+		if oid, elems, err = scanLinearBinaryArray(src, "CitextArray", a.Options); err != nil {
+			return err
+		}
+		// The citext type is created by its extension, so it has no
+		// fixed OID.
+		if !isTextOID(oid) && oid < firstNormalOID {
+			return fmt.Errorf("pg: cannot convert binary array of type OID %d to CitextArray", oid)
+		}
+	} else {
+		if scratch == nil {
+			scratch = getArrayScratch()
+			defer putArrayScratch(scratch)
+		}
+		if elems, err = scratch.parseLinear(src, []byte{','}, "CitextArray", a.Options); err != nil {
+			return err
+		}
+		// Zero-copy strings may refer to the scratch buffer.
+		if a.Options != nil && a.Options.ZeroCopy {
+			scratch.buf = nil
+		}
+	}
+
+	if elems, err = a.Options.nulls(elems, "Citext"); err != nil {
+		return err
+	}
+	if err := a.Options.checkUTF8(elems); err != nil {
+		return err
+	}
+	cs := reuseSlice(a.Citexts, len(elems))
+	for i, v := range elems {
+		cs[i] = Citext(a.Options.string(v))
+	}
+	a.Citexts = cs
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (a CitextArray) Value() (driver.Value, error) {
+	if a.Citexts == nil {
+		return nil, nil
+	}
+
+	size := 1 + len(a.Citexts)
+	for _, c := range a.Citexts {
+		size += arrayQuotedLen(string(c))
+	}
+	b, _ := a.AppendValue(make([]byte, 0, size))
+	return string(b), nil
+}
+
+// AppendValue appends the text of a to dst, like Value but without
+// allocating a string. A NULL value appends nothing.
+func (a CitextArray) AppendValue(dst []byte) ([]byte, error) {
+	if a.Citexts == nil {
+		return dst, nil
+	}
+
+	dst = append(dst, '{')
+	for i, c := range a.Citexts {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendArrayElement(dst, []byte(c))
+	}
+	return append(dst, '}'), nil
+}
+
+// Contains reports whether a has an element equal to c ignoring case.
+func (a CitextArray) Contains(c Citext) bool {
+	for _, e := range a.Citexts {
+		if e.Equal(c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package pg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCitextScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Citext), ``, ""},
+		{new(Citext), `Hello World`, ""},
+		{new(CitextArray), `{}`, ""},
+		{new(CitextArray), `{Alice,"Bob Smith","NULL"}`, ""},
+	})
+	testScanNull(t, new(CitextArray))
+}
+
+func TestCitextCompare(t *testing.T) {
+	for _, tt := range []struct {
+		a, b Citext
+		want int
+	}{
+		{"abc", "ABC", 0},
+		{"Straße", "STRASSE", 1},
+		{"a", "B", -1},
+		{"B", "a", 1},
+	} {
+		if got := tt.a.Compare(tt.b); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := tt.a.Equal(tt.b); got != (tt.want == 0) {
+			t.Errorf("Equal(%q, %q) = %v", tt.a, tt.b, got)
+		}
+	}
+}
+
+func TestCitextArray(t *testing.T) {
+	var a CitextArray
+	if err := a.Scan([]byte(`{Alice,"bob smith"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []Citext{"Alice", "bob smith"}; !reflect.DeepEqual(a.Citexts, want) {
+		t.Errorf("Scan = %q, want %q", a.Citexts, want)
+	}
+	if err := a.Scan(`{a,NULL}`); err == nil {
+		t.Error("expected error for a NULL element")
+	}
+	if !a.Contains("ALICE") || !a.Contains("Bob Smith") || a.Contains("carol") {
+		t.Error("Contains does not ignore case")
+	}
+}
@@ -0,0 +1,68 @@
+// Command pggen generates Go types for the composite and enum types of a
+// database from the output of the gen.Query introspection query:
+//
+//	psql -XAt -c "$(go run github.com/onrik/pg/cmd/pggen -query)" > types.json
+//	go run github.com/onrik/pg/cmd/pggen -pkg models < types.json > types_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/onrik/pg/gen"
+)
+
+func main() {
+	var (
+		query    = flag.Bool("query", false, "print the introspection query and exit")
+		pkg      = flag.String("pkg", "models", "name of the generated package")
+		schemas  = flag.String("schemas", "", "comma-separated list of schemas to generate types for (default all)")
+		pointers = flag.Bool("pointers", false, "use pointers for composite attributes so that NULL can be scanned")
+		input    = flag.String("i", "", "read type information from `file` instead of stdin")
+		output   = flag.String("o", "", "write generated code to `file` instead of stdout")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("pggen: ")
+
+	if *query {
+		fmt.Println(gen.Query)
+		return
+	}
+
+	var r io.Reader = os.Stdin
+	if *input != "" {
+		f, err := os.Open(*input)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	types, err := gen.Parse(r)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *schemas != "" {
+		types = gen.FilterSchemas(types, strings.Split(*schemas, ",")...)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := gen.Generate(w, types, gen.Options{Package: *pkg, Pointers: *pointers}); err != nil {
+		log.Fatal(err)
+	}
+}
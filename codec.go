@@ -0,0 +1,145 @@
+package pg
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// bytesScanner is implemented by the types that can parse their text
+// representation directly, skipping the type switch of Scan.
+type bytesScanner interface {
+	scanBytes(src []byte) error
+}
+
+// optionsSetter is implemented by the types with an Options field.
+type optionsSetter interface {
+	setOptions(opts *ParseOptions)
+}
+
+// optionsScanner is implemented by the types that are both bytesScanner
+// and optionsSetter.
+type optionsScanner interface {
+	bytesScanner
+	optionsSetter
+}
+
+// valueAppender is implemented by the types with an AppendValue method.
+type valueAppender interface {
+	AppendValue(dst []byte) ([]byte, error)
+}
+
+// Codec decodes and encodes the values of one column of type T. The way to
+// parse and format T and the options it is parsed with are resolved once by
+// CodecFor, rather than on every call. A Codec is safe for concurrent use.
+//
+//	codec := pg.CodecFor[pg.StringArray](&pg.ParseOptions{Intern: labels})
+//	for rows.Next() {
+//		var raw []byte
+//		if err := rows.Scan(&raw); err != nil {
+//			return err
+//		}
+//		var a pg.StringArray
+//		if err := codec.Decode(raw, &a); err != nil {
+//			return err
+//		}
+//	}
+type Codec[T any, PT interface {
+	*T
+	sql.Scanner
+}] struct {
+	opts   *ParseOptions
+	decode func(src []byte, dst *T) error
+	encode func(dst []byte, v T) ([]byte, error)
+}
+
+// CodecFor returns a Codec for values of type T, parsed with opts if T has
+// an Options field. opts may be nil.
+//
+// If T has an Options field, Decode and Scan set it to opts on every value
+// they parse, replacing whatever the caller set.
+func CodecFor[T any, PT interface {
+	*T
+	sql.Scanner
+}](opts *ParseOptions) *Codec[T, PT] {
+	c := &Codec[T, PT]{opts: opts}
+
+	// The way to decode is picked once here. Each call still converts dst
+	// to the interface, which is the only way to reach an unexported
+	// method through PT, but makes a single assertion and no other checks.
+	var zero T
+	switch interface{}(PT(&zero)).(type) {
+	case optionsScanner:
+		c.decode = func(src []byte, dst *T) error {
+			p := interface{}(PT(dst)).(optionsScanner)
+			p.setOptions(c.opts)
+			return p.scanBytes(src)
+		}
+	case bytesScanner:
+		c.decode = func(src []byte, dst *T) error {
+			return interface{}(PT(dst)).(bytesScanner).scanBytes(src)
+		}
+	case optionsSetter:
+		c.decode = func(src []byte, dst *T) error {
+			interface{}(PT(dst)).(optionsSetter).setOptions(c.opts)
+			return PT(dst).Scan(src)
+		}
+	default:
+		c.decode = func(src []byte, dst *T) error {
+			return PT(dst).Scan(src)
+		}
+	}
+
+	if _, ok := interface{}(zero).(valueAppender); ok {
+		c.encode = func(dst []byte, v T) ([]byte, error) {
+			return interface{}(v).(valueAppender).AppendValue(dst)
+		}
+	} else if _, ok := interface{}(zero).(driver.Valuer); ok {
+		c.encode = func(dst []byte, v T) ([]byte, error) {
+			val, err := interface{}(v).(driver.Valuer).Value()
+			if err != nil {
+				return dst, err
+			}
+			switch val := val.(type) {
+			case nil:
+				return dst, nil
+			case string:
+				return append(dst, val...), nil
+			case []byte:
+				return append(dst, val...), nil
+			}
+			return dst, fmt.Errorf("pg: cannot append %T value of %T", val, v)
+		}
+	} else {
+		c.encode = func(dst []byte, v T) ([]byte, error) {
+			return dst, fmt.Errorf("pg: %T does not implement driver.Valuer", v)
+		}
+	}
+	return c
+}
+
+// Decode parses the text representation src into dst. A nil src is NULL.
+func (c *Codec[T, PT]) Decode(src []byte, dst *T) error {
+	if src == nil {
+		return PT(dst).Scan(nil)
+	}
+	return c.decode(src, dst)
+}
+
+// Scan is like Decode for any value a driver may return, as passed to
+// sql.Scanner.
+func (c *Codec[T, PT]) Scan(src interface{}, dst *T) error {
+	switch src := src.(type) {
+	case []byte:
+		return c.Decode(src, dst)
+	case string:
+		return c.decode([]byte(src), dst)
+	}
+	return PT(dst).Scan(src)
+}
+
+// Encode appends the text representation of v to dst. A NULL value appends
+// nothing.
+func (c *Codec[T, PT]) Encode(dst []byte, v T) ([]byte, error) {
+	return c.encode(dst, v)
+}
@@ -0,0 +1,99 @@
+package pg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodecStringArray(t *testing.T) {
+	opts := &ParseOptions{Null: NullSkip}
+	codec := CodecFor[StringArray](opts)
+
+	// Options are replaced by those of the codec.
+	a := StringArray{Options: &ParseOptions{}}
+	if err := codec.Decode([]byte(`{a,NULL,"b c"}`), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Options != opts {
+		t.Error("Decode did not set Options")
+	}
+	if want := []string{"a", "b c"}; !reflect.DeepEqual(a.Strings, want) {
+		t.Errorf("Decode = %q, want %q", a.Strings, want)
+	}
+
+	for _, src := range []interface{}{`{d}`, []byte(`{d}`)} {
+		var a StringArray
+		if err := codec.Scan(src, &a); err != nil {
+			t.Fatalf("Scan(%#v): %v", src, err)
+		}
+		if a.Options != opts || !reflect.DeepEqual(a.Strings, []string{"d"}) {
+			t.Errorf("Scan(%#v) = %+v", src, a)
+		}
+	}
+	if err := codec.Scan(1, &a); err == nil {
+		t.Error("Scan(1): expected error")
+	}
+
+	b, err := codec.Encode([]byte("x"), StringArray{Strings: []string{"a", "b c"}})
+	if err != nil || string(b) != `x{a,"b c"}` {
+		t.Errorf("Encode = %q, %v", b, err)
+	}
+}
+
+func TestCodecNull(t *testing.T) {
+	codec := CodecFor[StringArray](nil)
+	a := StringArray{Strings: []string{"a"}}
+	if err := codec.Decode(nil, &a); err != nil || a.Strings != nil {
+		t.Errorf("Decode(nil) = %q, %v, want NULL", a.Strings, err)
+	}
+	a.Strings = []string{"a"}
+	if err := codec.Scan(nil, &a); err != nil || a.Strings != nil {
+		t.Errorf("Scan(nil) = %q, %v, want NULL", a.Strings, err)
+	}
+	if b, err := codec.Encode([]byte("x"), StringArray{}); err != nil || string(b) != "x" {
+		t.Errorf("Encode of NULL = %q, %v, want %q", b, err, "x")
+	}
+}
+
+func TestCodecTypes(t *testing.T) {
+	// Date has scanBytes and AppendValue.
+	dates := CodecFor[Date](nil)
+	var d Date
+	if err := dates.Decode([]byte("2024-02-29"), &d); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := dates.Encode(nil, d); err != nil || string(b) != "2024-02-29" {
+		t.Errorf("Encode = %q, %v", b, err)
+	}
+
+	// Inet only has Scan and Value.
+	inets := CodecFor[Inet](nil)
+	var n Inet
+	if err := inets.Decode([]byte("10.0.0.1/8"), &n); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := inets.Encode(nil, n); err != nil || string(b) != "10.0.0.1/8" {
+		t.Errorf("Encode = %q, %v", b, err)
+	}
+	if b, err := inets.Encode([]byte("x"), Inet{}); err != nil || string(b) != "x" {
+		t.Errorf("Encode of NULL = %q, %v, want %q", b, err, "x")
+	}
+
+	// Types without Value cannot be encoded.
+	scanners := CodecFor[scanOnly](nil)
+	var s scanOnly
+	if err := scanners.Decode([]byte("a"), &s); err != nil || s != "a" {
+		t.Errorf("Decode = %q, %v", s, err)
+	}
+	if _, err := scanners.Encode(nil, s); err == nil {
+		t.Error("Encode: expected error")
+	}
+}
+
+// scanOnly is a sql.Scanner without a Value method.
+type scanOnly string
+
+func (s *scanOnly) Scan(src interface{}) error {
+	*s = scanOnly(src.([]byte))
+	return nil
+}
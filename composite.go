@@ -0,0 +1,616 @@
+package pg
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParseComposite parses the text representation of a composite or record
+// value, such as `(1,"b,c",,d)`, into its fields. NULL fields, written as
+// nothing between delimiters, are returned as nil, while an empty string
+// field, written as "", is returned as an empty non-nil slice.
+func ParseComposite(src []byte) ([][]byte, error) {
+	if len(src) < 2 || src[0] != '(' {
+		return nil, parseErrorf("composite", src, 0, "expected %q", '(')
+	}
+
+	var fields [][]byte
+	i := 1
+	for {
+		var field []byte
+		var quoted bool
+	Field:
+		for ; i < len(src); i++ {
+			switch c := src[i]; {
+			case c == '\\':
+				if i+1 >= len(src) {
+					return nil, parseErrorf("composite", src, len(src), "unexpected end of input")
+				}
+				i++
+				field = append(field, src[i])
+			case c == '"':
+				if quoted && i+1 < len(src) && src[i+1] == '"' {
+					i++
+					field = append(field, '"')
+				} else {
+					quoted = !quoted
+				}
+				if field == nil {
+					field = []byte{}
+				}
+			case !quoted && (c == ',' || c == ')'):
+				break Field
+			default:
+				field = append(field, c)
+			}
+		}
+		if i >= len(src) {
+			return nil, parseErrorf("composite", src, i, "expected %q", ')')
+		}
+		fields = append(fields, field)
+
+		if src[i] == ')' {
+			break
+		}
+		i++
+	}
+	if i != len(src)-1 {
+		return nil, parseErrorf("composite", src, i+1, "unexpected %q", src[i+1])
+	}
+
+	return fields, nil
+}
+
+// FormatComposite formats fields into the text representation of a
+// composite value, quoting fields where necessary. A nil field is written as
+// NULL.
+func FormatComposite(fields [][]byte) string {
+	return string(appendComposite(nil, fields))
+}
+
+// NormalizeComposite returns the text representation of a composite or
+// record value in the canonical form the server outputs it, with fields
+// quoted only where needed. Fields themselves are left as they are, so
+// nested composites and arrays are not normalized.
+//
+// The output of NormalizeComposite and FormatComposite is always accepted
+// by ParseComposite, which returns the same fields it was formatted from.
+// Normalizing the output of NormalizeComposite again returns it unchanged.
+func NormalizeComposite(src []byte) (string, error) {
+	fields, err := ParseComposite(src)
+	if err != nil {
+		return "", err
+	}
+	return FormatComposite(fields), nil
+}
+
+func appendComposite(b []byte, fields [][]byte) []byte {
+	b = append(b, '(')
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if f == nil {
+			continue
+		}
+		b = appendCompositeField(b, f)
+	}
+	return append(b, ')')
+}
+
+// appendCompositeField appends a non-NULL field the way the server's
+// record_out does: quoted if empty or if it holds special characters, with
+// quotes and backslashes doubled.
+func appendCompositeField(b, f []byte) []byte {
+	if len(f) > 0 && bytes.IndexAny(f, "\"\\(), \t\n\r\v\f") < 0 {
+		return append(b, f...)
+	}
+
+	b = append(b, '"')
+	for _, c := range f {
+		if c == '"' || c == '\\' {
+			b = append(b, c)
+		}
+		b = append(b, c)
+	}
+	return append(b, '"')
+}
+
+// Composite represents a value of a composite type mapped onto the struct T.
+// The attributes of the composite type are matched to the exported fields
+// of T in declaration order. The `pg` struct tag names the attribute, and
+// fields tagged `pg:"-"` are skipped:
+//
+//	type Address struct {
+//		Street string  `pg:"street"`
+//		City   string  `pg:"city"`
+//		Zip    *string `pg:"zip"`
+//	}
+//
+//	var addr pg.Composite[Address]
+//	err := db.QueryRow(`SELECT address FROM users WHERE id = $1`, id).Scan(&addr)
+//
+// Fields may be strings, []byte, booleans, numbers, time.Time, nested
+// structs for nested composite types, slices of any of these for array
+// attributes, pointers for NULL-able attributes, or types implementing
+// sql.Scanner and driver.Valuer. An interface{} field receives the text of
+// the attribute as a string, unless the tag names the database type of the
+// attribute, such as `pg:"email,type=email"`, in which case it is decoded
+// with DecodeValue into the Go type registered for it.
+type Composite[T any] struct {
+	V T
+	// Null marks a SQL NULL: Scan sets it for a NULL column, and Value
+	// then returns nil whatever V is.
+	Null bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (c *Composite[T]) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return c.scanBytes(src)
+	case string:
+		return c.scanBytes([]byte(src))
+	case nil:
+		var zero T
+		c.V = zero
+		c.Null = true
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Composite[%T]", src, c.V)
+}
+
+func (c *Composite[T]) scanBytes(src []byte) error {
+	var v T
+	if err := decodeComposite(reflect.ValueOf(&v).Elem(), src); err != nil {
+		return err
+	}
+	c.V = v
+	c.Null = false
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (c Composite[T]) Value() (driver.Value, error) {
+	if c.Null {
+		return nil, nil
+	}
+	b, err := appendCompositeValue(nil, reflect.ValueOf(&c.V).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+// compositeField is a struct field mapped to a composite attribute.
+type compositeField struct {
+	name  string
+	typ   string
+	index int
+}
+
+var compositeFieldsCache sync.Map // map[reflect.Type][]compositeField
+
+// compositeFields returns the fields of the struct type t mapped to
+// composite attributes, in declaration order.
+func compositeFields(t reflect.Type) []compositeField {
+	if fields, ok := compositeFieldsCache.Load(t); ok {
+		return fields.([]compositeField)
+	}
+
+	var fields []compositeField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := f.Tag.Get("pg")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		field := compositeField{name: name, index: i}
+		for _, opt := range strings.Split(opts, ",") {
+			if strings.HasPrefix(opt, "type=") {
+				field.typ = strings.TrimPrefix(opt, "type=")
+			}
+		}
+		fields = append(fields, field)
+	}
+
+	compositeFieldsCache.Store(t, fields)
+	return fields
+}
+
+// decodeComposite decodes the composite text src into the struct dst.
+func decodeComposite(dst reflect.Value, src []byte) error {
+	if dst.Kind() != reflect.Struct {
+		return fmt.Errorf("pg: cannot decode composite into %s", dst.Type())
+	}
+
+	values, err := ParseComposite(src)
+	if err != nil {
+		return err
+	}
+	fields := compositeFields(dst.Type())
+	if len(values) != len(fields) {
+		return fmt.Errorf("pg: cannot decode composite with %d attributes into %s with %d fields", len(values), dst.Type(), len(fields))
+	}
+
+	for i, f := range fields {
+		var err error
+		if f.typ != "" {
+			err = decodeTypedField(dst.Field(f.index), f.typ, values[i])
+		} else {
+			err = decodeCompositeField(dst.Field(f.index), values[i])
+		}
+		if err != nil {
+			return fmt.Errorf("pg: composite attribute %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// decodeTypedField decodes one attribute value of the named database type
+// into dst with DecodeValue.
+func decodeTypedField(dst reflect.Value, typ string, src []byte) error {
+	v, err := DecodeValue(typ, src)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.Type().AssignableTo(dst.Type()) {
+		return fmt.Errorf("cannot assign %s to %s", rv.Type(), dst.Type())
+	}
+	dst.Set(rv)
+	return nil
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// decodeCompositeField decodes one attribute value into dst. A nil src is
+// NULL.
+func decodeCompositeField(dst reflect.Value, src []byte) error {
+	if dst.Kind() == reflect.Ptr {
+		if src == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		v := reflect.New(dst.Type().Elem())
+		if err := decodeCompositeField(v.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(v)
+		return nil
+	}
+
+	if dst.CanAddr() && dst.Addr().Type().Implements(scannerType) {
+		var v interface{}
+		if src != nil {
+			v = src
+		}
+		return dst.Addr().Interface().(sql.Scanner).Scan(v)
+	}
+	if src == nil {
+		if dst.Kind() == reflect.Slice || dst.Kind() == reflect.Interface {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		return fmt.Errorf("cannot convert NULL to %s", dst.Type())
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(string(src))
+		return nil
+	case reflect.Interface:
+		if !reflect.TypeOf("").AssignableTo(dst.Type()) {
+			break
+		}
+		dst.Set(reflect.ValueOf(string(src)))
+		return nil
+	case reflect.Bool:
+		switch string(src) {
+		case "t", "true":
+			dst.SetBool(true)
+		case "f", "false":
+			dst.SetBool(false)
+		default:
+			return fmt.Errorf("invalid boolean %q", src)
+		}
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(string(src), 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(string(src), 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(string(src), dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(f)
+		return nil
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := parseBytea(src)
+			if err != nil {
+				return err
+			}
+			dst.SetBytes(b)
+			return nil
+		}
+		return decodeArrayField(dst, src)
+	case reflect.Struct:
+		if dst.Type() == timeType {
+			t, err := parseTimestamp(src, time.UTC)
+			if err != nil {
+				return err
+			}
+			dst.Set(reflect.ValueOf(t))
+			return nil
+		}
+		return decodeComposite(dst, src)
+	}
+
+	return fmt.Errorf("unsupported field type %s", dst.Type())
+}
+
+// decodeArrayField decodes the one-dimensional array text src into the
+// slice dst, decoding each element as a composite attribute would be. This
+// covers array attributes of composites as well as arrays of composites.
+func decodeArrayField(dst reflect.Value, src []byte) error {
+	elems, err := scanLinearArray(src, arrayDelimiterOf(dst.Type().Elem()), dst.Type().String(), nil)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.MakeSlice(dst.Type(), len(elems), len(elems))
+	for i, elem := range elems {
+		if err := decodeCompositeField(v.Index(i), elem); err != nil {
+			return fmt.Errorf("array element index %d: %w", i, err)
+		}
+	}
+	dst.Set(v)
+	return nil
+}
+
+// appendArrayValue appends the slice v as a one-dimensional array literal,
+// encoding each element as a composite attribute would be.
+func appendArrayValue(b []byte, v reflect.Value) ([]byte, error) {
+	del := arrayDelimiterOf(v.Type().Elem())
+	b = append(b, '{')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			b = append(b, del...)
+		}
+		elem, err := encodeCompositeField(v.Index(i))
+		if err != nil {
+			return nil, fmt.Errorf("array element index %d: %w", i, err)
+		}
+		if elem == nil {
+			b = append(b, "NULL"...)
+		} else {
+			b = appendArrayElementDelim(b, elem, del)
+		}
+	}
+	return append(b, '}'), nil
+}
+
+var arrayDelimiterType = reflect.TypeOf((*ArrayDelimiter)(nil)).Elem()
+
+// arrayDelimiterOf returns the delimiter of arrays of the type t, a comma
+// unless t or a pointer to it implements ArrayDelimiter.
+func arrayDelimiterOf(t reflect.Type) []byte {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface {
+		return []byte{','}
+	}
+	var d ArrayDelimiter
+	if t.Implements(arrayDelimiterType) {
+		d = reflect.Zero(t).Interface().(ArrayDelimiter)
+	} else if reflect.PtrTo(t).Implements(arrayDelimiterType) {
+		d = reflect.New(t).Interface().(ArrayDelimiter)
+	}
+	if d != nil {
+		if del := d.ArrayDelimiter(); del != "" {
+			return []byte(del)
+		}
+	}
+	return []byte{','}
+}
+
+// CompositeArray represents an array of a composite type, such as
+// address[], mapped onto a slice of the struct T. See Composite for how
+// attributes map onto the fields of T. A nil V is NULL.
+type CompositeArray[T any] struct {
+	V []T
+}
+
+// Scan implements the sql.Scanner interface.
+func (a *CompositeArray[T]) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return a.scanBytes(src)
+	case string:
+		return a.scanBytes([]byte(src))
+	case nil:
+		a.V = nil
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to CompositeArray[%T]", src, *new(T))
+}
+
+func (a *CompositeArray[T]) scanBytes(src []byte) error {
+	var v []T
+	if err := decodeArrayField(reflect.ValueOf(&v).Elem(), src); err != nil {
+		return fmt.Errorf("pg: %w", err)
+	}
+	a.V = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (a CompositeArray[T]) Value() (driver.Value, error) {
+	if a.V == nil {
+		return nil, nil
+	}
+	b, err := appendArrayValue(nil, reflect.ValueOf(a.V))
+	if err != nil {
+		return nil, fmt.Errorf("pg: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// appendCompositeValue appends the composite text of the struct v to b.
+func appendCompositeValue(b []byte, v reflect.Value) ([]byte, error) {
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("pg: cannot encode %s as composite", v.Type())
+	}
+
+	fields := compositeFields(v.Type())
+	values := make([][]byte, len(fields))
+	for i, f := range fields {
+		var err error
+		if values[i], err = encodeCompositeField(v.Field(f.index)); err != nil {
+			return nil, fmt.Errorf("pg: composite attribute %s: %w", f.name, err)
+		}
+	}
+
+	return appendComposite(b, values), nil
+}
+
+// encodeCompositeField returns the text of one attribute value, or nil for
+// NULL.
+func encodeCompositeField(v reflect.Value) ([]byte, error) {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, nil
+	}
+	if v.Kind() == reflect.Interface {
+		return encodeCompositeField(v.Elem())
+	}
+	if !v.Type().Implements(valuerType) && v.CanAddr() && v.Addr().Type().Implements(valuerType) {
+		v = v.Addr()
+	}
+	if v.Type().Implements(valuerType) {
+		dv, err := v.Interface().(driver.Valuer).Value()
+		if err != nil {
+			return nil, err
+		}
+		return encodeDriverValue(dv)
+	}
+	if v.Kind() == reflect.Ptr {
+		return encodeCompositeField(v.Elem())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return []byte(v.String()), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return []byte("t"), nil
+		}
+		return []byte("f"), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(nil, v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.AppendUint(nil, v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return appendFloat(nil, v.Float(), v.Type().Bits()), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return appendByteaHex(nil, v.Bytes()), nil
+		}
+		return appendArrayValue(nil, v)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return appendTimestamp(nil, v.Interface().(time.Time), true), nil
+		}
+		return appendCompositeValue(nil, v)
+	}
+
+	return nil, fmt.Errorf("unsupported field type %s", v.Type())
+}
+
+// encodeDriverValue returns the text of a driver.Value, or nil for NULL.
+func encodeDriverValue(v driver.Value) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case int64:
+		return strconv.AppendInt(nil, v, 10), nil
+	case float64:
+		return appendFloat(nil, v, 64), nil
+	case bool:
+		if v {
+			return []byte("t"), nil
+		}
+		return []byte("f"), nil
+	case time.Time:
+		return appendTimestamp(nil, v, true), nil
+	}
+
+	return nil, fmt.Errorf("unsupported driver value %T", v)
+}
+
+func appendFloat(b []byte, f float64, bits int) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(b, "NaN"...)
+	case math.IsInf(f, 1):
+		return append(b, "Infinity"...)
+	case math.IsInf(f, -1):
+		return append(b, "-Infinity"...)
+	}
+	return strconv.AppendFloat(b, f, 'g', -1, bits)
+}
+
+func appendByteaHex(b, v []byte) []byte {
+	b = append(b, '\\', 'x')
+	n := len(b)
+	b = append(b, make([]byte, hex.EncodedLen(len(v)))...)
+	hex.Encode(b[n:], v)
+	return b
+}
@@ -0,0 +1,263 @@
+package pg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseComposite(t *testing.T) {
+	tests := []struct {
+		src  string
+		want [][]byte
+	}{
+		{`()`, [][]byte{nil}},
+		{`(1)`, [][]byte{[]byte("1")}},
+		{`(1,"b,c",,d)`, [][]byte{[]byte("1"), []byte("b,c"), nil, []byte("d")}},
+		{`("",)`, [][]byte{{}, nil}},
+		{`("a ""b"" c","d\\e")`, [][]byte{[]byte(`a "b" c`), []byte(`d\e`)}},
+		{`(a\,b, c )`, [][]byte{[]byte("a,b"), []byte(" c ")}},
+		{`("(1,2)","{a,b}")`, [][]byte{[]byte("(1,2)"), []byte("{a,b}")}},
+	}
+	for _, tt := range tests {
+		got, err := ParseComposite([]byte(tt.src))
+		if err != nil {
+			t.Errorf("ParseComposite(%s): %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseComposite(%s) = %q, want %q", tt.src, got, tt.want)
+		}
+		if f := FormatComposite(got); f != tt.src {
+			if again, err := ParseComposite([]byte(f)); err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("ParseComposite(FormatComposite(%q)) = %q, %v", got, again, err)
+			}
+		}
+	}
+}
+
+func TestParseCompositeInvalid(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`(`,
+		`1,2`,
+		`(1,2`,
+		`("a)`,
+		`(1)x`,
+		`(a\`,
+	} {
+		_, err := ParseComposite([]byte(src))
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Type != "composite" {
+			t.Errorf("ParseComposite(%s) error = %v, want a composite ParseError", src, err)
+		}
+	}
+}
+
+func TestNormalizeComposite(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{`(1,2)`, `(1,2)`},
+		{`("1","2")`, `(1,2)`},
+		{`(,"")`, `(,"")`},
+		{`(a\,b,"c d","e""f",g\\h)`, `("a,b","c d","e""f","g\\h")`},
+		{`("(1,2)")`, `("(1,2)")`},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeComposite([]byte(tt.src))
+		if err != nil {
+			t.Errorf("NormalizeComposite(%s): %v", tt.src, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeComposite(%s) = %s, want %s", tt.src, got, tt.want)
+		}
+		if again, err := NormalizeComposite([]byte(got)); err != nil || again != got {
+			t.Errorf("NormalizeComposite(%s) = %s, %v, want it unchanged", got, again, err)
+		}
+	}
+}
+
+func TestArrayDelimiter(t *testing.T) {
+	tests := []struct {
+		typ  reflect.Type
+		want string
+	}{
+		{reflect.TypeOf(""), ","},
+		{reflect.TypeOf(Box{}), ";"},
+		{reflect.TypeOf(&Box{}), ";"},
+		{reflect.TypeOf(testPipeDelim{}), "|"},
+		{reflect.TypeOf(testEmptyDelim{}), ","},
+		{reflect.TypeOf((*ArrayDelimiter)(nil)).Elem(), ","},
+	}
+	for _, tt := range tests {
+		if got := string(arrayDelimiterOf(tt.typ)); got != tt.want {
+			t.Errorf("arrayDelimiterOf(%s) = %q, want %q", tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestCompositeArrayDelimiter(t *testing.T) {
+	src := `{(2,2),(0,0);(3,3),(1,1)}`
+	var boxes CompositeArray[Box]
+	if err := boxes.Scan([]byte(src)); err != nil {
+		t.Fatal(err)
+	}
+	want := []Box{{High: Point{X: 2, Y: 2}}, {High: Point{X: 3, Y: 3}, Low: Point{X: 1, Y: 1}}}
+	if !reflect.DeepEqual(boxes.V, want) {
+		t.Errorf("got %+v, want %+v", boxes.V, want)
+	}
+	if v, err := boxes.Value(); err != nil || v != src {
+		t.Errorf("Value = %v, %v, want %s", v, err, src)
+	}
+
+	// The delimiter of a type with a pointer receiver is used as well.
+	var pipes CompositeArray[testPipeDelim]
+	if err := pipes.Scan([]byte(`{(a,b)|(c,d)}`)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []testPipeDelim{{"a", "b"}, {"c", "d"}}; !reflect.DeepEqual(pipes.V, want) {
+		t.Errorf("got %+v, want %+v", pipes.V, want)
+	}
+	// Commas need no quotes.
+	if v, err := pipes.Value(); err != nil || v != `{(a,b)|(c,d)}` {
+		t.Errorf("Value = %v, %v, want {(a,b)|(c,d)}", v, err)
+	}
+}
+
+// testPipeDelim is a composite type whose arrays are separated by '|'.
+type testPipeDelim struct {
+	A string `pg:"a"`
+	B string `pg:"b"`
+}
+
+func (*testPipeDelim) ArrayDelimiter() string { return "|" }
+
+// testEmptyDelim has an ArrayDelimiter method returning no delimiter.
+type testEmptyDelim struct{}
+
+func (testEmptyDelim) ArrayDelimiter() string { return "" }
+
+type testAddress struct {
+	Street string  `pg:"street"`
+	Zip    *string `pg:"zip"`
+}
+
+type testCustomer struct {
+	ID      int64       `pg:"id"`
+	Name    string      `pg:"name"`
+	Active  bool        `pg:"active"`
+	Score   float64     `pg:"score"`
+	Tags    []string    `pg:"tags"`
+	Address testAddress `pg:"address"`
+	Created time.Time   `pg:"created"`
+}
+
+func TestCompositeScanValue(t *testing.T) {
+	zip := "10115"
+	tests := []struct {
+		src  string
+		want testCustomer
+		// value is the Value if it is not src.
+		value string
+	}{
+		{
+			src: `(1,"Joe ""Jr""",t,1.5,"{a,""b c""}","(""Main St"",10115)","2020-01-01 12:00:00+00")`,
+			want: testCustomer{
+				ID: 1, Name: `Joe "Jr"`, Active: true, Score: 1.5, Tags: []string{"a", "b c"},
+				Address: testAddress{Street: "Main St", Zip: &zip},
+				Created: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+			},
+			value: `(1,"Joe ""Jr""",t,1.5,"{a,""b c""}","(""Main St"",10115)","2020-01-01 12:00:00+00:00")`,
+		},
+		{
+			src:   `(2,"",f,0,{},"("""",)","2020-01-01 00:00:00+00")`,
+			want:  testCustomer{ID: 2, Tags: []string{}, Created: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+			value: `(2,"",f,0,{},"("""",)","2020-01-01 00:00:00+00:00")`,
+		},
+	}
+	for _, tt := range tests {
+		var c Composite[testCustomer]
+		if err := c.Scan([]byte(tt.src)); err != nil {
+			t.Errorf("Scan(%s): %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(c.V, tt.want) {
+			t.Errorf("Scan(%s) = %+v, want %+v", tt.src, c.V, tt.want)
+		}
+		v, err := c.Value()
+		if err != nil {
+			t.Errorf("Value of %s: %v", tt.src, err)
+			continue
+		}
+		if v != tt.value {
+			t.Errorf("Value of %s = %s, want %s", tt.src, v, tt.value)
+		}
+	}
+}
+
+func TestCompositeNull(t *testing.T) {
+	testScanNull(t, new(Composite[testAddress]))
+
+	// A NULL attribute of a nested composite type stays NULL.
+	var c Composite[struct {
+		ID    int32     `pg:"id"`
+		Range Int4Range `pg:"range"`
+	}]
+	if err := c.Scan(`(1,)`); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.Value(); err != nil || v != `(1,)` {
+		t.Fatalf("Value = %#v, %v, want (1,)", v, err)
+	}
+	if c.Null {
+		t.Fatal("Null set after scanning a row")
+	}
+}
+
+func TestCompositeScanInvalid(t *testing.T) {
+	for _, src := range []string{
+		`(1,,t,1.5,{},"(a,)","2020-01-01 00:00:00+00")`,
+		`(x,a,t,1.5,{},"(a,)","2020-01-01 00:00:00+00")`,
+		`(1,a,t,1.5,{},"(a,)")`,
+		`(1,a,t,1.5,{},"(a,)","2020-01-01 00:00:00+00",x)`,
+		`(1,a,t,1.5,{},"(a,b,c)","2020-01-01 00:00:00+00")`,
+	} {
+		var c Composite[testCustomer]
+		if err := c.Scan(src); err == nil {
+			t.Errorf("Scan(%s) = %+v, want error", src, c.V)
+		}
+	}
+}
+
+func TestCompositeArray(t *testing.T) {
+	var a CompositeArray[testAddress]
+	src := `{"(\"Main St\",10115)","(Elm,)",NULL}`
+	if err := a.Scan(src); err == nil {
+		t.Fatalf("Scan(%s) = %+v, want an error for the NULL element", src, a.V)
+	}
+
+	src = `{"(\"Main St\",10115)","(Elm,)"}`
+	if err := a.Scan(src); err != nil {
+		t.Fatal(err)
+	}
+	zip := "10115"
+	want := []testAddress{{Street: "Main St", Zip: &zip}, {Street: "Elm"}}
+	if !reflect.DeepEqual(a.V, want) {
+		t.Fatalf("Scan(%s) = %+v, want %+v", src, a.V, want)
+	}
+	v, err := a.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != src {
+		t.Fatalf("Value = %s, want %s", v, src)
+	}
+
+	testScanNull(t, new(CompositeArray[testAddress]))
+	if v, err := (CompositeArray[testAddress]{V: []testAddress{}}).Value(); err != nil || v != "{}" {
+		t.Fatalf("Value of an empty array = %v, %v, want {}", v, err)
+	}
+}
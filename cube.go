@@ -0,0 +1,150 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// maxCubeDim is the maximum number of dimensions of a cube.
+const maxCubeDim = 100
+
+// Cube represents a value of the cube extension type, an n-dimensional cube
+// given by two opposite corners, such as (1,2,3),(4,5,6). For a point, a
+// cube written with a single corner such as (1,2,3), Lower and Upper are
+// equal. A nil Lower is NULL.
+type Cube struct {
+	Lower []float64
+	Upper []float64
+}
+
+// Scan implements the sql.Scanner interface.
+func (c *Cube) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return c.scanBytes(src)
+	case string:
+		return c.scanBytes([]byte(src))
+	case nil:
+		*c = Cube{}
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Cube", src)
+}
+
+func (c *Cube) scanBytes(src []byte) error {
+	g := geomParser{src: src, typ: "Cube"}
+	var lower, upper []float64
+	var err error
+	switch g.peek() {
+	case '[':
+		g.pos++
+		if lower, err = cubeCorner(&g, true); err == nil {
+			if err = g.expect(','); err == nil {
+				if upper, err = cubeCorner(&g, true); err == nil {
+					err = g.expect(']')
+				}
+			}
+		}
+	case '(':
+		if lower, err = cubeCorner(&g, true); err == nil && g.peek() == ',' {
+			g.pos++
+			upper, err = cubeCorner(&g, true)
+		}
+	default:
+		lower, err = cubeCorner(&g, false)
+	}
+	if err == nil {
+		err = g.end()
+	}
+	if err != nil {
+		return err
+	}
+
+	if upper == nil {
+		upper = append([]float64(nil), lower...)
+	} else if len(upper) != len(lower) {
+		return parseErrorf("Cube", src, -1, "corners have %d and %d dimensions", len(lower), len(upper))
+	}
+	*c = Cube{Lower: lower, Upper: upper}
+	return nil
+}
+
+// cubeCorner parses a comma-separated list of coordinates, enclosed in
+// parentheses if paren is set.
+func cubeCorner(g *geomParser, paren bool) ([]float64, error) {
+	if paren {
+		if err := g.expect('('); err != nil {
+			return nil, err
+		}
+	}
+	var coords []float64
+	for {
+		f, err := g.float()
+		if err != nil {
+			return nil, err
+		}
+		if coords = append(coords, f); len(coords) > maxCubeDim {
+			return nil, g.errorf("more than %d dimensions", maxCubeDim)
+		}
+		if g.peek() != ',' {
+			break
+		}
+		g.pos++
+	}
+	if paren {
+		return coords, g.expect(')')
+	}
+	return coords, nil
+}
+
+// Value implements the driver.Valuer interface.
+func (c Cube) Value() (driver.Value, error) {
+	if c.Lower == nil {
+		return nil, nil
+	}
+	if len(c.Lower) == 0 || len(c.Lower) > maxCubeDim {
+		return nil, fmt.Errorf("pg: invalid Cube: %d dimensions", len(c.Lower))
+	}
+	if c.Upper != nil && len(c.Upper) != len(c.Lower) {
+		return nil, fmt.Errorf("pg: invalid Cube: corners have %d and %d dimensions", len(c.Lower), len(c.Upper))
+	}
+
+	b := appendCubeCorner(nil, c.Lower)
+	if !c.IsPoint() {
+		b = append(b, ',')
+		b = appendCubeCorner(b, c.Upper)
+	}
+	return string(b), nil
+}
+
+// IsPoint reports whether c is a point, with both corners the same.
+func (c Cube) IsPoint() bool {
+	if c.Upper == nil {
+		return true
+	}
+	for i := range c.Lower {
+		if i >= len(c.Upper) || c.Lower[i] != c.Upper[i] {
+			return false
+		}
+	}
+	return len(c.Lower) == len(c.Upper)
+}
+
+// Dim returns the number of dimensions of c.
+func (c Cube) Dim() int {
+	return len(c.Lower)
+}
+
+// appendCubeCorner appends coords the way the extension outputs them, such
+// as (1, 2, 3).
+func appendCubeCorner(b []byte, coords []float64) []byte {
+	b = append(b, '(')
+	for i, f := range coords {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = appendFloat(b, f, 64)
+	}
+	return append(b, ')')
+}
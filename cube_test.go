@@ -0,0 +1,65 @@
+package pg
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCubeScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Cube), `(1)`, ""},
+		{new(Cube), `(1, 2, 3)`, ""},
+		{new(Cube), `(1, 2),(3, 4)`, ""},
+		{new(Cube), `(1,2),(1,2)`, `(1, 2)`},
+		{new(Cube), `[(1,2),(3,4)]`, `(1, 2),(3, 4)`},
+		{new(Cube), `1,2,3`, `(1, 2, 3)`},
+		{new(Cube), `(-1.5e3, 0.25)`, `(-1500, 0.25)`},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(Cube) },
+		``,
+		`()`,
+		`(1,2),(3)`,
+		`(1,2`,
+		`[(1,2),(3,4)`,
+		`(1),(2),(3)`,
+		`(a)`,
+		`(`+strings.Repeat("1,", maxCubeDim)+`1)`,
+	)
+	testScanNull(t, new(Cube))
+}
+
+func TestCubeScan(t *testing.T) {
+	var c Cube
+	if err := c.Scan("(1,2),(3,4)"); err != nil {
+		t.Fatal(err)
+	}
+	want := Cube{Lower: []float64{1, 2}, Upper: []float64{3, 4}}
+	if !reflect.DeepEqual(c, want) || c.IsPoint() || c.Dim() != 2 {
+		t.Errorf("Scan = %+v, want %+v", c, want)
+	}
+
+	if err := c.Scan("(5,6)"); err != nil {
+		t.Fatal(err)
+	}
+	if !c.IsPoint() || !reflect.DeepEqual(c.Upper, []float64{5, 6}) {
+		t.Errorf("Scan of a point = %+v", c)
+	}
+}
+
+func TestCubeValueInvalid(t *testing.T) {
+	for _, c := range []Cube{
+		{Lower: []float64{}},
+		{Lower: []float64{1, 2}, Upper: []float64{3}},
+		{Lower: make([]float64, maxCubeDim+1)},
+	} {
+		if v, err := c.Value(); err == nil {
+			t.Errorf("Value of %+v = %v, want error", c, v)
+		}
+	}
+
+	if v, err := (Cube{Lower: []float64{1, 2}}).Value(); err != nil || v != "(1, 2)" {
+		t.Errorf("Value without Upper = %v, %v", v, err)
+	}
+}
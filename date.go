@@ -0,0 +1,124 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Date represents a PostgreSQL date value as a calendar date, without a time
+// of day or location, so that it cannot shift when converted between zones.
+// Years before 1 AD are 0 for 1 BC, -1 for 2 BC and so on. The special
+// values infinity and -infinity have Infinite set to 1 or -1. Use a *Date to
+// scan nullable columns.
+type Date struct {
+	Year     int
+	Month    time.Month
+	Day      int
+	Infinite int
+}
+
+// DateOf returns the calendar date of t in its own location.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// Time returns midnight at the start of d in loc. It returns the zero
+// time.Time for infinite dates.
+func (d Date) Time(loc *time.Location) time.Time {
+	if d.Infinite != 0 {
+		return time.Time{}
+	}
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// Scan implements the sql.Scanner interface.
+func (d *Date) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case time.Time:
+		*d = DateOf(src)
+		return nil
+	case []byte:
+		return d.scanBytes(src)
+	case string:
+		return d.scanBytes([]byte(src))
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Date", src)
+}
+
+func (d *Date) scanBytes(src []byte) error {
+	if inf := infinitySign(src); inf != 0 {
+		*d = Date{Infinite: inf}
+		return nil
+	}
+
+	t, err := parseDate(src)
+	if err != nil {
+		return err
+	}
+
+	*d = DateOf(t)
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (d Date) Value() (driver.Value, error) {
+	b, err := d.AppendValue(nil)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// AppendValue appends the text of d to dst, like Value but without
+// allocating a string.
+func (d Date) AppendValue(dst []byte) ([]byte, error) {
+	if d.Infinite == 0 {
+		if t := d.Time(time.UTC); t.Month() != d.Month || t.Day() != d.Day {
+			return dst, fmt.Errorf("pg: invalid Date %d-%02d-%02d", d.Year, int(d.Month), d.Day)
+		}
+	}
+
+	return d.appendText(dst), nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. The date is encoded
+// as a string in the ISO DateStyle, such as "2006-01-02" or "infinity".
+func (d Date) MarshalJSON() ([]byte, error) {
+	b, err := d.AppendValue(nil)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(b))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return d.scanBytes([]byte(s))
+}
+
+// String returns d in the ISO DateStyle, such as "2006-01-02" or
+// "0044-03-15 BC".
+func (d Date) String() string {
+	return string(d.appendText(nil))
+}
+
+func (d Date) appendText(b []byte) []byte {
+	switch {
+	case d.Infinite > 0:
+		return append(b, "infinity"...)
+	case d.Infinite < 0:
+		return append(b, "-infinity"...)
+	}
+	return appendDateValue(b, d.Time(time.UTC))
+}
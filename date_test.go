@@ -0,0 +1,73 @@
+package pg
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDateScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Date), `2006-01-02`, ""},
+		{new(Date), `2024-02-29`, ""},
+		{new(Date), `0044-03-15 BC`, ""},
+		{new(Date), `12345-06-07`, ""},
+		{new(Date), `infinity`, ""},
+		{new(Date), `-infinity`, ""},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(Date) },
+		``,
+		`2023-02-29`,
+		`2006-01-02 15:04:05`,
+		`January 2, 2006`,
+	)
+	if err := new(Date).Scan(nil); err == nil {
+		t.Error("expected error scanning NULL into Date")
+	}
+}
+
+func TestDateTime(t *testing.T) {
+	loc := time.FixedZone("", -8*3600)
+	// Late in the evening west of UTC the date is a day behind UTC.
+	tm := time.Date(2006, 1, 2, 23, 0, 0, 0, loc)
+	var d Date
+	if err := d.Scan(tm); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Date{Year: 2006, Month: time.January, Day: 2}); d != want {
+		t.Errorf("Scan(time.Time) = %+v, want %+v", d, want)
+	}
+	if got := d.Time(loc); !got.Equal(time.Date(2006, 1, 2, 0, 0, 0, 0, loc)) {
+		t.Errorf("Time = %s", got)
+	}
+	if got := (Date{Infinite: 1}).Time(time.UTC); !got.IsZero() {
+		t.Errorf("Time of infinity = %s", got)
+	}
+	if bc := (Date{Year: -43, Month: time.March, Day: 15}).String(); bc != "0044-03-15 BC" {
+		t.Errorf("String = %s", bc)
+	}
+
+	if _, err := (Date{Year: 2023, Month: time.February, Day: 29}).Value(); err == nil {
+		t.Error("expected error for February 29 2023")
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	for _, tt := range []struct {
+		d    Date
+		data string
+	}{
+		{Date{Year: 2006, Month: time.January, Day: 2}, `"2006-01-02"`},
+		{Date{Infinite: -1}, `"-infinity"`},
+	} {
+		b, err := json.Marshal(tt.d)
+		if err != nil || string(b) != tt.data {
+			t.Errorf("Marshal(%+v) = %s, %v, want %s", tt.d, b, err, tt.data)
+		}
+		var got Date
+		if err := json.Unmarshal([]byte(tt.data), &got); err != nil || got != tt.d {
+			t.Errorf("Unmarshal(%s) = %+v, %v", tt.data, got, err)
+		}
+	}
+}
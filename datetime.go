@@ -0,0 +1,293 @@
+package pg
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// parseTimestamp parses the text output of a timestamp or timestamptz value
+// in the ISO DateStyle, such as "2006-01-02 15:04:05.999999",
+// "2006-01-02 15:04:05+05:30" or "0044-03-15 12:00:00 BC". Values without a
+// zone offset are returned in loc.
+func parseTimestamp(src []byte, loc *time.Location) (time.Time, error) {
+	s := bytes.TrimSpace(src)
+	bc := false
+	if bytes.HasSuffix(s, []byte(" BC")) {
+		bc = true
+		s = bytes.TrimSpace(s[:len(s)-3])
+	}
+
+	year, month, day, i, err := parseDatePart(s)
+	if err != nil {
+		return time.Time{}, parseError("timestamp", src, -1, err)
+	}
+
+	var hour, min, sec, nsec int
+	if i < len(s) && (s[i] == ' ' || s[i] == 'T') {
+		if hour, min, sec, nsec, i, err = parseTimePart(s, i+1); err != nil {
+			return time.Time{}, parseError("timestamp", src, -1, err)
+		}
+	}
+
+	if i < len(s) {
+		var offset int
+		if offset, i, err = parseZoneOffset(s, i); err != nil {
+			return time.Time{}, parseError("timestamp", src, -1, err)
+		}
+		loc = fixedZone(offset)
+	}
+	if i != len(s) {
+		return time.Time{}, parseErrorf("timestamp", s, i, "unexpected %q", s[i])
+	}
+
+	if bc {
+		year = 1 - year
+	}
+	if d := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC); d.Day() != day {
+		return time.Time{}, parseErrorf("timestamp", src, -1, "day out of range")
+	}
+	return time.Date(year, time.Month(month), day, hour, min, sec, nsec, loc), nil
+}
+
+// parseDate parses the text output of a date value in the ISO DateStyle,
+// such as "2006-01-02" or "0044-03-15 BC", returning midnight UTC.
+func parseDate(src []byte) (time.Time, error) {
+	s := bytes.TrimSpace(src)
+	bc := false
+	if bytes.HasSuffix(s, []byte(" BC")) {
+		bc = true
+		s = bytes.TrimSpace(s[:len(s)-3])
+	}
+
+	year, month, day, i, err := parseDatePart(s)
+	if err != nil {
+		return time.Time{}, parseError("date", src, -1, err)
+	}
+	if i != len(s) {
+		return time.Time{}, parseErrorf("date", s, i, "unexpected %q", s[i])
+	}
+
+	if bc {
+		year = 1 - year
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if t.Day() != day {
+		return time.Time{}, parseErrorf("date", src, -1, "day out of range")
+	}
+	return t, nil
+}
+
+// parseDatePart parses YYYY-MM-DD, where the year may have more than four
+// digits.
+func parseDatePart(s []byte) (year, month, day, i int, err error) {
+	if year, i, err = parseDigits(s, 0, -1); err != nil {
+		return
+	}
+	if i < len(s) && s[i] == '-' {
+		month, i, err = parseDigits(s, i+1, 2)
+	} else {
+		err = fmt.Errorf("expected %q at offset %d", '-', i)
+	}
+	if err != nil {
+		return
+	}
+	if i < len(s) && s[i] == '-' {
+		day, i, err = parseDigits(s, i+1, 2)
+	} else {
+		err = fmt.Errorf("expected %q at offset %d", '-', i)
+	}
+	if err == nil && (month < 1 || month > 12 || day < 1 || day > 31) {
+		err = fmt.Errorf("date field value out of range")
+	}
+	return
+}
+
+// parseTimePart parses HH:MM:SS with an optional fractional part starting at
+// offset i.
+func parseTimePart(s []byte, i int) (hour, min, sec, nsec, end int, err error) {
+	if hour, i, err = parseDigits(s, i, 2); err != nil {
+		return
+	}
+	if i < len(s) && s[i] == ':' {
+		min, i, err = parseDigits(s, i+1, 2)
+	} else {
+		err = fmt.Errorf("expected %q at offset %d", ':', i)
+	}
+	if err != nil {
+		return
+	}
+	if i < len(s) && s[i] == ':' {
+		sec, i, err = parseDigits(s, i+1, 2)
+	} else {
+		err = fmt.Errorf("expected %q at offset %d", ':', i)
+	}
+	if err != nil {
+		return
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		start := i
+		for scale := 100000000; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			nsec += int(s[i]-'0') * scale
+			scale /= 10
+		}
+		if i == start {
+			err = fmt.Errorf("expected digit at offset %d", i)
+			return
+		}
+	}
+	// 24:00:00 is allowed by the server for time values.
+	if hour > 24 || min > 59 || sec > 60 || (hour == 24 && (min > 0 || sec > 0 || nsec > 0)) {
+		err = fmt.Errorf("time field value out of range")
+	}
+	return hour, min, sec, nsec, i, err
+}
+
+// parseZoneOffset parses a zone offset in the forms Z, +HH, +HH:MM,
+// +HH:MM:SS or +HHMM starting at offset i, returning it in seconds east of
+// UTC.
+func parseZoneOffset(s []byte, i int) (offset, end int, err error) {
+	if i < len(s) && s[i] == 'Z' {
+		return 0, i + 1, nil
+	}
+	if i >= len(s) {
+		return 0, i, fmt.Errorf("unexpected end of input")
+	}
+	if s[i] != '+' && s[i] != '-' {
+		return 0, i, fmt.Errorf("unexpected %q at offset %d", s[i], i)
+	}
+	sign := 1
+	if s[i] == '-' {
+		sign = -1
+	}
+
+	var hour, min, sec int
+	if hour, i, err = parseDigits(s, i+1, 2); err != nil {
+		return 0, i, err
+	}
+	if i+1 < len(s) && s[i] == ':' {
+		if min, i, err = parseDigits(s, i+1, 2); err != nil {
+			return 0, i, err
+		}
+		if i+1 < len(s) && s[i] == ':' {
+			if sec, i, err = parseDigits(s, i+1, 2); err != nil {
+				return 0, i, err
+			}
+		}
+	} else if i+1 < len(s) && s[i] >= '0' && s[i] <= '9' {
+		if min, i, err = parseDigits(s, i, 2); err != nil {
+			return 0, i, err
+		}
+	}
+	if hour > 15 || min > 59 || sec > 59 {
+		return 0, i, fmt.Errorf("time zone displacement out of range")
+	}
+
+	return sign * (hour*3600 + min*60 + sec), i, nil
+}
+
+// parseDigits parses a run of decimal digits starting at offset i. If n is
+// positive exactly n digits are required.
+func parseDigits(s []byte, i, n int) (v, end int, err error) {
+	start := i
+	for ; i < len(s) && s[i] >= '0' && s[i] <= '9' && (n < 0 || i-start < n); i++ {
+		v = v*10 + int(s[i]-'0')
+	}
+	if i == start || (n > 0 && i-start != n) {
+		return 0, i, fmt.Errorf("expected digit at offset %d", i)
+	}
+	return v, i, nil
+}
+
+func fixedZone(offset int) *time.Location {
+	if offset == 0 {
+		return time.UTC
+	}
+	return time.FixedZone("", offset)
+}
+
+// appendDateValue appends the calendar date of t in the ISO DateStyle.
+func appendDateValue(b []byte, t time.Time) []byte {
+	year := t.Year()
+	bc := year <= 0
+	if bc {
+		year = 1 - year
+	}
+
+	b = appendDate(b, year, t.Month(), t.Day())
+	if bc {
+		b = append(b, " BC"...)
+	}
+	return b
+}
+
+// appendTimestamp appends t in the ISO DateStyle. If withZone is set the
+// zone offset of t is appended as well.
+func appendTimestamp(b []byte, t time.Time, withZone bool) []byte {
+	year := t.Year()
+	bc := year <= 0
+	if bc {
+		year = 1 - year
+	}
+
+	b = appendDate(b, year, t.Month(), t.Day())
+	b = append(b, ' ')
+	b = appendClock(b, t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
+	if withZone {
+		_, offset := t.Zone()
+		b = appendZoneOffset(b, offset)
+	}
+	if bc {
+		b = append(b, " BC"...)
+	}
+	return b
+}
+
+func appendDate(b []byte, year int, month time.Month, day int) []byte {
+	b = appendPadded(b, year, 4)
+	b = append(b, '-')
+	b = appendPadded(b, int(month), 2)
+	b = append(b, '-')
+	return appendPadded(b, day, 2)
+}
+
+func appendClock(b []byte, hour, min, sec, nsec int) []byte {
+	b = appendPadded(b, hour, 2)
+	b = append(b, ':')
+	b = appendPadded(b, min, 2)
+	b = append(b, ':')
+	b = appendPadded(b, sec, 2)
+	if nsec > 0 {
+		frac := strconv.AppendInt(nil, int64(nsec)+1e9, 10)[1:]
+		b = append(b, '.')
+		b = append(b, bytes.TrimRight(frac, "0")...)
+	}
+	return b
+}
+
+func appendZoneOffset(b []byte, offset int) []byte {
+	if offset < 0 {
+		b = append(b, '-')
+		offset = -offset
+	} else {
+		b = append(b, '+')
+	}
+	b = appendPadded(b, offset/3600, 2)
+	b = append(b, ':')
+	b = appendPadded(b, offset%3600/60, 2)
+	if offset%60 != 0 {
+		b = append(b, ':')
+		b = appendPadded(b, offset%60, 2)
+	}
+	return b
+}
+
+func appendPadded(b []byte, v, width int) []byte {
+	s := strconv.AppendInt(nil, int64(v), 10)
+	for i := len(s); i < width; i++ {
+		b = append(b, '0')
+	}
+	return append(b, s...)
+}
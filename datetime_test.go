@@ -0,0 +1,82 @@
+package pg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	for _, tt := range []struct {
+		src  string
+		want time.Time
+		text string
+	}{
+		{`2006-01-02 15:04:05`, time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), `2006-01-02 15:04:05+00:00`},
+		{`2006-01-02 15:04:05.5`, time.Date(2006, 1, 2, 15, 4, 5, 5e8, time.UTC), `2006-01-02 15:04:05.5+00:00`},
+		{`2006-01-02 15:04:05.000001`, time.Date(2006, 1, 2, 15, 4, 5, 1000, time.UTC), `2006-01-02 15:04:05.000001+00:00`},
+		{`2006-01-02T15:04:05Z`, time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), `2006-01-02 15:04:05+00:00`},
+		{`2006-01-02 15:04:05+05:30`, time.Date(2006, 1, 2, 15, 4, 5, 0, time.FixedZone("", 19800)), `2006-01-02 15:04:05+05:30`},
+		{`2006-01-02 15:04:05-08`, time.Date(2006, 1, 2, 15, 4, 5, 0, time.FixedZone("", -28800)), `2006-01-02 15:04:05-08:00`},
+		{`1900-01-01 00:00:00+00:09:21`, time.Date(1900, 1, 1, 0, 0, 0, 0, time.FixedZone("", 561)), `1900-01-01 00:00:00+00:09:21`},
+		{`2006-01-02 15:04:05+0530`, time.Date(2006, 1, 2, 15, 4, 5, 0, time.FixedZone("", 19800)), `2006-01-02 15:04:05+05:30`},
+		{`0044-03-15 12:00:00 BC`, time.Date(-43, 3, 15, 12, 0, 0, 0, time.UTC), `0044-03-15 12:00:00+00:00 BC`},
+		{`12345-01-01 00:00:00`, time.Date(12345, 1, 1, 0, 0, 0, 0, time.UTC), `12345-01-01 00:00:00+00:00`},
+		{`2006-01-02`, time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC), `2006-01-02 00:00:00+00:00`},
+	} {
+		got, err := parseTimestamp([]byte(tt.src), time.UTC)
+		if err != nil {
+			t.Errorf("%s: %v", tt.src, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: got %s, want %s", tt.src, got, tt.want)
+		}
+		if text := string(appendTimestamp(nil, got, true)); text != tt.text {
+			t.Errorf("%s: appendTimestamp = %s, want %s", tt.src, text, tt.text)
+		}
+	}
+}
+
+func TestParseTimestampInvalid(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`2006`,
+		`2006-1-02 15:04:05`,
+		`2006-13-02 15:04:05`,
+		`2006-02-30 15:04:05`,
+		`2006-01-02 15:04`,
+		`2006-01-02 25:00:00`,
+		`2006-01-02 15:04:05.`,
+		`2006-01-02 15:04:05+16`,
+		`2006-01-02 15:04:05 UTC`,
+	} {
+		if got, err := parseTimestamp([]byte(src), time.UTC); err == nil {
+			t.Errorf("%s: got %s, want error", src, got)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	for _, tt := range []struct {
+		src  string
+		want time.Time
+	}{
+		{`2006-01-02`, time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{`2024-02-29`, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{`0001-01-01 BC`, time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		got, err := parseDate([]byte(tt.src))
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("%s: got %s, %v, want %s", tt.src, got, err, tt.want)
+		}
+		if text := string(appendDateValue(nil, got)); text != tt.src {
+			t.Errorf("%s: appendDateValue = %s", tt.src, text)
+		}
+	}
+
+	for _, src := range []string{``, `2023-02-29`, `2006-01-02 00:00:00`, `2006-01`} {
+		if got, err := parseDate([]byte(src)); err == nil {
+			t.Errorf("%s: got %s, want error", src, got)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var enumLabels sync.Map // map[reflect.Type]map[string]bool
+
+// RegisterEnum registers the labels of the enum type mapped onto the string
+// type T, which Enum[T] checks values against. Registering T again replaces
+// its labels.
+//
+//	type Mood string
+//
+//	pg.RegisterEnum[Mood]("sad", "ok", "happy")
+func RegisterEnum[T ~string](labels ...T) {
+	set := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		set[string(l)] = true
+	}
+	enumLabels.Store(reflect.TypeOf((*T)(nil)).Elem(), set)
+}
+
+// Enum represents a value of an enum type as the string type T, whose
+// labels must be registered with RegisterEnum. Scan and Value return an
+// error for labels that are not registered. Use a *Enum[T] to scan nullable
+// columns.
+type Enum[T ~string] struct {
+	V T
+}
+
+// Scan implements the sql.Scanner interface.
+func (e *Enum[T]) Scan(src interface{}) error {
+	var v T
+	switch src := src.(type) {
+	case []byte:
+		v = T(src)
+	case string:
+		v = T(src)
+	default:
+		return fmt.Errorf("pg: cannot convert %T to Enum[%T]", src, e.V)
+	}
+
+	if err := checkEnum(v); err != nil {
+		return err
+	}
+	e.V = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (e Enum[T]) Value() (driver.Value, error) {
+	if err := checkEnum(e.V); err != nil {
+		return nil, err
+	}
+	return string(e.V), nil
+}
+
+// checkEnum checks that v is a registered label of T.
+func checkEnum[T ~string](v T) error {
+	t := reflect.TypeOf(v)
+	set, ok := enumLabels.Load(t)
+	if !ok {
+		return fmt.Errorf("pg: no labels registered for enum %s", t)
+	}
+	if !set.(map[string]bool)[string(v)] {
+		return fmt.Errorf("pg: invalid label %q for enum %s", string(v), t)
+	}
+	return nil
+}
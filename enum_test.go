@@ -0,0 +1,54 @@
+package pg
+
+import (
+	"database/sql"
+	"testing"
+)
+
+type testMood string
+
+type testUnregistered string
+
+func TestEnumScanValue(t *testing.T) {
+	RegisterEnum[testMood]("sad", "ok", "happy")
+
+	testScanValue(t, []scanValueTest{
+		{new(Enum[testMood]), `sad`, ""},
+		{new(Enum[testMood]), `happy`, ""},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(Enum[testMood]) },
+		``,
+		`Happy`,
+		`angry`,
+	)
+	if err := new(Enum[testMood]).Scan(nil); err == nil {
+		t.Error("expected error scanning NULL into Enum")
+	}
+
+	if _, err := (Enum[testMood]{V: "angry"}).Value(); err == nil {
+		t.Error("expected error for an unknown label")
+	}
+}
+
+func TestEnumReregister(t *testing.T) {
+	RegisterEnum[testMood]("sad", "ok", "happy")
+	RegisterEnum[testMood]("meh")
+	defer RegisterEnum[testMood]("sad", "ok", "happy")
+
+	if _, err := (Enum[testMood]{V: "meh"}).Value(); err != nil {
+		t.Errorf("Value of a new label: %v", err)
+	}
+	if _, err := (Enum[testMood]{V: "sad"}).Value(); err == nil {
+		t.Error("expected error for a replaced label")
+	}
+}
+
+func TestEnumUnregistered(t *testing.T) {
+	var e Enum[testUnregistered]
+	if err := e.Scan("a"); err == nil {
+		t.Error("expected error for an unregistered enum")
+	}
+	if _, err := e.Value(); err == nil {
+		t.Error("expected error for an unregistered enum")
+	}
+}
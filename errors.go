@@ -0,0 +1,123 @@
+package pg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	// ErrInvalidArray is matched by the errors returned for malformed
+	// array input, in either the text or the binary format. The errors for
+	// sub-arrays of differing lengths, or for input exceeding a limit, match
+	// it as well as ErrDimensionMismatch or ErrLimitExceeded.
+	ErrInvalidArray = errors.New("pg: invalid array")
+
+	// ErrNullElement is wrapped by the errors returned when an array
+	// containing NULL is scanned into a type that cannot represent it.
+	ErrNullElement = errors.New("pg: NULL array element")
+
+	// ErrDimensionMismatch is wrapped by the errors returned when the
+	// dimensions of an array do not match, either between its
+	// sub-arrays or with those of the type it is scanned into.
+	ErrDimensionMismatch = errors.New("pg: array dimensions do not match")
+
+	// ErrLimitExceeded is matched by the errors returned for input
+	// exceeding one of the limits set in ParseOptions.
+	ErrLimitExceeded = errors.New("pg: parse limit exceeded")
+
+	// ErrInvalidUTF8 is wrapped by the errors returned for string
+	// elements that are not valid UTF-8, when ParseOptions asks for them
+	// to be rejected.
+	ErrInvalidUTF8 = errors.New("pg: invalid UTF-8 array element")
+)
+
+// maxSnippet is the length of input kept in a ParseError.
+const maxSnippet = 64
+
+// ParseError is returned by Scan and the parsing functions when the text of
+// a value is malformed, as opposed to when a value of the wrong Go type is
+// scanned.
+type ParseError struct {
+	// Type is the name of the type being parsed, such as "StringArray",
+	// or "array" if there is no Go type.
+	Type string
+	// Offset is the offset in the input at which the error was found, or
+	// -1 if it is not known.
+	Offset int
+	// Snippet is the input, or the part of it around Offset if it is
+	// long.
+	Snippet string
+	// Underlying describes the error. It may be nil.
+	Underlying error
+
+	// kind is the sentinel error the ParseError matches, if any.
+	kind error
+}
+
+func (e *ParseError) Error() string {
+	msg := "pg: unable to parse " + e.Type + " " + strconv.Quote(e.Snippet)
+	if e.Underlying != nil {
+		msg += ": " + e.Underlying.Error()
+	}
+	if e.Offset >= 0 {
+		msg += " at offset " + strconv.Itoa(e.Offset)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Underlying
+}
+
+// Is reports whether the error is of the kind of target, such as
+// ErrInvalidArray. Dimension and limit errors are also invalid arrays.
+func (e *ParseError) Is(target error) bool {
+	if e.kind == nil {
+		return false
+	}
+	if e.kind == target {
+		return true
+	}
+	return target == ErrInvalidArray && (e.kind == ErrDimensionMismatch || e.kind == ErrLimitExceeded)
+}
+
+// parseError returns a ParseError for the input src of the type typ. If
+// offset is not negative the snippet is taken from around it.
+func parseError[S ~string | ~[]byte](typ string, src S, offset int, err error) *ParseError {
+	s := string(src)
+	if len(s) > maxSnippet {
+		start := 0
+		if offset > maxSnippet/2 {
+			start = offset - maxSnippet/2
+		}
+		if start > len(s)-maxSnippet {
+			start = len(s) - maxSnippet
+		}
+		s = s[start : start+maxSnippet]
+	}
+	return &ParseError{Type: typ, Offset: offset, Snippet: s, Underlying: err}
+}
+
+// parseErrorf is like parseError with an underlying error formatted from
+// format and args.
+func parseErrorf[S ~string | ~[]byte](typ string, src S, offset int, format string, args ...interface{}) *ParseError {
+	return parseError(typ, src, offset, fmt.Errorf(format, args...))
+}
+
+// arrayParseErrorf is like parseErrorf for malformed array input, returning
+// a ParseError that matches ErrInvalidArray.
+func arrayParseErrorf(typ string, src []byte, offset int, format string, args ...interface{}) *ParseError {
+	e := parseErrorf(typ, src, offset, format, args...)
+	e.kind = ErrInvalidArray
+	return e
+}
+
+// limitErrorf is like parseErrorf for input exceeding a limit, returning a
+// ParseError that matches ErrLimitExceeded.
+func limitErrorf(typ string, src []byte, offset int, format string, args ...interface{}) *ParseError {
+	e := parseErrorf(typ, src, offset, format, args...)
+	e.kind = ErrLimitExceeded
+	return e
+}
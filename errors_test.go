@@ -0,0 +1,75 @@
+package pg
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	err := &ParseError{Type: "UUID", Offset: 3, Snippet: "abc", Underlying: errors.New("bad")}
+	if got, want := err.Error(), `pg: unable to parse UUID "abc": bad at offset 3`; got != want {
+		t.Errorf("Error() = %s, want %s", got, want)
+	}
+	err = &ParseError{Type: "UUID", Offset: -1, Snippet: "abc"}
+	if got, want := err.Error(), `pg: unable to parse UUID "abc"`; got != want {
+		t.Errorf("Error() = %s, want %s", got, want)
+	}
+}
+
+func TestParseErrorSnippet(t *testing.T) {
+	src := strings.Repeat("a", 100) + "x" + strings.Repeat("b", 100)
+	tests := []struct {
+		offset int
+		want   string
+	}{
+		{-1, src[:maxSnippet]},
+		{0, src[:maxSnippet]},
+		{100, src[100-maxSnippet/2 : 100+maxSnippet/2]},
+		{len(src) - 1, src[len(src)-maxSnippet:]},
+	}
+	for _, tt := range tests {
+		if got := parseError("text", src, tt.offset, nil).Snippet; got != tt.want {
+			t.Errorf("snippet at %d = %q, want %q", tt.offset, got, tt.want)
+		}
+	}
+	if got := parseError("text", "short", 2, nil).Snippet; got != "short" {
+		t.Errorf("snippet = %q, want the whole input", got)
+	}
+}
+
+func TestScanParseError(t *testing.T) {
+	tests := []struct {
+		scanner sql.Scanner
+		src     string
+		typ     string
+		offset  int
+	}{
+		{new(StringArray), `{a,b`, "StringArray", 4},
+		{new(StringArray), `{a,"b`, "StringArray", 5},
+		{new(StringArray), `{a}x`, "StringArray", 3},
+		{new(StringArray), `{{a}} x`, "StringArray", 6},
+		{new(CitextArray), `{a}x`, "CitextArray", 3},
+		{new(ByteaArray), `{}}`, "ByteaArray", 2},
+		{new(ByteaArray), `x`, "ByteaArray", 0},
+		{new(UUID), `a0eebc99`, "UUID", -1},
+	}
+	for _, tt := range tests {
+		err := tt.scanner.Scan([]byte(tt.src))
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%s: error %v is not a ParseError", tt.src, err)
+			continue
+		}
+		if perr.Type != tt.typ || perr.Offset != tt.offset || perr.Snippet != tt.src {
+			t.Errorf("%s: got %+v, want type %s at offset %d", tt.src, perr, tt.typ, tt.offset)
+		}
+	}
+
+	// Scanning the wrong Go type is not a parse error.
+	var perr *ParseError
+	if err := new(StringArray).Scan(1); err == nil || errors.As(err, &perr) {
+		t.Errorf("Scan(1) = %v, want an error other than ParseError", err)
+	}
+}
@@ -0,0 +1,418 @@
+// Package gen generates Go types for the composite and enum types of a
+// database, so that models stay in sync with the DDL.
+//
+// The type information is read with Query, either through a *sql.DB with
+// Load or from the output of psql with Parse, and turned into Go source with
+// Generate:
+//
+//	psql -XAt -c "$(go run github.com/onrik/pg/cmd/pggen -query)" > types.json
+//	go run github.com/onrik/pg/cmd/pggen -pkg models < types.json > types_gen.go
+package gen
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Query returns a single JSON document describing the standalone composite
+// types and the enum types of all non-system schemas.
+const Query = `SELECT coalesce(json_agg(x ORDER BY x.schema, x.name), '[]')
+FROM (
+	SELECT n.nspname AS schema, t.typname AS name, t.oid::int8 AS oid,
+		CASE t.typtype WHEN 'e' THEN 'enum' ELSE 'composite' END AS kind,
+		(SELECT coalesce(json_agg(json_build_object(
+				'name', a.attname,
+				'type', at.typname,
+				'type_schema', an.nspname,
+				'elem_type', et.typname,
+				'elem_schema', en.nspname
+			) ORDER BY a.attnum), '[]')
+		FROM pg_attribute a
+		JOIN pg_type at ON at.oid = a.atttypid
+		JOIN pg_namespace an ON an.oid = at.typnamespace
+		LEFT JOIN pg_type et ON et.oid = at.typelem AND at.typcategory = 'A'
+		LEFT JOIN pg_namespace en ON en.oid = et.typnamespace
+		WHERE a.attrelid = t.typrelid AND a.attnum > 0 AND NOT a.attisdropped) AS attributes,
+		(SELECT coalesce(json_agg(e.enumlabel ORDER BY e.enumsortorder), '[]')
+		FROM pg_enum e
+		WHERE e.enumtypid = t.oid) AS labels
+	FROM pg_type t
+	JOIN pg_namespace n ON n.oid = t.typnamespace
+	LEFT JOIN pg_class c ON c.oid = t.typrelid
+	WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+		AND n.nspname NOT LIKE 'pg\_toast%'
+		AND n.nspname NOT LIKE 'pg\_temp\_%'
+		AND (t.typtype = 'e' OR (t.typtype = 'c' AND c.relkind = 'c'))
+) x`
+
+// Kind is the kind of a database type.
+type Kind string
+
+const (
+	// Composite is a standalone composite type created by CREATE TYPE ... AS.
+	Composite Kind = "composite"
+	// Enum is an enum type created by CREATE TYPE ... AS ENUM.
+	Enum Kind = "enum"
+)
+
+// Type describes a composite or enum type.
+type Type struct {
+	Schema     string      `json:"schema"`
+	Name       string      `json:"name"`
+	OID        uint32      `json:"oid"`
+	Kind       Kind        `json:"kind"`
+	Attributes []Attribute `json:"attributes"`
+	Labels     []string    `json:"labels"`
+}
+
+// Attribute describes an attribute of a composite type. For array types
+// ElemType and ElemSchema name the element type.
+type Attribute struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	TypeSchema string `json:"type_schema"`
+	ElemType   string `json:"elem_type"`
+	ElemSchema string `json:"elem_schema"`
+}
+
+// Load runs Query on db and returns the types of the given schemas, or of
+// all schemas if none are given.
+func Load(ctx context.Context, db *sql.DB, schemas ...string) ([]Type, error) {
+	var src []byte
+	if err := db.QueryRowContext(ctx, Query).Scan(&src); err != nil {
+		return nil, err
+	}
+	types, err := Parse(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	return FilterSchemas(types, schemas...), nil
+}
+
+// Parse reads the output of Query from r.
+func Parse(r io.Reader) ([]Type, error) {
+	var types []Type
+	if err := json.NewDecoder(r).Decode(&types); err != nil {
+		return nil, fmt.Errorf("gen: cannot parse type information: %s", err)
+	}
+	return types, nil
+}
+
+// FilterSchemas returns the types belonging to one of the given schemas. If
+// no schemas are given all types are returned.
+func FilterSchemas(types []Type, schemas ...string) []Type {
+	if len(schemas) == 0 {
+		return types
+	}
+
+	var filtered []Type
+	for _, t := range types {
+		for _, s := range schemas {
+			if t.Schema == s {
+				filtered = append(filtered, t)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// Options control the generated code.
+type Options struct {
+	// Package is the name of the generated package.
+	Package string
+	// Pointers makes composite attributes pointers, so that NULL attributes
+	// can be scanned.
+	Pointers bool
+}
+
+// Generate writes Go source declaring a type for each of types. Composite
+// types become structs implementing sql.Scanner and driver.Valuer through
+// pg.Composite, and enum types become string types with a constant per
+// label.
+func Generate(w io.Writer, types []Type, opts Options) error {
+	if opts.Package == "" {
+		return fmt.Errorf("gen: no package name")
+	}
+
+	g := generator{
+		opts:    opts,
+		names:   map[string]string{},
+		imports: map[string]bool{},
+	}
+	sorted := append([]Type(nil), types...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Schema != sorted[j].Schema {
+			return sorted[i].Schema < sorted[j].Schema
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	if err := g.name(sorted); err != nil {
+		return err
+	}
+
+	for _, t := range sorted {
+		var err error
+		switch t.Kind {
+		case Composite:
+			err = g.composite(t)
+		case Enum:
+			err = g.enum(t)
+		default:
+			err = fmt.Errorf("gen: type %s.%s has unknown kind %q", t.Schema, t.Name, t.Kind)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	var header bytes.Buffer
+	fmt.Fprintf(&header, "// Code generated by pggen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", opts.Package)
+	for _, path := range []string{"database/sql/driver", "fmt", pgPackage} {
+		if !g.imports[path] {
+			continue
+		}
+		if path == pgPackage {
+			header.WriteString("\n")
+		}
+		fmt.Fprintf(&header, "\t%q\n", path)
+	}
+	header.WriteString(")\n")
+
+	src, err := format.Source(append(header.Bytes(), g.buf.Bytes()...))
+	if err != nil {
+		return fmt.Errorf("gen: cannot format generated code: %s", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// pgPackage is the import path of the pg package.
+const pgPackage = "github.com/onrik/pg"
+
+type generator struct {
+	buf   bytes.Buffer
+	opts  Options
+	names map[string]string
+	// imports holds the import paths of the packages the generated code
+	// refers to.
+	imports map[string]bool
+}
+
+// name sets the Go names of types. A type whose name is shared by a type in
+// another schema is named after its schema too, such as BillingAddress for
+// billing.address.
+func (g *generator) name(types []Type) error {
+	count := map[string]int{}
+	for _, t := range types {
+		count[goName(t.Name)]++
+	}
+	owner := map[string]string{}
+	for _, t := range types {
+		name := goName(t.Name)
+		if count[name] > 1 {
+			name = goName(t.Schema + "_" + t.Name)
+		}
+		key := t.Schema + "." + t.Name
+		if other, ok := owner[name]; ok {
+			return fmt.Errorf("gen: types %s and %s both have the Go name %s", other, key, name)
+		}
+		owner[name] = key
+		g.names[key] = name
+	}
+	return nil
+}
+
+// memberNames returns the Go names of the attributes or labels of t, made
+// by prefix + goName(name), or an error if two of them have the same Go
+// name, such as the labels in-progress and in_progress.
+func memberNames(t Type, what, prefix string, names []string) ([]string, error) {
+	goNames := make([]string, len(names))
+	owner := map[string]string{}
+	for i, n := range names {
+		goNames[i] = prefix + goName(n)
+		if other, ok := owner[goNames[i]]; ok {
+			return nil, fmt.Errorf("gen: %ss %q and %q of %s.%s both have the Go name %s", what, other, n, t.Schema, t.Name, goNames[i])
+		}
+		owner[goNames[i]] = n
+	}
+	return goNames, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) composite(t Type) error {
+	attrs := make([]string, len(t.Attributes))
+	for i, a := range t.Attributes {
+		attrs[i] = a.Name
+	}
+	fields, err := memberNames(t, "attribute", "", attrs)
+	if err != nil {
+		return err
+	}
+
+	g.imports["database/sql/driver"] = true
+	g.imports[pgPackage] = true
+	name := g.names[t.Schema+"."+t.Name]
+	g.printf("\n// %s is the composite type %s.%s.\n", name, t.Schema, t.Name)
+	g.printf("type %s struct {\n", name)
+	for i, a := range t.Attributes {
+		typ := g.goType(a.TypeSchema, a.Type, a.ElemSchema, a.ElemType)
+		if g.opts.Pointers && !strings.HasPrefix(typ, "[]") {
+			typ = "*" + typ
+		}
+		g.printf("\t%s %s `pg:%q`\n", fields[i], typ, a.Name)
+	}
+	g.printf("}\n")
+
+	g.printf("\n// Scan implements the sql.Scanner interface.\n")
+	g.printf("func (v *%s) Scan(src interface{}) error {\n", name)
+	g.printf("\tvar c pg.Composite[%s]\n", name)
+	g.printf("\tif err := c.Scan(src); err != nil {\n\t\treturn err\n\t}\n")
+	g.printf("\t*v = c.V\n\treturn nil\n}\n")
+
+	g.printf("\n// Value implements the driver.Valuer interface.\n")
+	g.printf("func (v %s) Value() (driver.Value, error) {\n", name)
+	g.printf("\treturn pg.Composite[%s]{V: v}.Value()\n}\n", name)
+	return nil
+}
+
+func (g *generator) enum(t Type) error {
+	name := g.names[t.Schema+"."+t.Name]
+	consts, err := memberNames(t, "label", name, t.Labels)
+	if err != nil {
+		return err
+	}
+
+	g.imports["database/sql/driver"] = true
+	g.imports["fmt"] = true
+	g.printf("\n// %s is the enum type %s.%s.\n", name, t.Schema, t.Name)
+	g.printf("type %s string\n\n", name)
+	g.printf("// Labels of %s.\nconst (\n", name)
+	for i, l := range t.Labels {
+		g.printf("\t%s %s = %q\n", consts[i], name, l)
+	}
+	g.printf(")\n")
+
+	g.printf("\n// Valid reports whether v is a label of %s.\n", name)
+	g.printf("func (v %s) Valid() bool {\n\tswitch v {\n\tcase ", name)
+	for i, c := range consts {
+		if i > 0 {
+			g.printf(", ")
+		}
+		g.printf("%s", c)
+	}
+	if len(t.Labels) == 0 {
+		g.printf("\"\"")
+	}
+	g.printf(":\n\t\treturn true\n\t}\n\treturn false\n}\n")
+
+	g.printf("\n// Scan implements the sql.Scanner interface.\n")
+	g.printf("func (v *%s) Scan(src interface{}) error {\n", name)
+	g.printf("\tvar s %s\n\tswitch src := src.(type) {\n", name)
+	g.printf("\tcase []byte:\n\t\ts = %s(src)\n", name)
+	g.printf("\tcase string:\n\t\ts = %s(src)\n", name)
+	g.printf("\tdefault:\n\t\treturn fmt.Errorf(\"cannot convert %%T to %s\", src)\n\t}\n", name)
+	g.printf("\tif !s.Valid() {\n\t\treturn fmt.Errorf(\"invalid %s label %%q\", string(s))\n\t}\n", name)
+	g.printf("\t*v = s\n\treturn nil\n}\n")
+
+	g.printf("\n// Value implements the driver.Valuer interface.\n")
+	g.printf("func (v %s) Value() (driver.Value, error) {\n", name)
+	g.printf("\tif !v.Valid() {\n\t\treturn nil, fmt.Errorf(\"invalid %s label %%q\", string(v))\n\t}\n", name)
+	g.printf("\treturn string(v), nil\n}\n")
+	return nil
+}
+
+// builtinTypes maps pg_catalog type names to Go types.
+var builtinTypes = map[string]string{
+	"bool":        "bool",
+	"int2":        "int16",
+	"int4":        "int32",
+	"int8":        "int64",
+	"oid":         "uint32",
+	"float4":      "float32",
+	"float8":      "float64",
+	"numeric":     "pg.Numeric",
+	"text":        "string",
+	"varchar":     "string",
+	"bpchar":      "string",
+	"name":        "string",
+	"char":        "string",
+	"citext":      "string",
+	"uuid":        "pg.UUID",
+	"bytea":       "[]byte",
+	"date":        "pg.Date",
+	"timestamp":   "pg.Timestamp",
+	"timestamptz": "pg.Timestamp",
+	"json":        "pg.JSONB",
+	"jsonb":       "pg.JSONB",
+	"int4range":   "pg.Int4Range",
+	"int8range":   "pg.Int8Range",
+	"numrange":    "pg.NumRange",
+	"tsrange":     "pg.TsRange",
+	"tstzrange":   "pg.TstzRange",
+	"daterange":   "pg.DateRange",
+}
+
+// builtinPackages maps the packages referred to in builtinTypes to their
+// import paths.
+var builtinPackages = map[string]string{
+	"pg": pgPackage,
+}
+
+// goType returns the Go type of an attribute, recording the package it is
+// declared in as imported. Unknown types are mapped to string.
+func (g *generator) goType(schema, typ, elemSchema, elemType string) string {
+	if elemType != "" {
+		return "[]" + g.goType(elemSchema, elemType, "", "")
+	}
+	if name, ok := g.names[schema+"."+typ]; ok {
+		return name
+	}
+	if t, ok := builtinTypes[typ]; ok {
+		if i := strings.IndexByte(t, '.'); i >= 0 {
+			g.imports[builtinPackages[t[:i]]] = true
+		}
+		return t
+	}
+	return "string"
+}
+
+// commonInitialisms are written in upper case in Go names.
+var commonInitialisms = map[string]bool{
+	"ID": true, "URL": true, "URI": true, "UUID": true, "IP": true,
+	"HTTP": true, "JSON": true, "SQL": true, "API": true, "XML": true,
+}
+
+// goName converts a database identifier such as "street_address" into an
+// exported Go name such as "StreetAddress".
+func goName(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, w := range words {
+		if up := strings.ToUpper(w); commonInitialisms[up] {
+			b.WriteString(up)
+			continue
+		}
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	if b.Len() == 0 || unicode.IsDigit([]rune(b.String())[0]) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
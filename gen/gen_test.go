@@ -0,0 +1,145 @@
+package gen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerateImports(t *testing.T) {
+	tests := []struct {
+		name  string
+		types []Type
+		want  []string
+		no    []string
+	}{
+		{
+			name: "schema named like a package",
+			types: []Type{{
+				Schema: "realtime", Name: "event", Kind: Composite,
+				Attributes: []Attribute{{Name: "label", Type: "text", TypeSchema: "pg_catalog"}},
+			}},
+			want: []string{`"database/sql/driver"`, `"github.com/onrik/pg"`},
+			no:   []string{`"time"`, `"fmt"`},
+		},
+		{
+			name: "timestamp attribute",
+			types: []Type{{
+				Schema: "public", Name: "event", Kind: Composite,
+				Attributes: []Attribute{{Name: "at", Type: "timestamptz", TypeSchema: "pg_catalog"}},
+			}},
+			want: []string{`"github.com/onrik/pg"`, "At pg.Timestamp"},
+			no:   []string{`"time"`, `"fmt"`},
+		},
+		{
+			name:  "enum",
+			types: []Type{{Schema: "public", Name: "mood", Kind: Enum, Labels: []string{"ok"}}},
+			want:  []string{`"database/sql/driver"`, `"fmt"`},
+			no:    []string{`"time"`, `"github.com/onrik/pg"`},
+		},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Generate(&buf, tt.types, Options{Package: "models"}); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		src := buf.String()
+		for _, imp := range tt.want {
+			if !strings.Contains(src, imp) {
+				t.Errorf("%s: missing import %s in\n%s", tt.name, imp, src)
+			}
+		}
+		for _, imp := range tt.no {
+			if strings.Contains(src, imp) {
+				t.Errorf("%s: unexpected import %s in\n%s", tt.name, imp, src)
+			}
+		}
+	}
+}
+
+func TestGenerateTypes(t *testing.T) {
+	types := []Type{{
+		Schema: "public", Name: "payment", Kind: Composite,
+		Attributes: []Attribute{
+			{Name: "id", Type: "uuid", TypeSchema: "pg_catalog"},
+			{Name: "amount", Type: "numeric", TypeSchema: "pg_catalog"},
+			{Name: "due", Type: "date", TypeSchema: "pg_catalog"},
+			{Name: "created", Type: "timestamp", TypeSchema: "pg_catalog"},
+			{Name: "paid", Type: "_timestamptz", TypeSchema: "pg_catalog", ElemType: "timestamptz", ElemSchema: "pg_catalog"},
+			{Name: "note", Type: "unknown_type", TypeSchema: "public"},
+		},
+	}}
+	var buf bytes.Buffer
+	if err := Generate(&buf, types, Options{Package: "models"}); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	for _, want := range []string{
+		"ID      pg.UUID",
+		"Amount  pg.Numeric",
+		"Due     pg.Date",
+		"Created pg.Timestamp",
+		"Paid    []pg.Timestamp",
+		"Note    string",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("missing %q in\n%s", want, src)
+		}
+	}
+}
+
+func TestGenerateNameCollision(t *testing.T) {
+	types := []Type{
+		{Schema: "billing", Name: "address", Kind: Composite},
+		{Schema: "shipping", Name: "address", Kind: Composite},
+		{Schema: "public", Name: "customer", Kind: Composite, Attributes: []Attribute{
+			{Name: "address", Type: "address", TypeSchema: "shipping"},
+		}},
+	}
+	var buf bytes.Buffer
+	if err := Generate(&buf, types, Options{Package: "models"}); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	for _, want := range []string{
+		"type BillingAddress struct",
+		"type ShippingAddress struct",
+		"type Customer struct",
+		"Address ShippingAddress `pg:\"address\"`",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("missing %q in\n%s", want, src)
+		}
+	}
+
+	types = append(types, Type{Schema: "public", Name: "billing_address", Kind: Composite})
+	if err := Generate(&buf, types, Options{Package: "models"}); err == nil {
+		t.Error("no error for two types named BillingAddress")
+	}
+}
+
+func TestGenerateMemberCollision(t *testing.T) {
+	for _, typ := range []Type{
+		{Schema: "public", Name: "status", Kind: Enum, Labels: []string{"in-progress", "in_progress"}},
+		{Schema: "public", Name: "status", Kind: Enum, Labels: []string{"done", "Done"}},
+		{Schema: "public", Name: "event", Kind: Composite, Attributes: []Attribute{
+			{Name: "start_at", Type: "text", TypeSchema: "pg_catalog"},
+			{Name: "start-at", Type: "text", TypeSchema: "pg_catalog"},
+		}},
+	} {
+		var buf bytes.Buffer
+		if err := Generate(&buf, []Type{typ}, Options{Package: "models"}); err == nil {
+			t.Errorf("no error for %+v, generated\n%s", typ, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	typ := Type{Schema: "public", Name: "status", Kind: Enum, Labels: []string{"in-progress", "done"}}
+	if err := Generate(&buf, []Type{typ}, Options{Package: "models"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := `StatusInProgress Status = "in-progress"`; !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in\n%s", want, buf.String())
+	}
+}
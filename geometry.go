@@ -0,0 +1,744 @@
+package pg
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Point represents a PostgreSQL point value. Use a *Point to scan nullable
+// columns.
+//
+// The geometric types encode to JSON as objects, such as {"x":1,"y":2} for a
+// point or {"center":{"x":1,"y":2},"radius":3} for a circle. Coordinates
+// that are not finite, which encoding/json cannot encode as numbers, are
+// encoded as the strings "NaN", "Infinity" and "-Infinity".
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Scan implements the sql.Scanner interface.
+func (p *Point) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return p.scanBytes(src)
+	case string:
+		return p.scanBytes([]byte(src))
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Point", src)
+}
+
+func (p *Point) scanBytes(src []byte) error {
+	g := geomParser{src: src, typ: "Point"}
+	v, err := g.point()
+	if err == nil {
+		err = g.end()
+	}
+	if err != nil {
+		return err
+	}
+
+	*p = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (p Point) Value() (driver.Value, error) {
+	return string(appendPoint(nil, p)), nil
+}
+
+type pointJSON struct {
+	X jsonFloat `json:"x"`
+	Y jsonFloat `json:"y"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p Point) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pointJSON{X: jsonFloat(p.X), Y: jsonFloat(p.Y)})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var v pointJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*p = Point{X: float64(v.X), Y: float64(v.Y)}
+	return nil
+}
+
+// Line represents a PostgreSQL line value, the infinite line
+// Ax + By + C = 0, written as {A,B,C}. Use a *Line to scan nullable columns.
+type Line struct {
+	A float64
+	B float64
+	C float64
+}
+
+// Scan implements the sql.Scanner interface.
+func (l *Line) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return l.scanBytes(src)
+	case string:
+		return l.scanBytes([]byte(src))
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Line", src)
+}
+
+func (l *Line) scanBytes(src []byte) error {
+	g := geomParser{src: src, typ: "Line"}
+	var v Line
+	err := g.expect('{')
+	if err == nil {
+		v.A, err = g.float()
+	}
+	if err == nil {
+		err = g.expect(',')
+	}
+	if err == nil {
+		v.B, err = g.float()
+	}
+	if err == nil {
+		err = g.expect(',')
+	}
+	if err == nil {
+		v.C, err = g.float()
+	}
+	if err == nil {
+		err = g.expect('}')
+	}
+	if err == nil {
+		err = g.end()
+	}
+	if err != nil {
+		return err
+	}
+
+	*l = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (l Line) Value() (driver.Value, error) {
+	if l.A == 0 && l.B == 0 {
+		return nil, fmt.Errorf("pg: invalid Line: A and B cannot both be zero")
+	}
+
+	b := []byte{'{'}
+	b = appendFloat(b, l.A, 64)
+	b = append(b, ',')
+	b = appendFloat(b, l.B, 64)
+	b = append(b, ',')
+	b = appendFloat(b, l.C, 64)
+	return string(append(b, '}')), nil
+}
+
+type lineJSON struct {
+	A jsonFloat `json:"a"`
+	B jsonFloat `json:"b"`
+	C jsonFloat `json:"c"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (l Line) MarshalJSON() ([]byte, error) {
+	return json.Marshal(lineJSON{A: jsonFloat(l.A), B: jsonFloat(l.B), C: jsonFloat(l.C)})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (l *Line) UnmarshalJSON(data []byte) error {
+	var v lineJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*l = Line{A: float64(v.A), B: float64(v.B), C: float64(v.C)}
+	return nil
+}
+
+// Lseg represents a PostgreSQL lseg value, the line segment between two
+// points. Use a *Lseg to scan nullable columns.
+type Lseg struct {
+	P [2]Point `json:"points"`
+}
+
+// Scan implements the sql.Scanner interface.
+func (l *Lseg) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return l.scanBytes(src)
+	case string:
+		return l.scanBytes([]byte(src))
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Lseg", src)
+}
+
+func (l *Lseg) scanBytes(src []byte) error {
+	g := geomParser{src: src, typ: "Lseg"}
+	points, _, err := g.points(2)
+	if err == nil {
+		err = g.end()
+	}
+	if err != nil {
+		return err
+	}
+
+	l.P = [2]Point{points[0], points[1]}
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (l Lseg) Value() (driver.Value, error) {
+	return string(appendPoints(nil, '[', l.P[:])), nil
+}
+
+// Box represents a PostgreSQL box value. As on the server, the corners are
+// normalized so that High is the upper right and Low the lower left corner.
+// Use a *Box to scan nullable columns.
+type Box struct {
+	High Point `json:"high"`
+	Low  Point `json:"low"`
+}
+
+// Scan implements the sql.Scanner interface.
+func (b *Box) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return b.scanBytes(src)
+	case string:
+		return b.scanBytes([]byte(src))
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Box", src)
+}
+
+func (b *Box) scanBytes(src []byte) error {
+	g := geomParser{src: src, typ: "Box"}
+	points, _, err := g.points(2)
+	if err == nil {
+		err = g.end()
+	}
+	if err != nil {
+		return err
+	}
+
+	*b = NewBox(points[0], points[1])
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (b Box) Value() (driver.Value, error) {
+	n := NewBox(b.High, b.Low)
+	return string(appendPoints(nil, 0, []Point{n.High, n.Low})), nil
+}
+
+// ArrayDelimiter implements the ArrayDelimiter interface. Unlike other
+// types, arrays of boxes separate their elements with semicolons, as boxes
+// themselves contain commas.
+func (Box) ArrayDelimiter() string {
+	return ";"
+}
+
+// NewBox returns the box with opposite corners p and q.
+func NewBox(p, q Point) Box {
+	return Box{
+		High: Point{X: math.Max(p.X, q.X), Y: math.Max(p.Y, q.Y)},
+		Low:  Point{X: math.Min(p.X, q.X), Y: math.Min(p.Y, q.Y)},
+	}
+}
+
+// Path represents a PostgreSQL path value. An open path is written as
+// [(x1,y1),...] and a closed one as ((x1,y1),...). Use a *Path to scan
+// nullable columns.
+type Path struct {
+	Points []Point `json:"points"`
+	Closed bool    `json:"closed"`
+}
+
+// Scan implements the sql.Scanner interface.
+func (p *Path) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return p.scanBytes(src)
+	case string:
+		return p.scanBytes([]byte(src))
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Path", src)
+}
+
+func (p *Path) scanBytes(src []byte) error {
+	g := geomParser{src: src, typ: "Path"}
+	points, delim, err := g.points(-1)
+	if err == nil {
+		err = g.end()
+	}
+	if err != nil {
+		return err
+	}
+
+	// As on the server, a path without delimiters is closed.
+	*p = Path{Points: points, Closed: delim != '['}
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (p Path) Value() (driver.Value, error) {
+	if len(p.Points) == 0 {
+		return nil, fmt.Errorf("pg: invalid Path: no points")
+	}
+
+	delim := byte('[')
+	if p.Closed {
+		delim = '('
+	}
+	return string(appendPoints(nil, delim, p.Points)), nil
+}
+
+// Polygon represents a PostgreSQL polygon value. Use a *Polygon to scan
+// nullable columns.
+type Polygon struct {
+	Points []Point `json:"points"`
+}
+
+// Scan implements the sql.Scanner interface.
+func (p *Polygon) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return p.scanBytes(src)
+	case string:
+		return p.scanBytes([]byte(src))
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Polygon", src)
+}
+
+func (p *Polygon) scanBytes(src []byte) error {
+	g := geomParser{src: src, typ: "Polygon"}
+	if g.peek() == '[' {
+		return g.errorf("unexpected %q", '[')
+	}
+	points, _, err := g.points(-1)
+	if err == nil {
+		err = g.end()
+	}
+	if err != nil {
+		return err
+	}
+
+	p.Points = points
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (p Polygon) Value() (driver.Value, error) {
+	if len(p.Points) == 0 {
+		return nil, fmt.Errorf("pg: invalid Polygon: no points")
+	}
+
+	return string(appendPoints(nil, '(', p.Points)), nil
+}
+
+// Circle represents a PostgreSQL circle value, written as <(x,y),r>. Use a
+// *Circle to scan nullable columns.
+type Circle struct {
+	Center Point
+	Radius float64
+}
+
+// Scan implements the sql.Scanner interface.
+func (c *Circle) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return c.scanBytes(src)
+	case string:
+		return c.scanBytes([]byte(src))
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Circle", src)
+}
+
+func (c *Circle) scanBytes(src []byte) error {
+	g := geomParser{src: src, typ: "Circle"}
+	var closing byte
+	switch g.peek() {
+	case '<':
+		closing = '>'
+		g.pos++
+	case '(':
+		if next := skipSpace(g.src, g.pos+1); next < len(g.src) && g.src[next] == '(' {
+			closing = ')'
+			g.pos = next
+		}
+	}
+
+	var v Circle
+	var err error
+	if v.Center, err = g.point(); err != nil {
+		return err
+	}
+	if err = g.expect(','); err != nil {
+		return err
+	}
+	if v.Radius, err = g.float(); err != nil {
+		return err
+	}
+	if v.Radius < 0 {
+		return g.errorf("negative radius")
+	}
+	if closing != 0 {
+		if err = g.expect(closing); err != nil {
+			return err
+		}
+	}
+	if err = g.end(); err != nil {
+		return err
+	}
+
+	*c = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (c Circle) Value() (driver.Value, error) {
+	if c.Radius < 0 {
+		return nil, fmt.Errorf("pg: invalid Circle: negative radius")
+	}
+
+	b := []byte{'<'}
+	b = appendPoint(b, c.Center)
+	b = append(b, ',')
+	b = appendFloat(b, c.Radius, 64)
+	return string(append(b, '>')), nil
+}
+
+type circleJSON struct {
+	Center Point     `json:"center"`
+	Radius jsonFloat `json:"radius"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c Circle) MarshalJSON() ([]byte, error) {
+	return json.Marshal(circleJSON{Center: c.Center, Radius: jsonFloat(c.Radius)})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *Circle) UnmarshalJSON(data []byte) error {
+	var v circleJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*c = Circle{Center: v.Center, Radius: float64(v.Radius)}
+	return nil
+}
+
+// jsonFloat is a float8 coordinate in JSON, which is a number if it is
+// finite and a string in the server's text format otherwise.
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.AppendQuote(nil, string(appendFloat(nil, v, 64))), nil
+	}
+	return json.Marshal(v)
+}
+
+func (f *jsonFloat) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 || data[0] != '"' {
+		var v float64
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*f = jsonFloat(v)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "NaN":
+		*f = jsonFloat(math.NaN())
+	case "Infinity":
+		*f = jsonFloat(math.Inf(1))
+	case "-Infinity":
+		*f = jsonFloat(math.Inf(-1))
+	default:
+		return fmt.Errorf("pg: cannot unmarshal %q into a coordinate", s)
+	}
+	return nil
+}
+
+// Distance returns the distance between p and q, like the <-> operator.
+func (p Point) Distance(q Point) float64 {
+	return math.Hypot(p.X-q.X, p.Y-q.Y)
+}
+
+// Distance returns the distance between p and l, like the <-> operator.
+func (l Line) Distance(p Point) float64 {
+	return math.Abs(l.A*p.X+l.B*p.Y+l.C) / math.Hypot(l.A, l.B)
+}
+
+// Length returns the length of l, like the @-@ operator.
+func (l Lseg) Length() float64 {
+	return l.P[0].Distance(l.P[1])
+}
+
+// Distance returns the distance between p and the closest point of l, like
+// the <-> operator.
+func (l Lseg) Distance(p Point) float64 {
+	return segmentDistance(l.P[0], l.P[1], p)
+}
+
+// Contains reports whether p is inside or on the border of b, like the @>
+// operator.
+func (b Box) Contains(p Point) bool {
+	n := NewBox(b.High, b.Low)
+	return p.X >= n.Low.X && p.X <= n.High.X && p.Y >= n.Low.Y && p.Y <= n.High.Y
+}
+
+// Area returns the area of b, like the area function.
+func (b Box) Area() float64 {
+	return math.Abs(b.High.X-b.Low.X) * math.Abs(b.High.Y-b.Low.Y)
+}
+
+// Center returns the center of b, like the @@ operator.
+func (b Box) Center() Point {
+	return Point{X: (b.High.X + b.Low.X) / 2, Y: (b.High.Y + b.Low.Y) / 2}
+}
+
+// Length returns the length of p, like the @-@ operator. The length of a
+// closed path includes the segment from the last point back to the first.
+func (p Path) Length() float64 {
+	var l float64
+	for i := 1; i < len(p.Points); i++ {
+		l += p.Points[i-1].Distance(p.Points[i])
+	}
+	if p.Closed && len(p.Points) > 1 {
+		l += p.Points[len(p.Points)-1].Distance(p.Points[0])
+	}
+	return l
+}
+
+// Contains reports whether p is inside or on the border of the polygon, like
+// the @> operator.
+func (g Polygon) Contains(p Point) bool {
+	n := len(g.Points)
+	if n == 0 {
+		return false
+	}
+
+	inside := false
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := g.Points[j], g.Points[i]
+		if segmentDistance(a, b, p) == 0 {
+			return true
+		}
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Area returns the area of the polygon, like the area function.
+func (g Polygon) Area() float64 {
+	var a float64
+	for i, j := 0, len(g.Points)-1; i < len(g.Points); j, i = i, i+1 {
+		a += g.Points[j].X*g.Points[i].Y - g.Points[i].X*g.Points[j].Y
+	}
+	return math.Abs(a) / 2
+}
+
+// Contains reports whether p is inside or on the border of c, like the @>
+// operator.
+func (c Circle) Contains(p Point) bool {
+	return c.Center.Distance(p) <= c.Radius
+}
+
+// Area returns the area of c, like the area function.
+func (c Circle) Area() float64 {
+	return math.Pi * c.Radius * c.Radius
+}
+
+// Distance returns the distance between p and the border of c, or 0 if p is
+// inside c, like the <-> operator.
+func (c Circle) Distance(p Point) float64 {
+	return math.Max(c.Center.Distance(p)-c.Radius, 0)
+}
+
+// segmentDistance returns the distance between p and the segment from a to
+// b.
+func segmentDistance(a, b, p Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	if dx == 0 && dy == 0 {
+		return a.Distance(p)
+	}
+	t := ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return p.Distance(Point{X: a.X + t*dx, Y: a.Y + t*dy})
+}
+
+// geomParser parses the text representation of geometric types.
+type geomParser struct {
+	src []byte
+	pos int
+	typ string
+}
+
+func (g *geomParser) skipSpace() {
+	g.pos = skipSpace(g.src, g.pos)
+}
+
+// peek returns the next non-space byte, or 0 at the end of input.
+func (g *geomParser) peek() byte {
+	g.skipSpace()
+	if g.pos < len(g.src) {
+		return g.src[g.pos]
+	}
+	return 0
+}
+
+func (g *geomParser) expect(c byte) error {
+	if g.peek() != c {
+		return g.errorf("expected %q", c)
+	}
+	g.pos++
+	return nil
+}
+
+// end checks that only whitespace is left.
+func (g *geomParser) end() error {
+	if g.peek() != 0 {
+		return g.errorf("unexpected %q", g.src[g.pos])
+	}
+	return nil
+}
+
+func (g *geomParser) errorf(format string, args ...interface{}) error {
+	return parseErrorf(g.typ, g.src, g.pos, format, args...)
+}
+
+// float parses a float8 value, including NaN and Infinity.
+func (g *geomParser) float() (float64, error) {
+	g.skipSpace()
+	start := g.pos
+	for g.pos < len(g.src) && bytes.IndexByte([]byte(" \t\n\r\v\f,()[]{}<>"), g.src[g.pos]) < 0 {
+		g.pos++
+	}
+	if start == g.pos {
+		return 0, g.errorf("expected number")
+	}
+	tok := g.src[start:g.pos]
+	f, err := strconv.ParseFloat(string(tok), 64)
+	if err != nil {
+		g.pos = start
+		return 0, g.errorf("invalid number %q", tok)
+	}
+	return f, nil
+}
+
+// point parses a point written as (x,y) or x,y.
+func (g *geomParser) point() (Point, error) {
+	var p Point
+	paren := g.peek() == '('
+	if paren {
+		g.pos++
+	}
+	var err error
+	if p.X, err = g.float(); err != nil {
+		return p, err
+	}
+	if err = g.expect(','); err != nil {
+		return p, err
+	}
+	if p.Y, err = g.float(); err != nil {
+		return p, err
+	}
+	if paren {
+		err = g.expect(')')
+	}
+	return p, err
+}
+
+// points parses a list of points, optionally enclosed in [] or (). If n is
+// positive exactly n points are expected. The opening delimiter is returned,
+// or 0 if there was none.
+func (g *geomParser) points(n int) ([]Point, byte, error) {
+	var delim byte
+	switch g.peek() {
+	case '[':
+		delim = '['
+		g.pos++
+	case '(':
+		// A second parenthesis means the first one encloses the list.
+		if next := skipSpace(g.src, g.pos+1); next < len(g.src) && g.src[next] == '(' {
+			delim = '('
+			g.pos = next
+		}
+	}
+
+	var points []Point
+	for {
+		p, err := g.point()
+		if err != nil {
+			return nil, delim, err
+		}
+		points = append(points, p)
+		if len(points) == n || g.peek() != ',' {
+			break
+		}
+		g.pos++
+	}
+	if n > 0 && len(points) != n {
+		return nil, delim, g.errorf("expected %d points", n)
+	}
+
+	switch delim {
+	case '[':
+		return points, delim, g.expect(']')
+	case '(':
+		return points, delim, g.expect(')')
+	}
+	return points, delim, nil
+}
+
+// appendPoints appends points separated by commas and enclosed in delim and
+// its closing counterpart if delim is not 0.
+func appendPoints(b []byte, delim byte, points []Point) []byte {
+	if delim != 0 {
+		b = append(b, delim)
+	}
+	for i, p := range points {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendPoint(b, p)
+	}
+	switch delim {
+	case '[':
+		b = append(b, ']')
+	case '(':
+		b = append(b, ')')
+	}
+	return b
+}
+
+func appendPoint(b []byte, p Point) []byte {
+	b = append(b, '(')
+	b = appendFloat(b, p.X, 64)
+	b = append(b, ',')
+	b = appendFloat(b, p.Y, 64)
+	return append(b, ')')
+}
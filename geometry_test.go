@@ -0,0 +1,121 @@
+package pg
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestGeometryScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Point), `(1,2)`, ""},
+		{new(Point), `(-1.5,2e-05)`, ""},
+		{new(Point), `(Infinity,-Infinity)`, ""},
+		{new(Point), `(NaN,0)`, ""},
+		{new(Line), `{1,-1,0}`, ""},
+		{new(Line), `{0,1,-2.5}`, ""},
+		{new(Lseg), `[(0,0),(1,1)]`, ""},
+		{new(Box), `(2,2),(0,0)`, ""},
+		{new(Box), `(0,0),(2,2)`, `(2,2),(0,0)`},
+		{new(Path), `((0,0),(1,1),(2,0))`, ""},
+		{new(Path), `[(0,0),(1,1)]`, ""},
+		{new(Polygon), `((0,0),(1,1),(2,0))`, ""},
+		{new(Circle), `<(1,2),3>`, ""},
+	})
+}
+
+func TestGeometryScanInvalid(t *testing.T) {
+	testScanInvalid(t, func() sql.Scanner { return new(Point) }, ``, `(1)`, `(1,2`, `(a,2)`, `(1,2)x`)
+	testScanInvalid(t, func() sql.Scanner { return new(Line) }, `{1,2}`, `(1,2,3)`, `{1,2,3`)
+	testScanInvalid(t, func() sql.Scanner { return new(Lseg) }, `[(0,0)]`, `[(0,0),(1,1),(2,2)]`, `[(0,0),(1,1)`)
+	testScanInvalid(t, func() sql.Scanner { return new(Box) }, `(0,0)`, `(0,0),(1,1),(2,2)`)
+	testScanInvalid(t, func() sql.Scanner { return new(Path) }, `((0,0)`, `{(0,0)}`, `[(0,0),(1,1))`)
+	testScanInvalid(t, func() sql.Scanner { return new(Polygon) }, `((0,0)`, `[(0,0),(1,1)]`)
+	testScanInvalid(t, func() sql.Scanner { return new(Circle) }, `<(1,2)>`, `<(1,2),3`)
+}
+
+// The geometric types cannot represent NULL, so nullable columns must be
+// scanned into pointers.
+func TestGeometryScanNull(t *testing.T) {
+	for _, s := range []sql.Scanner{
+		new(Point),
+		new(Line),
+		new(Lseg),
+		new(Box),
+		new(Path),
+		new(Polygon),
+		new(Circle),
+	} {
+		if err := s.Scan(nil); err == nil {
+			t.Errorf("%T: Scan(nil) = %+v, want error", s, s)
+		}
+	}
+}
+
+func TestGeometryJSON(t *testing.T) {
+	inf := math.Inf(1)
+	tests := []struct {
+		v    interface{}
+		json string
+	}{
+		{Point{X: 1, Y: -2.5}, `{"x":1,"y":-2.5}`},
+		{Point{X: inf, Y: -inf}, `{"x":"Infinity","y":"-Infinity"}`},
+		{Line{A: 1, B: -1, C: inf}, `{"a":1,"b":-1,"c":"Infinity"}`},
+		{Lseg{P: [2]Point{{X: 0, Y: 0}, {X: 1, Y: inf}}}, `{"points":[{"x":0,"y":0},{"x":1,"y":"Infinity"}]}`},
+		{Box{High: Point{X: 2, Y: 2}, Low: Point{X: -inf, Y: 0}}, `{"high":{"x":2,"y":2},"low":{"x":"-Infinity","y":0}}`},
+		{Path{Points: []Point{{X: 1, Y: 2}}, Closed: true}, `{"points":[{"x":1,"y":2}],"closed":true}`},
+		{Polygon{Points: []Point{{X: 0, Y: 0}, {X: 1, Y: 1}}}, `{"points":[{"x":0,"y":0},{"x":1,"y":1}]}`},
+		{Circle{Center: Point{X: 1, Y: 2}, Radius: inf}, `{"center":{"x":1,"y":2},"radius":"Infinity"}`},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.v)
+		if err != nil {
+			t.Errorf("Marshal(%+v): %v", tt.v, err)
+			continue
+		}
+		if string(b) != tt.json {
+			t.Errorf("Marshal(%+v) = %s, want %s", tt.v, b, tt.json)
+		}
+
+		p := reflect.New(reflect.TypeOf(tt.v))
+		if err := json.Unmarshal(b, p.Interface()); err != nil {
+			t.Errorf("Unmarshal(%s): %v", b, err)
+			continue
+		}
+		if got := p.Elem().Interface(); !reflect.DeepEqual(got, tt.v) {
+			t.Errorf("Unmarshal(%s) = %+v, want %+v", b, got, tt.v)
+		}
+	}
+}
+
+func TestGeometryJSONNaN(t *testing.T) {
+	b, err := json.Marshal(Point{X: math.NaN(), Y: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"x":"NaN","y":1}`; string(b) != want {
+		t.Fatalf("Marshal = %s, want %s", b, want)
+	}
+	var p Point
+	if err := json.Unmarshal(b, &p); err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(p.X) || p.Y != 1 {
+		t.Fatalf("Unmarshal(%s) = %+v", b, p)
+	}
+}
+
+func TestGeometryJSONInvalid(t *testing.T) {
+	for _, src := range []string{
+		`{"x":"1","y":2}`,
+		`{"x":"inf","y":2}`,
+		`{"x":true,"y":2}`,
+	} {
+		var p Point
+		if err := json.Unmarshal([]byte(src), &p); err == nil {
+			t.Errorf("Unmarshal(%s) = %+v, want error", src, p)
+		}
+	}
+}
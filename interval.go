@@ -0,0 +1,482 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interval represents a PostgreSQL interval value. Like the server it keeps
+// months, days and microseconds separately, since the length of a month or
+// day depends on the date the interval is added to. Use a *Interval to scan
+// nullable columns.
+type Interval struct {
+	Months       int32
+	Days         int32
+	Microseconds int64
+}
+
+// InfiniteInterval and NegInfiniteInterval are the special values infinity and
+// -infinity added in PostgreSQL 17, which the server stores with every field
+// at its maximum or minimum.
+var (
+	InfiniteInterval    = Interval{Months: math.MaxInt32, Days: math.MaxInt32, Microseconds: math.MaxInt64}
+	NegInfiniteInterval = Interval{Months: math.MinInt32, Days: math.MinInt32, Microseconds: math.MinInt64}
+)
+
+const (
+	usPerSecond = 1000000
+	usPerMinute = 60 * usPerSecond
+	usPerHour   = 60 * usPerMinute
+)
+
+// Scan implements the sql.Scanner interface. The output of all the
+// IntervalStyle settings, postgres, postgres_verbose, sql_standard and
+// iso_8601, is accepted, as are infinity and -infinity.
+func (iv *Interval) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return iv.scanString(string(src))
+	case string:
+		return iv.scanString(src)
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Interval", src)
+}
+
+func (iv *Interval) scanString(src string) error {
+	s := strings.TrimSpace(src)
+	var v Interval
+	var err error
+	switch inf := infinitySign([]byte(s)); {
+	case inf > 0:
+		v = InfiniteInterval
+	case inf < 0:
+		v = NegInfiniteInterval
+	case strings.HasPrefix(s, "P"):
+		v, err = parseISOInterval(s)
+	case isSQLInterval(s):
+		v, err = parseSQLInterval(s)
+	default:
+		v, err = parsePostgresInterval(s)
+	}
+	if err != nil {
+		return parseError("Interval", src, -1, err)
+	}
+
+	*iv = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface. The interval is sent in ISO
+// 8601 format, which the server accepts whatever its IntervalStyle is.
+func (iv Interval) Value() (driver.Value, error) {
+	return iv.String(), nil
+}
+
+// AppendValue appends the text of iv to dst, like Value but without
+// allocating a string.
+func (iv Interval) AppendValue(dst []byte) ([]byte, error) {
+	return iv.appendText(dst), nil
+}
+
+// String returns iv in ISO 8601 format the way the server does with the
+// iso_8601 IntervalStyle, such as "P1Y2M3DT4H5M6.5S", or as infinity or
+// -infinity.
+func (iv Interval) String() string {
+	return string(iv.appendText(nil))
+}
+
+func (iv Interval) appendText(b []byte) []byte {
+	switch iv {
+	case Interval{}:
+		return append(b, "PT0S"...)
+	case InfiniteInterval:
+		return append(b, "infinity"...)
+	case NegInfiniteInterval:
+		return append(b, "-infinity"...)
+	}
+
+	b = append(b, 'P')
+	b = appendIntervalField(b, int64(iv.Months/12), 'Y')
+	b = appendIntervalField(b, int64(iv.Months%12), 'M')
+	b = appendIntervalField(b, int64(iv.Days), 'D')
+	if us := iv.Microseconds; us != 0 {
+		b = append(b, 'T')
+		b = appendIntervalField(b, us/usPerHour, 'H')
+		b = appendIntervalField(b, us%usPerHour/usPerMinute, 'M')
+		if us%usPerMinute != 0 {
+			b = appendSeconds(b, us%usPerMinute)
+			b = append(b, 'S')
+		}
+	}
+	return b
+}
+
+// FromDuration returns an interval of the length of d, truncated to whole
+// microseconds. Like the server does for intervals given in hours, minutes
+// or seconds, the whole duration goes into Microseconds.
+func FromDuration(d time.Duration) Interval {
+	return Interval{Microseconds: int64(d / time.Microsecond)}
+}
+
+// Duration returns iv as a time.Duration, counting a day as 24 hours. It
+// returns an error if iv has months, whose length varies, or if iv does not
+// fit into a time.Duration.
+func (iv Interval) Duration() (time.Duration, error) {
+	if iv.Months != 0 {
+		return 0, fmt.Errorf("pg: cannot convert Interval with months to time.Duration")
+	}
+	return iv.ApproxDuration(0)
+}
+
+// ApproxDuration is like Duration but counts a month as monthDays days,
+// instead of returning an error for intervals with months. The server uses
+// 30 days per month for justify_days and extract(epoch from ...).
+func (iv Interval) ApproxDuration(monthDays int32) (time.Duration, error) {
+	days := int64(iv.Months)*int64(monthDays) + int64(iv.Days)
+	const maxUs = math.MaxInt64 / int64(time.Microsecond)
+	us := iv.Microseconds
+	if us > maxUs || us < -maxUs ||
+		days > (maxUs-us)/(24*usPerHour) || days < (-maxUs-us)/(24*usPerHour) {
+		return 0, fmt.Errorf("pg: Interval %s out of range for time.Duration", iv)
+	}
+	us += days * 24 * usPerHour
+	return time.Duration(us) * time.Microsecond, nil
+}
+
+func appendIntervalField(b []byte, v int64, unit byte) []byte {
+	if v == 0 {
+		return b
+	}
+	return append(strconv.AppendInt(b, v, 10), unit)
+}
+
+// appendSeconds appends us microseconds as seconds with a fractional part
+// if needed.
+func appendSeconds(b []byte, us int64) []byte {
+	if us < 0 {
+		b = append(b, '-')
+		us = -us
+	}
+	b = strconv.AppendInt(b, us/usPerSecond, 10)
+	if frac := us % usPerSecond; frac != 0 {
+		f := strconv.AppendInt(nil, frac+usPerSecond, 10)[1:]
+		b = append(b, '.')
+		b = append(b, strings.TrimRight(string(f), "0")...)
+	}
+	return b
+}
+
+// intervalBuilder accumulates the fields of an interval, checking for
+// overflow.
+type intervalBuilder struct {
+	months, days, us int64
+	err              error
+}
+
+// add adds n+frac of unit to the interval. Fractions of a unit carry over
+// into the next smaller field the way the server does it.
+func (ib *intervalBuilder) add(n int64, frac float64, unit string) error {
+	switch unit {
+	case "year", "years", "Y":
+		ib.addField(&ib.months, n, frac, 12)
+	case "mon", "mons", "month", "months":
+		ib.addField(&ib.months, n, 0, 1)
+		ib.addField(&ib.days, 0, frac, 30)
+	case "W":
+		ib.addField(&ib.days, n, 0, 7)
+		ib.addField(&ib.us, 0, frac, 7*24*usPerHour)
+	case "day", "days", "D":
+		ib.addField(&ib.days, n, 0, 1)
+		ib.addField(&ib.us, 0, frac, 24*usPerHour)
+	case "hour", "hours", "H":
+		ib.addField(&ib.us, n, frac, usPerHour)
+	case "min", "mins", "minute", "minutes":
+		ib.addField(&ib.us, n, frac, usPerMinute)
+	case "sec", "secs", "second", "seconds", "S":
+		ib.addField(&ib.us, n, frac, usPerSecond)
+	default:
+		return fmt.Errorf("unknown unit %q", unit)
+	}
+	return ib.err
+}
+
+// addField adds (n+frac)*per to *f.
+func (ib *intervalBuilder) addField(f *int64, n int64, frac float64, per int64) {
+	if ib.err != nil {
+		return
+	}
+	if n > math.MaxInt64/per || n < math.MinInt64/per {
+		ib.err = fmt.Errorf("interval out of range")
+		return
+	}
+	v := n*per + int64(math.Round(frac*float64(per)))
+	if (frac > 0 && v < n*per) || (frac < 0 && v > n*per) {
+		ib.err = fmt.Errorf("interval out of range")
+		return
+	}
+	sum := *f + v
+	if (v > 0 && sum < *f) || (v < 0 && sum > *f) || (f != &ib.us && sum != int64(int32(sum))) {
+		ib.err = fmt.Errorf("interval out of range")
+		return
+	}
+	*f = sum
+}
+
+func (ib *intervalBuilder) interval() Interval {
+	return Interval{Months: int32(ib.months), Days: int32(ib.days), Microseconds: ib.us}
+}
+
+// parseIntervalNumber parses a signed decimal number starting at offset i,
+// returning its integer part and fraction separately.
+func parseIntervalNumber(s string, i int) (n int64, frac float64, end int, err error) {
+	start := i
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	digits := i
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == digits {
+		return 0, 0, i, fmt.Errorf("expected digit at offset %d", i)
+	}
+	if n, err = strconv.ParseInt(s[start:i], 10, 64); err != nil {
+		return 0, 0, i, fmt.Errorf("interval out of range")
+	}
+	if i < len(s) && s[i] == '.' {
+		fracStart := i
+		for i++; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		}
+		if frac, err = strconv.ParseFloat("0"+s[fracStart:i], 64); err != nil {
+			return 0, 0, i, err
+		}
+		if s[start] == '-' {
+			frac = -frac
+		}
+	}
+	return n, frac, i, nil
+}
+
+// parsePostgresInterval parses the output of the postgres IntervalStyle,
+// such as "1 year 2 mons -3 days +04:05:06.5", and of postgres_verbose, such
+// as "@ 1 year 2 mons 3 days 4 hours 5 mins 6.5 secs ago".
+func parsePostgresInterval(s string) (Interval, error) {
+	var ib intervalBuilder
+	verbose := strings.HasPrefix(s, "@")
+	if verbose {
+		s = strings.TrimSpace(s[1:])
+	}
+	ago := false
+	if verbose && strings.HasSuffix(s, " ago") {
+		ago = true
+		s = strings.TrimSpace(s[:len(s)-4])
+	}
+	if s == "" {
+		return Interval{}, fmt.Errorf("unexpected end of input")
+	}
+	if verbose && s == "0" {
+		// A zero interval has no fields to print.
+		return Interval{}, nil
+	}
+
+	for i := 0; i < len(s); {
+		n, frac, j, err := parseIntervalNumber(s, i)
+		if err != nil {
+			return Interval{}, err
+		}
+		if j < len(s) && s[j] == ':' {
+			end, err := parseIntervalTime(&ib, s, i)
+			if err != nil {
+				return Interval{}, err
+			}
+			i = end
+		} else {
+			for j < len(s) && s[j] == ' ' {
+				j++
+			}
+			end := j
+			for end < len(s) && s[end] >= 'a' && s[end] <= 'z' {
+				end++
+			}
+			if err := ib.add(n, frac, s[j:end]); err != nil {
+				return Interval{}, err
+			}
+			i = end
+		}
+		if i < len(s) && s[i] != ' ' {
+			return Interval{}, fmt.Errorf("unexpected %q at offset %d", s[i], i)
+		}
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+	}
+
+	v := ib.interval()
+	if ago {
+		v = Interval{Months: -v.Months, Days: -v.Days, Microseconds: -v.Microseconds}
+	}
+	return v, nil
+}
+
+// isSQLInterval reports whether s is in the format of the sql_standard
+// IntervalStyle, having no units. A lone time such as "04:05:06" is the same
+// in the postgres IntervalStyle.
+func isSQLInterval(s string) bool {
+	if s == "" || s[0] == '@' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 'a' && s[i] <= 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSQLInterval parses the output of the sql_standard IntervalStyle, such
+// as "1-2" for years and months, "-3 4:05:06" for days and a time, or
+// "+1-2 -3 +4:05:06" for both or for fields of mixed signs. A leading minus
+// applies to every field unless the other fields have signs of their own.
+func parseSQLInterval(s string) (Interval, error) {
+	var ib intervalBuilder
+	fields := strings.Fields(s)
+	neg := false
+	if s[0] == '-' {
+		neg = true
+		for _, f := range fields[1:] {
+			if f[0] == '+' || f[0] == '-' {
+				neg = false
+			}
+		}
+		if neg {
+			fields[0] = fields[0][1:]
+		}
+	}
+
+	for k, f := range fields {
+		switch {
+		case f == "":
+			return Interval{}, fmt.Errorf("unexpected %q", s[0])
+		case strings.IndexByte(f, ':') >= 0:
+			end, err := parseIntervalTime(&ib, f, 0)
+			if err != nil {
+				return Interval{}, err
+			}
+			if end != len(f) {
+				return Interval{}, fmt.Errorf("unexpected %q in %q", f[end], f)
+			}
+		case k == 0 && strings.IndexByte(f[1:], '-') >= 0:
+			// The sign of the years is that of the months too.
+			sign := int64(1)
+			if f[0] == '+' || f[0] == '-' {
+				if f[0] == '-' {
+					sign = -1
+				}
+				f = f[1:]
+			}
+			i := strings.IndexByte(f, '-')
+			years, err := strconv.ParseUint(f[:i], 10, 31)
+			if err != nil {
+				return Interval{}, fmt.Errorf("invalid years %q", f[:i])
+			}
+			months, err := strconv.ParseUint(f[i+1:], 10, 31)
+			if err != nil {
+				return Interval{}, fmt.Errorf("invalid months %q", f[i+1:])
+			}
+			ib.addField(&ib.months, sign*int64(years), 0, 12)
+			ib.addField(&ib.months, sign*int64(months), 0, 1)
+		default:
+			days, err := strconv.ParseInt(f, 10, 32)
+			if err != nil {
+				return Interval{}, fmt.Errorf("invalid days %q", f)
+			}
+			ib.addField(&ib.days, days, 0, 1)
+		}
+		if ib.err != nil {
+			return Interval{}, ib.err
+		}
+	}
+
+	v := ib.interval()
+	if neg {
+		v = Interval{Months: -v.Months, Days: -v.Days, Microseconds: -v.Microseconds}
+	}
+	return v, nil
+}
+
+// parseIntervalTime parses [+-]H:MM[:SS[.ffffff]] starting at offset i and
+// adds it to ib.
+func parseIntervalTime(ib *intervalBuilder, s string, i int) (end int, err error) {
+	neg := s[i] == '-'
+	hour, _, i, err := parseIntervalNumber(s, i)
+	if err != nil {
+		return i, err
+	}
+	var min, sec int64
+	var frac float64
+	if min, _, i, err = parseIntervalNumber(s, i+1); err != nil {
+		return i, err
+	}
+	if i < len(s) && s[i] == ':' {
+		if sec, frac, i, err = parseIntervalNumber(s, i+1); err != nil {
+			return i, err
+		}
+	}
+	if min < 0 || min > 59 || sec < 0 || sec > 59 || frac < 0 {
+		return i, fmt.Errorf("time field value out of range")
+	}
+	if neg {
+		min, sec, frac = -min, -sec, -frac
+	}
+
+	ib.addField(&ib.us, hour, 0, usPerHour)
+	ib.addField(&ib.us, min, 0, usPerMinute)
+	ib.addField(&ib.us, sec, frac, usPerSecond)
+	return i, ib.err
+}
+
+// parseISOInterval parses an interval in ISO 8601 format with designators,
+// such as "P1Y2M3DT4H5M6.5S" or "P-1Y-2M3DT-4H".
+func parseISOInterval(s string) (Interval, error) {
+	var ib intervalBuilder
+	if len(s) < 2 {
+		return Interval{}, fmt.Errorf("unexpected end of input")
+	}
+
+	inTime := false
+	for i := 1; i < len(s); {
+		if s[i] == 'T' && !inTime {
+			inTime = true
+			i++
+			continue
+		}
+		n, frac, j, err := parseIntervalNumber(s, i)
+		if err != nil {
+			return Interval{}, err
+		}
+		if j >= len(s) {
+			return Interval{}, fmt.Errorf("missing unit at offset %d", j)
+		}
+		unit := string(s[j])
+		switch {
+		case inTime && unit == "M":
+			unit = "min"
+		case !inTime && unit == "M":
+			unit = "mon"
+		case inTime && (unit == "Y" || unit == "W" || unit == "D"),
+			!inTime && (unit == "H" || unit == "S"):
+			return Interval{}, fmt.Errorf("unexpected %q at offset %d", s[j], j)
+		}
+		if err := ib.add(n, frac, unit); err != nil {
+			return Interval{}, err
+		}
+		i = j + 1
+	}
+
+	return ib.interval(), nil
+}
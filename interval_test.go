@@ -0,0 +1,206 @@
+package pg
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// The output of each IntervalStyle for the same values, as printed by the
+// server.
+var intervalStyleTests = []struct {
+	name     string
+	want     Interval
+	postgres string
+	verbose  string
+	sql      string
+	iso      string
+}{
+	{
+		name:     "zero",
+		want:     Interval{},
+		postgres: "00:00:00",
+		verbose:  "@ 0",
+		sql:      "0",
+		iso:      "PT0S",
+	},
+	{
+		name:     "negative",
+		want:     Interval{Months: -14, Days: -3, Microseconds: -14706500000},
+		postgres: "-1 years -2 mons -3 days -04:05:06.5",
+		verbose:  "@ 1 year 2 mons 3 days 4 hours 5 mins 6.5 secs ago",
+		sql:      "-1-2 -3 -4:05:06.5",
+		iso:      "P-1Y-2M-3DT-4H-5M-6.5S",
+	},
+	{
+		name:     "mixed signs",
+		want:     Interval{Months: 14, Days: -3, Microseconds: 14706000000},
+		postgres: "1 year 2 mons -3 days +04:05:06",
+		verbose:  "@ 1 year 2 mons -3 days 4 hours 5 mins 6 secs",
+		sql:      "+1-2 -3 +4:05:06",
+		iso:      "P1Y2M-3DT4H5M6S",
+	},
+	{
+		name:     "negative years and months",
+		want:     Interval{Months: -14},
+		postgres: "-1 years -2 mons",
+		verbose:  "@ 1 year 2 mons ago",
+		sql:      "-1-2",
+		iso:      "P-1Y-2M",
+	},
+	{
+		name:     "negative days and time",
+		want:     Interval{Days: -3, Microseconds: -14706000000},
+		postgres: "-3 days -04:05:06",
+		verbose:  "@ 3 days 4 hours 5 mins 6 secs ago",
+		sql:      "-3 4:05:06",
+		iso:      "P-3DT-4H-5M-6S",
+	},
+	{
+		name:     "negative time",
+		want:     Interval{Microseconds: -3723000000},
+		postgres: "-01:02:03",
+		verbose:  "@ 1 hour 2 mins 3 secs ago",
+		sql:      "-1:02:03",
+		iso:      "PT-1H-2M-3S",
+	},
+	{
+		name:     "later fields negated",
+		want:     Interval{Months: -12, Days: 3},
+		postgres: "-1 years +3 days",
+		verbose:  "@ 1 year -3 days ago",
+		sql:      "-1-0 +3 +0:00:00",
+		iso:      "P-1Y3D",
+	},
+}
+
+func TestIntervalScanStyles(t *testing.T) {
+	for _, tt := range intervalStyleTests {
+		for _, src := range []string{tt.postgres, tt.verbose, tt.sql, tt.iso} {
+			var iv Interval
+			if err := iv.Scan([]byte(src)); err != nil {
+				t.Errorf("%s: Scan(%q): %v", tt.name, src, err)
+				continue
+			}
+			if iv != tt.want {
+				t.Errorf("%s: Scan(%q) = %+v, want %+v", tt.name, src, iv, tt.want)
+			}
+		}
+	}
+}
+
+func TestIntervalString(t *testing.T) {
+	for _, tt := range intervalStyleTests {
+		if got := tt.want.String(); got != tt.iso {
+			t.Errorf("%s: String() = %q, want %q", tt.name, got, tt.iso)
+		}
+	}
+}
+
+func TestIntervalScanInvalid(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"@",
+		"1 fortnight",
+		"1-",
+		"- 1",
+		"1:60:00",
+		"P1",
+		"PT1Y",
+	} {
+		var iv Interval
+		if err := iv.Scan(src); err == nil {
+			t.Errorf("Scan(%q) = %+v, want error", src, iv)
+		}
+	}
+}
+
+func TestIntervalInfinity(t *testing.T) {
+	for _, tt := range []struct {
+		src  string
+		want Interval
+		text string
+	}{
+		{"infinity", InfiniteInterval, "infinity"},
+		{" Infinity ", InfiniteInterval, "infinity"},
+		{"+infinity", InfiniteInterval, "infinity"},
+		{"-infinity", NegInfiniteInterval, "-infinity"},
+	} {
+		var iv Interval
+		if err := iv.Scan([]byte(tt.src)); err != nil {
+			t.Errorf("Scan(%q): %v", tt.src, err)
+			continue
+		}
+		if iv != tt.want {
+			t.Errorf("Scan(%q) = %+v, want %+v", tt.src, iv, tt.want)
+		}
+		if v, err := iv.Value(); err != nil || v != tt.text {
+			t.Errorf("Value() of %q = %v, %v, want %s", tt.src, v, err, tt.text)
+		}
+	}
+
+	if _, err := InfiniteInterval.ApproxDuration(30); err == nil {
+		t.Error("expected error converting infinity to time.Duration")
+	}
+}
+
+func TestIntervalDuration(t *testing.T) {
+	for _, tt := range []struct {
+		iv   Interval
+		want time.Duration
+	}{
+		{Interval{}, 0},
+		{Interval{Microseconds: 1500000}, 1500 * time.Millisecond},
+		{Interval{Days: 2, Microseconds: -usPerHour}, 47 * time.Hour},
+		{Interval{Days: -1}, -24 * time.Hour},
+	} {
+		got, err := tt.iv.Duration()
+		if err != nil || got != tt.want {
+			t.Errorf("Duration() of %s = %v, %v, want %v", tt.iv, got, err, tt.want)
+		}
+	}
+
+	for _, iv := range []Interval{
+		{Months: 1},
+		{Days: 106752},
+		{Microseconds: math.MaxInt64},
+		{Days: -106752},
+	} {
+		if d, err := iv.Duration(); err == nil {
+			t.Errorf("Duration() of %+v = %v, want error", iv, d)
+		}
+	}
+}
+
+func TestIntervalApproxDuration(t *testing.T) {
+	iv := Interval{Months: 1, Days: 1, Microseconds: usPerHour}
+	got, err := iv.ApproxDuration(30)
+	if want := 31*24*time.Hour + time.Hour; err != nil || got != want {
+		t.Errorf("ApproxDuration(30) = %v, %v, want %v", got, err, want)
+	}
+	if _, err := (Interval{Months: 12 * 300}).ApproxDuration(30); err == nil {
+		t.Error("expected error for 300 years")
+	}
+}
+
+func TestFromDuration(t *testing.T) {
+	for _, tt := range []struct {
+		d    time.Duration
+		want Interval
+	}{
+		{0, Interval{}},
+		{36 * time.Hour, Interval{Microseconds: 36 * usPerHour}},
+		{1500 * time.Nanosecond, Interval{Microseconds: 1}},
+		{-1500 * time.Nanosecond, Interval{Microseconds: -1}},
+	} {
+		if got := FromDuration(tt.d); got != tt.want {
+			t.Errorf("FromDuration(%v) = %+v, want %+v", tt.d, got, tt.want)
+		}
+	}
+
+	d := 90*time.Minute + 250*time.Microsecond
+	back, err := FromDuration(d).Duration()
+	if err != nil || back != d {
+		t.Errorf("round trip of %v = %v, %v", d, back, err)
+	}
+}
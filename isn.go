@@ -0,0 +1,163 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// EAN13 represents a value of the isn extension's ean13 type, stored as its
+// 13 digits without hyphens. Use a *EAN13 to scan nullable columns.
+type EAN13 string
+
+// Scan implements the sql.Scanner interface. Hyphens are removed.
+func (e *EAN13) Scan(src interface{}) error {
+	s, err := scanISN(src, "EAN13")
+	if err != nil {
+		return err
+	}
+
+	*e = EAN13(s)
+	return nil
+}
+
+// Value implements the driver.Valuer interface. It returns an error if the
+// check digit is wrong.
+func (e EAN13) Value() (driver.Value, error) {
+	if err := checkEAN13(string(e)); err != nil {
+		return nil, fmt.Errorf("pg: invalid EAN13 %q: %w", string(e), err)
+	}
+	return string(e), nil
+}
+
+// ISBN13 represents a value of the isn extension's isbn13 type, stored as
+// its 13 digits without hyphens. ISBN-10 numbers are converted when
+// scanned. Use a *ISBN13 to scan nullable columns.
+type ISBN13 string
+
+// Scan implements the sql.Scanner interface. Hyphens are removed.
+func (b *ISBN13) Scan(src interface{}) error {
+	s, err := scanISN(src, "ISBN13")
+	if err != nil {
+		return err
+	}
+	if len(s) == 10 {
+		s = "978" + s[:9]
+		s += string(eanCheckDigit(s))
+	}
+
+	*b = ISBN13(s)
+	return nil
+}
+
+// Value implements the driver.Valuer interface. It returns an error if the
+// number is not a 978 or 979 EAN or the check digit is wrong.
+func (b ISBN13) Value() (driver.Value, error) {
+	err := checkEAN13(string(b))
+	if err == nil && !strings.HasPrefix(string(b), "978") && !strings.HasPrefix(string(b), "979") {
+		err = fmt.Errorf("ISBN prefix must be 978 or 979")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pg: invalid ISBN13 %q: %w", string(b), err)
+	}
+	return string(b), nil
+}
+
+// ISSN represents a value of the isn extension's issn type, stored as its
+// eight characters without the hyphen, the last of which is a check digit
+// that may be X. The 13-digit EAN form of an ISSN is converted when
+// scanned. Use a *ISSN to scan nullable columns.
+type ISSN string
+
+// Scan implements the sql.Scanner interface. Hyphens are removed.
+func (n *ISSN) Scan(src interface{}) error {
+	s, err := scanISN(src, "ISSN")
+	if err != nil {
+		return err
+	}
+	if len(s) == 13 && strings.HasPrefix(s, "977") {
+		s = s[3:10]
+		s += string(issnCheckDigit(s))
+	}
+
+	*n = ISSN(s)
+	return nil
+}
+
+// Value implements the driver.Valuer interface. It returns an error if the
+// check digit is wrong.
+func (n ISSN) Value() (driver.Value, error) {
+	s := string(n)
+	if len(s) != 8 || !isDigits(s[:7]) {
+		return nil, fmt.Errorf("pg: invalid ISSN %q: expected 7 digits and a check digit", s)
+	}
+	if c := issnCheckDigit(s[:7]); s[7] != c {
+		return nil, fmt.Errorf("pg: invalid ISSN %q: check digit should be %c", s, c)
+	}
+	return s, nil
+}
+
+// scanISN returns the text of src without hyphens, checking that it only
+// has digits and a possible X check digit.
+func scanISN(src interface{}, typ string) (string, error) {
+	var s string
+	switch src := src.(type) {
+	case []byte:
+		s = string(src)
+	case string:
+		s = src
+	default:
+		return "", fmt.Errorf("pg: cannot convert %T to %s", src, typ)
+	}
+
+	n := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), "-", ""))
+	if n == "" || !isDigits(n[:len(n)-1]) || (!isDigits(n[len(n)-1:]) && n[len(n)-1] != 'X') {
+		return "", parseError(typ, s, -1, nil)
+	}
+	return n, nil
+}
+
+func checkEAN13(s string) error {
+	if len(s) != 13 || !isDigits(s) {
+		return fmt.Errorf("expected 13 digits")
+	}
+	if c := eanCheckDigit(s[:12]); s[12] != c {
+		return fmt.Errorf("check digit should be %c", c)
+	}
+	return nil
+}
+
+// eanCheckDigit returns the check digit of the first 12 digits of an EAN.
+func eanCheckDigit(s string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(s[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// issnCheckDigit returns the check digit of the first 7 digits of an ISSN.
+func issnCheckDigit(s string) byte {
+	sum := 0
+	for i := 0; i < 7; i++ {
+		sum += int(s[i]-'0') * (8 - i)
+	}
+	c := (11 - sum%11) % 11
+	if c == 10 {
+		return 'X'
+	}
+	return byte('0' + c)
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package pg
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestISNScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(EAN13), `9780306406157`, ""},
+		{new(EAN13), `978-0-306-40615-7`, `9780306406157`},
+		{new(ISBN13), `978-0-306-40615-7`, `9780306406157`},
+		{new(ISBN13), `0-306-40615-2`, `9780306406157`},
+		{new(ISBN13), `979-10-90636-07-1`, `9791090636071`},
+		{new(ISSN), `0317-8471`, `03178471`},
+		{new(ISSN), `0000-006x`, `0000006X`},
+		{new(ISSN), `977-0317-847-00-1`, `03178471`},
+	})
+
+	for _, fn := range []func() sql.Scanner{
+		func() sql.Scanner { return new(EAN13) },
+		func() sql.Scanner { return new(ISBN13) },
+		func() sql.Scanner { return new(ISSN) },
+	} {
+		testScanInvalid(t, fn, ``, `-`, `12a4`, `X123`)
+		if err := fn().Scan(nil); err == nil {
+			t.Errorf("%T: expected error scanning NULL", fn())
+		}
+	}
+}
+
+func TestISNValueInvalid(t *testing.T) {
+	if _, err := EAN13("9780306406158").Value(); err == nil {
+		t.Error("EAN13: expected error for a wrong check digit")
+	}
+	if _, err := EAN13("978030640615").Value(); err == nil {
+		t.Error("EAN13: expected error for 12 digits")
+	}
+	if _, err := ISBN13("4006381333931").Value(); err == nil {
+		t.Error("ISBN13: expected error for a non-978 EAN")
+	}
+	if _, err := ISSN("03178472").Value(); err == nil {
+		t.Error("ISSN: expected error for a wrong check digit")
+	}
+	if _, err := ISSN("0317847").Value(); err == nil {
+		t.Error("ISSN: expected error for 7 characters")
+	}
+}
@@ -0,0 +1,320 @@
+package pg
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// NullState distinguishes a SQL NULL from the JSON literal null in a
+// scanned json or jsonb value.
+type NullState uint8
+
+const (
+	// NotNull is a JSON value other than null.
+	NotNull NullState = iota
+	// SQLNull is a NULL column.
+	SQLNull
+	// JSONNull is the JSON literal null.
+	JSONNull
+)
+
+func jsonNullState(src []byte) NullState {
+	if bytes.Equal(bytes.TrimSpace(src), []byte("null")) {
+		return JSONNull
+	}
+	return NotNull
+}
+
+// JSONB represents a json or jsonb column value as raw bytes. Value writes
+// RawMessage if it is not nil, whatever Null is. A nil RawMessage is written
+// as the JSON literal null if Null is JSONNull, and as NULL otherwise.
+type JSONB struct {
+	RawMessage json.RawMessage
+	Null       NullState
+}
+
+// Scan implements the sql.Scanner interface.
+func (j *JSONB) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return j.scanBytes(src)
+	case string:
+		return j.scanBytes([]byte(src))
+	case nil:
+		j.RawMessage = nil
+		j.Null = SQLNull
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to JSONB", src)
+}
+
+func (j *JSONB) scanBytes(src []byte) error {
+	src = trimJSONBVersion(src)
+	if !json.Valid(src) {
+		return fmt.Errorf("pg: invalid JSON in JSONB: %q", src)
+	}
+	// The driver may reuse src after Scan returns, so keep a copy.
+	j.RawMessage = append(json.RawMessage(nil), src...)
+	j.Null = jsonNullState(src)
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (j JSONB) Value() (driver.Value, error) {
+	if j.RawMessage == nil {
+		if j.Null == JSONNull {
+			return "null", nil
+		}
+		return nil, nil
+	}
+	if !json.Valid(j.RawMessage) {
+		return nil, fmt.Errorf("pg: invalid JSON in JSONB: %q", []byte(j.RawMessage))
+	}
+
+	// Bind as text: a []byte parameter would be sent as bytea by some drivers.
+	return string(j.RawMessage), nil
+}
+
+// JSON represents a json or jsonb column value decoded into a T. Null only
+// matters while V is the zero value, as it is after scanning NULL or the
+// JSON literal null: Value then writes NULL for SQLNull and null for
+// JSONNull. Any other V is marshaled, so setting V on a value scanned from
+// NULL writes V.
+type JSON[T any] struct {
+	V    T
+	Null NullState
+}
+
+// Scan implements the sql.Scanner interface.
+func (j *JSON[T]) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return j.scanBytes(src)
+	case string:
+		return j.scanBytes([]byte(src))
+	case nil:
+		var zero T
+		j.V = zero
+		j.Null = SQLNull
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to JSON[%T]", src, j.V)
+}
+
+func (j *JSON[T]) scanBytes(src []byte) error {
+	src = trimJSONBVersion(src)
+	var v T
+	if err := json.Unmarshal(src, &v); err != nil {
+		return fmt.Errorf("pg: cannot unmarshal JSON into %T: %w", v, err)
+	}
+	j.V = v
+	j.Null = jsonNullState(src)
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (j JSON[T]) Value() (driver.Value, error) {
+	if j.Null != NotNull && reflect.ValueOf(&j.V).Elem().IsZero() {
+		if j.Null == JSONNull {
+			return "null", nil
+		}
+		return nil, nil
+	}
+	b, err := json.Marshal(j.V)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+// JSONMap represents a jsonb object column value as a map. Numbers are
+// decoded as json.Number so that no precision is lost. Like JSONB, Value
+// writes Map if it is not nil, and otherwise NULL, or null if Null is
+// JSONNull.
+type JSONMap struct {
+	Map  map[string]interface{}
+	Null NullState
+}
+
+// Scan implements the sql.Scanner interface.
+func (m *JSONMap) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return m.scanBytes(src)
+	case string:
+		return m.scanBytes([]byte(src))
+	case nil:
+		m.Map = nil
+		m.Null = SQLNull
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to JSONMap", src)
+}
+
+func (m *JSONMap) scanBytes(src []byte) error {
+	src = trimJSONBVersion(src)
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+
+	var v map[string]interface{}
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("pg: cannot unmarshal JSON into JSONMap: %w", err)
+	}
+	// More only looks for the start of another value, missing a stray
+	// closing delimiter, so anything but the end of the input is an error.
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return fmt.Errorf("pg: invalid JSON in JSONMap: unexpected data after object")
+	}
+	m.Map = v
+	m.Null = jsonNullState(src)
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (m JSONMap) Value() (driver.Value, error) {
+	if m.Map == nil {
+		if m.Null == JSONNull {
+			return "null", nil
+		}
+		return nil, nil
+	}
+	b, err := json.Marshal(m.Map)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+// jsonbVersion is the version byte prepended to jsonb values in the binary
+// wire format.
+const jsonbVersion = 1
+
+// trimJSONBVersion strips the jsonb binary format version byte which some
+// drivers pass through as-is. JSON text can never start with this byte.
+func trimJSONBVersion(src []byte) []byte {
+	if len(src) > 0 && src[0] == jsonbVersion {
+		return src[1:]
+	}
+	return src
+}
+
+// JSONArrayDecoder reads the elements of a JSON array one at a time, so that
+// large json array columns can be processed without decoding the whole value
+// at once.
+//
+//	dec := pg.NewJSONArrayDecoder(bytes.NewReader(src))
+//	for dec.Next() {
+//		var item Item
+//		if err := dec.Decode(&item); err != nil {
+//			return err
+//		}
+//	}
+//	if err := dec.Err(); err != nil {
+//		return err
+//	}
+type JSONArrayDecoder struct {
+	dec     *json.Decoder
+	started bool
+	done    bool
+	err     error
+}
+
+// NewJSONArrayDecoder returns a decoder reading a JSON array from r.
+func NewJSONArrayDecoder(r io.Reader) *JSONArrayDecoder {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return &JSONArrayDecoder{dec: dec}
+}
+
+// Next reports whether there is another element to decode. It returns false
+// at the end of the array or after an error, see Err.
+func (d *JSONArrayDecoder) Next() bool {
+	if d.done {
+		return false
+	}
+	if !d.started {
+		d.started = true
+		if err := d.readDelim('['); err != nil {
+			return d.fail(err)
+		}
+	}
+	if d.dec.More() {
+		return true
+	}
+	d.done = true
+	if err := d.readDelim(']'); err != nil {
+		return d.fail(err)
+	}
+	return false
+}
+
+// Decode decodes the next element into v.
+func (d *JSONArrayDecoder) Decode(v interface{}) error {
+	if d.done || !d.started {
+		return fmt.Errorf("pg: Decode called without a successful call to Next")
+	}
+	if err := d.dec.Decode(v); err != nil {
+		d.fail(err)
+		return d.err
+	}
+	return nil
+}
+
+// Err returns the error, if any, that was encountered while decoding.
+func (d *JSONArrayDecoder) Err() error {
+	return d.err
+}
+
+func (d *JSONArrayDecoder) readDelim(delim json.Delim) error {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %q, got %v", delim, tok)
+	}
+	return nil
+}
+
+func (d *JSONArrayDecoder) fail(err error) bool {
+	d.done = true
+	d.err = fmt.Errorf("pg: cannot decode JSON array: %w", err)
+	return false
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// ScanJSONRows scans a single json or jsonb array column, as produced by
+// json_agg or array_to_json(array_agg(row_to_json(t))), into a slice of T.
+// json_agg returns NULL when there are no rows, in which case an empty slice
+// is returned.
+func ScanJSONRows[T any](row rowScanner) ([]T, error) {
+	var src JSONB
+	if err := row.Scan(&src); err != nil {
+		return nil, err
+	}
+	if src.Null != NotNull {
+		return []T{}, nil
+	}
+
+	var v []T
+	if err := json.Unmarshal(src.RawMessage, &v); err != nil {
+		return nil, fmt.Errorf("pg: cannot unmarshal JSON into []%T: %w", *new(T), err)
+	}
+	if v == nil {
+		v = []T{}
+	}
+	return v, nil
+}
@@ -0,0 +1,301 @@
+package pg
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONBScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(JSONB), `{}`, ""},
+		{new(JSONB), `{"a": 1, "b": [true, null, "c"]}`, ""},
+		{new(JSONB), `[1, 2.5, -3e10]`, ""},
+		{new(JSONB), `"text"`, ""},
+		{new(JSONB), `12345678901234567890`, ""},
+	})
+}
+
+func TestJSONBScan(t *testing.T) {
+	src := []byte(`{"a": 1}`)
+	var j JSONB
+	if err := j.Scan(src); err != nil {
+		t.Fatal(err)
+	}
+	src[2] = 'b'
+	if string(j.RawMessage) != `{"a": 1}` {
+		t.Fatalf("RawMessage = %s, changed with the scanned buffer", j.RawMessage)
+	}
+}
+
+func TestJSONBScanInvalid(t *testing.T) {
+	testScanInvalid(t, func() sql.Scanner { return new(JSONB) },
+		``,
+		`{`,
+		`{"a":}`,
+		`{} {}`,
+		`nul`,
+	)
+}
+
+func TestJSONBValueInvalid(t *testing.T) {
+	j := JSONB{RawMessage: []byte(`{"a"}`)}
+	if v, err := j.Value(); err == nil {
+		t.Fatalf("Value of %s = %v, want error", j.RawMessage, v)
+	}
+}
+
+type testItem struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func TestJSONScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(JSON[testItem]), `{"name":"a","count":2,"tags":["x","y"]}`, ""},
+		{new(JSON[testItem]), `{"name": "a", "other": 1}`, `{"name":"a"}`},
+		{new(JSON[[]int]), `[1, 2, 3]`, `[1,2,3]`},
+		{new(JSON[map[string]bool]), `{"b": false, "a": true}`, `{"a":true,"b":false}`},
+	})
+}
+
+func TestJSONScan(t *testing.T) {
+	var j JSON[testItem]
+	if err := j.Scan(`{"name": "a", "tags": ["x"]}`); err != nil {
+		t.Fatal(err)
+	}
+	if want := (testItem{Name: "a", Tags: []string{"x"}}); !reflect.DeepEqual(j.V, want) {
+		t.Fatalf("Scan = %+v, want %+v", j.V, want)
+	}
+}
+
+func TestJSONScanInvalid(t *testing.T) {
+	testScanInvalid(t, func() sql.Scanner { return new(JSON[testItem]) },
+		``,
+		`{"name": 1}`,
+		`[]`,
+		`{"name": "a"`,
+	)
+}
+
+func TestJSONMapScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(JSONMap), `{}`, ""},
+		{new(JSONMap), `{"b": [1, "x"], "a": {"c": null}}`, `{"a":{"c":null},"b":[1,"x"]}`},
+		// Numbers are kept as json.Number, so no precision is lost.
+		{new(JSONMap), `{"n": 12345678901234567890.123456789}`, `{"n":12345678901234567890.123456789}`},
+	})
+}
+
+func TestJSONMapScanInvalid(t *testing.T) {
+	testScanInvalid(t, func() sql.Scanner { return new(JSONMap) },
+		``,
+		`[]`,
+		`"a"`,
+		`{"a": 1`,
+		`{"a": 1} {}`,
+		`{"a": 1}]`,
+		`{"a": 1}}`,
+		`{"a": 1} x`,
+	)
+}
+
+func TestJSONBVersionByte(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(JSONB), "\x01{\"a\": 1}", `{"a": 1}`},
+		{new(JSONB), "\x01null", `null`},
+		{new(JSON[testItem]), "\x01{\"name\": \"a\"}", `{"name":"a"}`},
+		{new(JSONMap), "\x01{\"a\": 1}", `{"a":1}`},
+		{new(JSONPath), "\x01$.\"a\"", `$."a"`},
+	})
+	// Only a single leading version byte is stripped.
+	testScanInvalid(t, func() sql.Scanner { return new(JSONB) }, "\x01\x01{}", "{}\x01")
+}
+
+func TestJSONArrayDecoder(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []interface{}
+	}{
+		{`[]`, nil},
+		{` [ ] `, nil},
+		{`[1, "a", null, {"b": 2.5}, [true]]`, []interface{}{
+			json.Number("1"), "a", nil, map[string]interface{}{"b": json.Number("2.5")}, []interface{}{true},
+		}},
+	}
+	for _, tt := range tests {
+		dec := NewJSONArrayDecoder(strings.NewReader(tt.src))
+		var got []interface{}
+		for dec.Next() {
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				t.Fatalf("Decode of %s: %v", tt.src, err)
+			}
+			got = append(got, v)
+		}
+		if err := dec.Err(); err != nil {
+			t.Errorf("Err of %s: %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("elements of %s = %#v, want %#v", tt.src, got, tt.want)
+		}
+		if dec.Next() {
+			t.Errorf("Next after the end of %s = true", tt.src)
+		}
+	}
+}
+
+func TestJSONArrayDecoderInvalid(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`{}`,
+		`1`,
+		`[1, 2`,
+		`[1 2]`,
+		`[1,]`,
+	} {
+		dec := NewJSONArrayDecoder(strings.NewReader(src))
+		for dec.Next() {
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				break
+			}
+		}
+		if dec.Err() == nil {
+			t.Errorf("Err of %s = nil, want error", src)
+		}
+	}
+
+	dec := NewJSONArrayDecoder(strings.NewReader(`[1]`))
+	var v interface{}
+	if err := dec.Decode(&v); err == nil {
+		t.Error("Decode before Next succeeded")
+	}
+}
+
+func TestJSONNullState(t *testing.T) {
+	var (
+		j JSONB
+		v JSON[*testItem]
+		m JSONMap
+	)
+	tests := []struct {
+		src   interface{}
+		want  NullState
+		value interface{}
+	}{
+		{nil, SQLNull, nil},
+		{`null`, JSONNull, "null"},
+		{`{"name":"a"}`, NotNull, `{"name":"a"}`},
+	}
+	for _, tt := range tests {
+		for _, s := range []struct {
+			scanner sql.Scanner
+			null    *NullState
+		}{{&j, &j.Null}, {&v, &v.Null}, {&m, &m.Null}} {
+			if err := s.scanner.Scan(tt.src); err != nil {
+				t.Errorf("%T: Scan(%v): %v", s.scanner, tt.src, err)
+				continue
+			}
+			if *s.null != tt.want {
+				t.Errorf("%T: Null after Scan(%v) = %d, want %d", s.scanner, tt.src, *s.null, tt.want)
+			}
+			value, err := s.scanner.(driver.Valuer).Value()
+			if err != nil {
+				t.Errorf("%T: Value after Scan(%v): %v", s.scanner, tt.src, err)
+				continue
+			}
+			if value != tt.value {
+				t.Errorf("%T: Value after Scan(%v) = %#v, want %#v", s.scanner, tt.src, value, tt.value)
+			}
+		}
+	}
+}
+
+func TestJSONNullPayload(t *testing.T) {
+	// A payload set after scanning NULL is written rather than NULL.
+	j := JSONB{Null: SQLNull, RawMessage: []byte(`[1]`)}
+	v := JSON[int]{Null: SQLNull, V: 2}
+	m := JSONMap{Null: SQLNull, Map: map[string]interface{}{"a": 3}}
+	for _, tt := range []struct {
+		valuer driver.Valuer
+		want   string
+	}{{j, `[1]`}, {v, `2`}, {m, `{"a":3}`}} {
+		if got, err := tt.valuer.Value(); err != nil || got != tt.want {
+			t.Errorf("Value of %+v = %#v, %v, want %s", tt.valuer, got, err, tt.want)
+		}
+	}
+
+	// Without a payload, Null picks between NULL and null.
+	for _, tt := range []struct {
+		valuer driver.Valuer
+		want   driver.Value
+	}{
+		{JSONB{}, nil},
+		{JSONB{Null: JSONNull}, "null"},
+		{JSON[*testItem]{Null: SQLNull}, nil},
+		{JSON[*testItem]{Null: JSONNull}, "null"},
+		{JSON[*testItem]{}, "null"},
+		{JSON[int]{}, "0"},
+		{JSONMap{}, nil},
+		{JSONMap{Null: JSONNull}, "null"},
+	} {
+		if got, err := tt.valuer.Value(); err != nil || got != tt.want {
+			t.Errorf("Value of %+v = %#v, %v, want %#v", tt.valuer, got, err, tt.want)
+		}
+	}
+}
+
+// testRow is a single-column row for ScanJSONRows.
+type testRow struct {
+	src interface{}
+	err error
+}
+
+func (r testRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	return dest[0].(sql.Scanner).Scan(r.src)
+}
+
+func TestScanJSONRows(t *testing.T) {
+	tests := []struct {
+		src  interface{}
+		want []testItem
+	}{
+		{nil, []testItem{}},
+		{`null`, []testItem{}},
+		{`[]`, []testItem{}},
+		{[]byte(`[{"name":"a","count":1},{"name":"b"}]`), []testItem{{Name: "a", Count: 1}, {Name: "b"}}},
+		{"\x01[{\"name\":\"a\"}]", []testItem{{Name: "a"}}},
+	}
+	for _, tt := range tests {
+		got, err := ScanJSONRows[testItem](testRow{src: tt.src})
+		if err != nil {
+			t.Errorf("ScanJSONRows(%v): %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ScanJSONRows(%v) = %+v, want %+v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestScanJSONRowsInvalid(t *testing.T) {
+	errScan := errors.New("scan failed")
+	if _, err := ScanJSONRows[testItem](testRow{err: errScan}); !errors.Is(err, errScan) {
+		t.Errorf("ScanJSONRows error = %v, want %v", err, errScan)
+	}
+	for _, src := range []string{`{"name":"a"}`, `[{"name":1}]`, `[`} {
+		if got, err := ScanJSONRows[testItem](testRow{src: src}); err == nil {
+			t.Errorf("ScanJSONRows(%s) = %+v, want error", src, got)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// JSONPath represents a PostgreSQL jsonpath value.
+type JSONPath struct {
+	Path string
+}
+
+// Scan implements the sql.Scanner interface. Like jsonb, the binary format
+// of jsonpath is its text after a version byte, which is stripped.
+func (p *JSONPath) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		p.Path = string(trimJSONBVersion(src))
+		return nil
+	case string:
+		p.Path = string(trimJSONBVersion([]byte(src)))
+		return nil
+	case nil:
+		p.Path = ""
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to JSONPath", src)
+}
+
+// Value implements the driver.Valuer interface.
+func (p JSONPath) Value() (driver.Value, error) {
+	if p.Path == "" {
+		return nil, nil
+	}
+	if err := validateJSONPath(p.Path); err != nil {
+		return nil, err
+	}
+
+	return p.Path, nil
+}
+
+// validateJSONPath performs a basic syntactic check of a jsonpath
+// expression: it must not be empty, string literals must be terminated and
+// brackets must be balanced. Full validation is left to the server.
+func validateJSONPath(path string) error {
+	expr := strings.TrimSpace(path)
+	for _, mode := range []string{"strict", "lax"} {
+		if len(expr) > len(mode) && strings.HasPrefix(expr, mode) && isJSONPathSpace(expr[len(mode)]) {
+			expr = strings.TrimSpace(expr[len(mode):])
+			break
+		}
+	}
+	if expr == "" {
+		return fmt.Errorf("pg: invalid jsonpath %q: empty expression", path)
+	}
+
+	var stack []byte
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; c {
+		case '"':
+			for i++; i < len(expr) && expr[i] != '"'; i++ {
+				if expr[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(expr) {
+				return fmt.Errorf("pg: invalid jsonpath %q: unterminated string literal", path)
+			}
+		case '(', '[':
+			stack = append(stack, c)
+		case ')', ']':
+			open := byte('(')
+			if c == ']' {
+				open = '['
+			}
+			if len(stack) == 0 || stack[len(stack)-1] != open {
+				return fmt.Errorf("pg: invalid jsonpath %q: unexpected %q at offset %d", path, c, i)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("pg: invalid jsonpath %q: unclosed %q", path, stack[len(stack)-1])
+	}
+
+	return nil
+}
+
+func isJSONPathSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,33 @@
+package pg
+
+import "testing"
+
+func TestJSONPathScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(JSONPath), `$`, ""},
+		{new(JSONPath), `$."a"[*]."b"`, ""},
+		{new(JSONPath), `strict $."a"`, ""},
+		{new(JSONPath), `lax $."a"[0 to 2]`, ""},
+		{new(JSONPath), `$."a"?(@ > 2 && @."b" == "x)")`, ""},
+		{new(JSONPath), `$."a \"b\""`, ""},
+	})
+}
+
+func TestJSONPathValueInvalid(t *testing.T) {
+	for _, path := range []string{
+		` `,
+		`$."a`,
+		`$."a\"`,
+		`$.a[0`,
+		`$.a)`,
+		`$.a[0)`,
+	} {
+		if v, err := (JSONPath{Path: path}).Value(); err == nil {
+			t.Errorf("Value of %q = %q, want error", path, v)
+		}
+	}
+}
+
+func TestJSONPathNull(t *testing.T) {
+	testScanNull(t, new(JSONPath))
+}
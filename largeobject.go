@@ -0,0 +1,149 @@
+package pg
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+)
+
+// maxLargeObjectChunk is the largest number of bytes read or written by a
+// single call to the server.
+const maxLargeObjectChunk = 1 << 20
+
+// LargeObjectMode is the access mode a large object is opened with.
+type LargeObjectMode int32
+
+const (
+	// LargeObjectRead opens a large object for reading. Reads see the
+	// object as of the start of the transaction's snapshot.
+	LargeObjectRead LargeObjectMode = 0x40000
+	// LargeObjectWrite opens a large object for writing. Combine it with
+	// LargeObjectRead to read the object's current contents as well.
+	LargeObjectWrite LargeObjectMode = 0x20000
+)
+
+// LargeObjects creates, opens and removes PostgreSQL large objects using the
+// server-side lo_* functions, so that it works with any driver. Large
+// objects can only be used within a transaction, and any open objects are
+// closed when it ends.
+type LargeObjects struct {
+	tx *sql.Tx
+}
+
+// NewLargeObjects returns a LargeObjects using the transaction tx.
+func NewLargeObjects(tx *sql.Tx) *LargeObjects {
+	return &LargeObjects{tx: tx}
+}
+
+// Create creates a new, empty large object and returns its OID.
+func (o *LargeObjects) Create() (uint32, error) {
+	var oid uint32
+	if err := o.tx.QueryRow("SELECT lo_creat(-1)").Scan(&oid); err != nil {
+		return 0, err
+	}
+	return oid, nil
+}
+
+// Open opens the large object with the given OID.
+func (o *LargeObjects) Open(oid uint32, mode LargeObjectMode) (*LargeObject, error) {
+	var fd int32
+	if err := o.tx.QueryRow("SELECT lo_open($1, $2)", oid, int32(mode)).Scan(&fd); err != nil {
+		return nil, err
+	}
+	return &LargeObject{tx: o.tx, fd: fd}, nil
+}
+
+// Unlink removes the large object with the given OID.
+func (o *LargeObjects) Unlink(oid uint32) error {
+	_, err := o.tx.Exec("SELECT lo_unlink($1)", oid)
+	return err
+}
+
+// LargeObject is an open large object. It implements io.Reader, io.Writer,
+// io.Seeker and io.Closer, reading and writing at the current position of
+// the server-side descriptor.
+type LargeObject struct {
+	tx *sql.Tx
+	fd int32
+}
+
+var _ io.ReadWriteSeeker = (*LargeObject)(nil)
+
+// Read implements the io.Reader interface. It reads at most 1 MiB at a time.
+func (l *LargeObject) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n := len(p)
+	if n > maxLargeObjectChunk {
+		n = maxLargeObjectChunk
+	}
+
+	var data []byte
+	if err := l.tx.QueryRow("SELECT loread($1, $2)", l.fd, n).Scan(&data); err != nil {
+		return 0, err
+	}
+	if len(data) == 0 {
+		return 0, io.EOF
+	}
+	return copy(p, data), nil
+}
+
+// Write implements the io.Writer interface.
+func (l *LargeObject) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		chunk := p[written:]
+		if len(chunk) > maxLargeObjectChunk {
+			chunk = chunk[:maxLargeObjectChunk]
+		}
+
+		var n int
+		if err := l.tx.QueryRow("SELECT lowrite($1, $2)", l.fd, chunk).Scan(&n); err != nil {
+			return written, err
+		}
+		written += n
+		if n < len(chunk) {
+			return written, io.ErrShortWrite
+		}
+	}
+	return written, nil
+}
+
+// Seek implements the io.Seeker interface.
+func (l *LargeObject) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart, io.SeekCurrent, io.SeekEnd:
+	default:
+		return 0, fmt.Errorf("pg: invalid whence %d for large object seek", whence)
+	}
+
+	// The server's whence values are those of io.
+	var pos int64
+	if err := l.tx.QueryRow("SELECT lo_lseek64($1, $2, $3)", l.fd, offset, whence).Scan(&pos); err != nil {
+		return 0, err
+	}
+	return pos, nil
+}
+
+// Tell returns the current position in the large object.
+func (l *LargeObject) Tell() (int64, error) {
+	var pos int64
+	if err := l.tx.QueryRow("SELECT lo_tell64($1)", l.fd).Scan(&pos); err != nil {
+		return 0, err
+	}
+	return pos, nil
+}
+
+// Truncate truncates the large object to size bytes, or extends it with
+// zeros if it is shorter.
+func (l *LargeObject) Truncate(size int64) error {
+	_, err := l.tx.Exec("SELECT lo_truncate64($1, $2)", l.fd, size)
+	return err
+}
+
+// Close implements the io.Closer interface.
+func (l *LargeObject) Close() error {
+	_, err := l.tx.Exec("SELECT lo_close($1)", l.fd)
+	return err
+}
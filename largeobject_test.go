@@ -0,0 +1,278 @@
+package pg
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// loServer fakes the server-side lo_* functions for a database/sql driver,
+// keeping large objects in memory.
+type loServer struct {
+	objects  map[int64][]byte
+	fds      map[int64]*loDescriptor
+	nextOID  int64
+	nextFD   int64
+	maxRead  int64
+	maxWrite int
+}
+
+type loDescriptor struct {
+	oid int64
+	pos int64
+}
+
+func (s *loServer) call(query string, args []driver.Value) (driver.Value, error) {
+	arg := func(i int) int64 { return args[i].(int64) }
+	fd := func() (*loDescriptor, error) {
+		d, ok := s.fds[arg(0)]
+		if !ok {
+			return nil, fmt.Errorf("invalid large-object descriptor: %d", arg(0))
+		}
+		return d, nil
+	}
+
+	name := strings.TrimPrefix(query, "SELECT ")
+	name = name[:strings.IndexByte(name, '(')]
+	switch name {
+	case "lo_creat":
+		s.nextOID++
+		s.objects[s.nextOID] = nil
+		return s.nextOID, nil
+	case "lo_open":
+		if _, ok := s.objects[arg(0)]; !ok {
+			return nil, fmt.Errorf("large object %d does not exist", arg(0))
+		}
+		s.nextFD++
+		s.fds[s.nextFD] = &loDescriptor{oid: arg(0)}
+		return s.nextFD, nil
+	case "lo_unlink":
+		delete(s.objects, arg(0))
+		return int64(1), nil
+	}
+
+	d, err := fd()
+	if err != nil {
+		return nil, err
+	}
+	data := s.objects[d.oid]
+	switch name {
+	case "loread":
+		n := arg(1)
+		if n > s.maxRead {
+			s.maxRead = n
+		}
+		if d.pos >= int64(len(data)) {
+			return []byte{}, nil
+		}
+		end := d.pos + n
+		if end > int64(len(data)) {
+			end = int64(len(data))
+		}
+		v := append([]byte{}, data[d.pos:end]...)
+		d.pos = end
+		return v, nil
+	case "lowrite":
+		p := args[1].([]byte)
+		if len(p) > s.maxWrite {
+			s.maxWrite = len(p)
+		}
+		for int64(len(data)) < d.pos+int64(len(p)) {
+			data = append(data, 0)
+		}
+		copy(data[d.pos:], p)
+		s.objects[d.oid] = data
+		d.pos += int64(len(p))
+		return int64(len(p)), nil
+	case "lo_lseek64":
+		switch arg(2) {
+		case 0:
+			d.pos = arg(1)
+		case 1:
+			d.pos += arg(1)
+		case 2:
+			d.pos = int64(len(data)) + arg(1)
+		}
+		return d.pos, nil
+	case "lo_tell64":
+		return d.pos, nil
+	case "lo_truncate64":
+		size := arg(1)
+		for int64(len(data)) < size {
+			data = append(data, 0)
+		}
+		s.objects[d.oid] = data[:size]
+		return int64(0), nil
+	case "lo_close":
+		delete(s.fds, arg(0))
+		return int64(0), nil
+	}
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+type loDriver struct{ s *loServer }
+
+func (d loDriver) Open(string) (driver.Conn, error) { return loConn(d), nil }
+
+type loConn struct{ s *loServer }
+
+func (c loConn) Prepare(query string) (driver.Stmt, error) { return loStmt{c.s, query}, nil }
+func (c loConn) Close() error                              { return nil }
+func (c loConn) Begin() (driver.Tx, error)                 { return c, nil }
+func (c loConn) Commit() error                             { return nil }
+func (c loConn) Rollback() error                           { return nil }
+
+type loStmt struct {
+	s     *loServer
+	query string
+}
+
+func (s loStmt) Close() error  { return nil }
+func (s loStmt) NumInput() int { return -1 }
+
+func (s loStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if _, err := s.s.call(s.query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s loStmt) Query(args []driver.Value) (driver.Rows, error) {
+	v, err := s.s.call(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &loRows{v: v}, nil
+}
+
+type loRows struct {
+	v    driver.Value
+	done bool
+}
+
+func (r *loRows) Columns() []string { return []string{"v"} }
+func (r *loRows) Close() error      { return nil }
+
+func (r *loRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.v
+	return nil
+}
+
+var testLOServer = &loServer{objects: map[int64][]byte{}, fds: map[int64]*loDescriptor{}}
+
+func init() {
+	sql.Register("pg-test-largeobject", loDriver{testLOServer})
+}
+
+func beginLargeObjects(t *testing.T) *LargeObjects {
+	t.Helper()
+	db, err := sql.Open("pg-test-largeobject", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return NewLargeObjects(tx)
+}
+
+func TestLargeObject(t *testing.T) {
+	los := beginLargeObjects(t)
+	oid, err := los.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lo, err := los.Open(oid, LargeObjectRead|LargeObjectWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := lo.Write([]byte("hello, world")); err != nil || n != 12 {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if pos, err := lo.Tell(); err != nil || pos != 12 {
+		t.Errorf("Tell = %d, %v", pos, err)
+	}
+	if pos, err := lo.Seek(-5, io.SeekEnd); err != nil || pos != 7 {
+		t.Errorf("Seek = %d, %v", pos, err)
+	}
+	if b, err := io.ReadAll(lo); err != nil || string(b) != "world" {
+		t.Errorf("ReadAll = %q, %v", b, err)
+	}
+
+	if err := lo.Truncate(5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lo.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := io.ReadAll(lo); err != nil || string(b) != "hello" {
+		t.Errorf("ReadAll after Truncate = %q, %v", b, err)
+	}
+	if _, err := lo.Seek(0, 3); err == nil {
+		t.Error("expected error for an invalid whence")
+	}
+
+	if err := lo.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lo.Tell(); err == nil {
+		t.Error("expected error using a closed large object")
+	}
+	if err := los.Unlink(oid); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := los.Open(oid, LargeObjectRead); err == nil {
+		t.Error("expected error opening a removed large object")
+	}
+}
+
+func TestLargeObjectChunks(t *testing.T) {
+	los := beginLargeObjects(t)
+	oid, err := los.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lo, err := los.Open(oid, LargeObjectRead|LargeObjectWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := bytes.Repeat([]byte("0123456789"), maxLargeObjectChunk/4)
+	testLOServer.maxWrite = 0
+	if n, err := lo.Write(data); err != nil || n != len(data) {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if testLOServer.maxWrite != maxLargeObjectChunk {
+		t.Errorf("largest write = %d, want %d", testLOServer.maxWrite, maxLargeObjectChunk)
+	}
+	if _, err := lo.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	testLOServer.maxRead = 0
+	got := make([]byte, len(data))
+	if _, err := io.ReadFull(lo, got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("read data differs from written data")
+	}
+	if testLOServer.maxRead != maxLargeObjectChunk {
+		t.Errorf("largest read = %d, want %d", testLOServer.maxRead, maxLargeObjectChunk)
+	}
+	if n, err := lo.Read(nil); n != 0 || err != nil {
+		t.Errorf("Read(nil) = %d, %v", n, err)
+	}
+}
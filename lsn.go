@@ -0,0 +1,110 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LSN represents a PostgreSQL pg_lsn value, a position in the write-ahead
+// log. Use a *LSN to scan nullable columns.
+type LSN uint64
+
+// ParseLSN parses an LSN in the form XXX/XXX of two hexadecimal numbers,
+// the high and low 32 bits, such as "16/B374D848".
+func ParseLSN(s string) (LSN, error) {
+	hi, lo, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok || len(hi) < 1 || len(hi) > 8 || len(lo) < 1 || len(lo) > 8 {
+		return 0, parseError("LSN", s, -1, nil)
+	}
+	h, err := strconv.ParseUint(hi, 16, 32)
+	if err != nil {
+		return 0, parseError("LSN", s, -1, nil)
+	}
+	l, err := strconv.ParseUint(lo, 16, 32)
+	if err != nil {
+		return 0, parseError("LSN", s, -1, nil)
+	}
+	return LSN(h<<32 | l), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (l *LSN) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return l.scanString(string(src))
+	case string:
+		return l.scanString(src)
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to LSN", src)
+}
+
+func (l *LSN) scanString(src string) error {
+	v, err := ParseLSN(src)
+	if err != nil {
+		return err
+	}
+
+	*l = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (l LSN) Value() (driver.Value, error) {
+	return l.String(), nil
+}
+
+// AppendValue appends the text of l to dst, like Value but without
+// allocating a string.
+func (l LSN) AppendValue(dst []byte) ([]byte, error) {
+	return l.appendText(dst), nil
+}
+
+// String returns l in the server's output format, such as "16/B374D848".
+func (l LSN) String() string {
+	return string(l.appendText(nil))
+}
+
+// appendText appends l as X/X in upper-case hex, as the server outputs it.
+func (l LSN) appendText(b []byte) []byte {
+	n := len(b)
+	b = strconv.AppendUint(b, uint64(l>>32), 16)
+	b = append(b, '/')
+	b = strconv.AppendUint(b, uint64(uint32(l)), 16)
+	for i := n; i < len(b); i++ {
+		if b[i] >= 'a' {
+			b[i] -= 'a' - 'A'
+		}
+	}
+	return b
+}
+
+// Compare returns -1, 0 or 1 depending on whether l is before, at or after
+// o.
+func (l LSN) Compare(o LSN) int {
+	switch {
+	case l < o:
+		return -1
+	case l > o:
+		return 1
+	}
+	return 0
+}
+
+// Sub returns the number of bytes of write-ahead log between o and l, like
+// pg_wal_lsn_diff(l, o). It is negative if l is before o.
+func (l LSN) Sub(o LSN) int64 {
+	return int64(l - o)
+}
+
+// Add returns the LSN n bytes after l, like the server's pg_lsn + numeric
+// operator. It returns an error if the result is out of range.
+func (l LSN) Add(n int64) (LSN, error) {
+	r := l + LSN(n)
+	if (n > 0 && r < l) || (n < 0 && r > l) {
+		return 0, fmt.Errorf("pg: LSN out of range")
+	}
+	return r, nil
+}
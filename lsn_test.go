@@ -0,0 +1,55 @@
+package pg
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestLSNScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(LSN), `0/0`, ""},
+		{new(LSN), `16/B374D848`, ""},
+		{new(LSN), `16/b374d848`, `16/B374D848`},
+		{new(LSN), `FFFFFFFF/FFFFFFFF`, ""},
+		{new(LSN), `0/00000001`, `0/1`},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(LSN) },
+		``,
+		`16`,
+		`/1`,
+		`1/`,
+		`1/2/3`,
+		`G/0`,
+		`100000000/0`,
+		`-1/0`,
+	)
+	if err := new(LSN).Scan(nil); err == nil {
+		t.Error("expected error scanning NULL into LSN")
+	}
+}
+
+func TestLSNArithmetic(t *testing.T) {
+	a, _ := ParseLSN("16/B374D848")
+	b, _ := ParseLSN("16/B374D800")
+	if got := a.Sub(b); got != 0x48 {
+		t.Errorf("Sub = %d", got)
+	}
+	if got := b.Sub(a); got != -0x48 {
+		t.Errorf("Sub = %d", got)
+	}
+	if a.Compare(b) != 1 || b.Compare(a) != -1 || a.Compare(a) != 0 {
+		t.Error("Compare gave wrong order")
+	}
+	if got, err := b.Add(0x48); err != nil || got != a {
+		t.Errorf("Add = %s, %v", got, err)
+	}
+	if got, err := a.Add(-0x48); err != nil || got != b {
+		t.Errorf("Add = %s, %v", got, err)
+	}
+	if _, err := LSN(1<<64 - 1).Add(1); err == nil {
+		t.Error("expected overflow error")
+	}
+	if _, err := LSN(0).Add(-1); err == nil {
+		t.Error("expected underflow error")
+	}
+}
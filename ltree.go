@@ -0,0 +1,299 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLTreeLabel is the maximum length of an ltree label.
+const maxLTreeLabel = 1000
+
+// LTree represents a value of the ltree extension type, a label path such
+// as "Top.Science.Astronomy", as its labels. An empty path has no labels.
+// Use a *LTree to scan nullable columns.
+type LTree struct {
+	Labels []string
+}
+
+// Scan implements the sql.Scanner interface. The labels are not checked, as
+// the characters the server allows in them depend on its version and
+// locale.
+func (t *LTree) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return t.scanString(string(src))
+	case string:
+		return t.scanString(src)
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to LTree", src)
+}
+
+func (t *LTree) scanString(src string) error {
+	s := strings.TrimSpace(src)
+	if s == "" {
+		t.Labels = []string{}
+		return nil
+	}
+
+	t.Labels = strings.Split(s, ".")
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (t LTree) Value() (driver.Value, error) {
+	for _, label := range t.Labels {
+		if err := checkLTreeLabel(label); err != nil {
+			return nil, fmt.Errorf("pg: invalid LTree: %w", err)
+		}
+	}
+
+	return t.String(), nil
+}
+
+// String returns the labels of t joined with dots.
+func (t LTree) String() string {
+	return strings.Join(t.Labels, ".")
+}
+
+// IsAncestorOf reports whether t is an ancestor of o or equal to it, like
+// the extension's @> operator.
+func (t LTree) IsAncestorOf(o LTree) bool {
+	if len(t.Labels) > len(o.Labels) {
+		return false
+	}
+	for i, label := range t.Labels {
+		if o.Labels[i] != label {
+			return false
+		}
+	}
+	return true
+}
+
+// IsDescendantOf reports whether t is a descendant of o or equal to it, like
+// the extension's <@ operator.
+func (t LTree) IsDescendantOf(o LTree) bool {
+	return o.IsAncestorOf(t)
+}
+
+// checkLTreeLabel checks that label is a valid ltree label: a non-empty
+// sequence of letters, digits, underscores and hyphens. Any non-ASCII
+// character is accepted, since from PostgreSQL 16 on labels may hold the
+// letters of the server's locale.
+func checkLTreeLabel(label string) error {
+	if label == "" {
+		return fmt.Errorf("empty label")
+	}
+	if utf8.RuneCountInString(label) > maxLTreeLabel {
+		return fmt.Errorf("label is longer than %d characters", maxLTreeLabel)
+	}
+	for i := 0; i < len(label); i++ {
+		if !isLTreeLabelChar(label[i]) {
+			return fmt.Errorf("label %q has invalid character %q", label, label[i])
+		}
+	}
+	return nil
+}
+
+func isLTreeLabelChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-' || c >= utf8.RuneSelf
+}
+
+// LQuery represents a value of the ltree extension's lquery type, a pattern
+// for matching label paths such as "*.Science.!Astronomy|Physics.*{1,2}".
+// Use a *LQuery to scan nullable columns.
+type LQuery struct {
+	Query string
+}
+
+// Scan implements the sql.Scanner interface.
+func (q *LQuery) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		q.Query = string(src)
+		return nil
+	case string:
+		q.Query = src
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to LQuery", src)
+}
+
+// Value implements the driver.Valuer interface. It returns an error if the
+// pattern is not valid lquery syntax.
+func (q LQuery) Value() (driver.Value, error) {
+	if err := checkLQuery(q.Query); err != nil {
+		return nil, fmt.Errorf("pg: invalid LQuery %q: %w", q.Query, err)
+	}
+
+	return q.Query, nil
+}
+
+// checkLQuery checks the syntax of an lquery: dot-separated levels, each
+// either * or an optionally negated list of |-separated labels with
+// optional @, * and % modifiers, and each with an optional {n}, {n,}, {,m}
+// or {n,m} quantifier.
+func checkLQuery(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return fmt.Errorf("empty query")
+	}
+
+	for _, level := range strings.Split(s, ".") {
+		if i := strings.IndexByte(level, '{'); i >= 0 {
+			if err := checkLQueryQuantifier(level[i:]); err != nil {
+				return err
+			}
+			level = level[:i]
+		}
+		if level == "*" {
+			continue
+		}
+		level = strings.TrimPrefix(level, "!")
+		for _, label := range strings.Split(level, "|") {
+			label = strings.TrimRight(label, "@*%")
+			if err := checkLTreeLabel(label); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkLQueryQuantifier checks a level quantifier, such as "{1,3}".
+func checkLQueryQuantifier(q string) error {
+	if len(q) < 3 || q[len(q)-1] != '}' {
+		return fmt.Errorf("invalid quantifier %q", q)
+	}
+	bounds := strings.Split(q[1:len(q)-1], ",")
+	if len(bounds) > 2 || (len(bounds) == 1 && bounds[0] == "") || (len(bounds) == 2 && bounds[0] == "" && bounds[1] == "") {
+		return fmt.Errorf("invalid quantifier %q", q)
+	}
+	var n [2]int
+	for i, bound := range bounds {
+		if bound == "" {
+			continue
+		}
+		v, end, err := parseDigits([]byte(bound), 0, -1)
+		if err != nil || end != len(bound) || v > 0xffff {
+			return fmt.Errorf("invalid quantifier %q", q)
+		}
+		n[i] = v
+	}
+	if len(bounds) == 2 && bounds[0] != "" && bounds[1] != "" && n[0] > n[1] {
+		return fmt.Errorf("quantifier %q has a lower bound greater than its upper bound", q)
+	}
+	return nil
+}
+
+// LTXTQuery represents a value of the ltree extension's ltxtquery type, a
+// full-text-search-like pattern such as "Europe & Russia*@ & !Transportation".
+// Use a *LTXTQuery to scan nullable columns.
+type LTXTQuery struct {
+	Query string
+}
+
+// Scan implements the sql.Scanner interface.
+func (q *LTXTQuery) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		q.Query = string(src)
+		return nil
+	case string:
+		q.Query = src
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to LTXTQuery", src)
+}
+
+// Value implements the driver.Valuer interface. It returns an error if the
+// pattern is not valid ltxtquery syntax.
+func (q LTXTQuery) Value() (driver.Value, error) {
+	p := ltxtQueryParser{src: q.Query}
+	err := p.expr()
+	if err == nil {
+		if p.skipSpace(); p.pos < len(p.src) {
+			err = fmt.Errorf("unexpected %q at offset %d", p.src[p.pos], p.pos)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pg: invalid LTXTQuery %q: %w", q.Query, err)
+	}
+
+	return q.Query, nil
+}
+
+// ltxtQueryParser checks the syntax of an ltxtquery: words with optional
+// @, * and % modifiers combined with &, |, ! and parentheses.
+type ltxtQueryParser struct {
+	src string
+	pos int
+}
+
+func (p *ltxtQueryParser) skipSpace() {
+	for p.pos < len(p.src) && isTSSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *ltxtQueryParser) expr() error {
+	for {
+		if err := p.term(); err != nil {
+			return err
+		}
+		if p.skipSpace(); p.pos >= len(p.src) || (p.src[p.pos] != '&' && p.src[p.pos] != '|') {
+			return nil
+		}
+		p.pos++
+	}
+}
+
+func (p *ltxtQueryParser) term() error {
+	if p.skipSpace(); p.pos >= len(p.src) {
+		return fmt.Errorf("unexpected end of input")
+	}
+
+	switch c := p.src[p.pos]; {
+	case c == '!':
+		p.pos++
+		return p.term()
+	case c == '(':
+		p.pos++
+		if err := p.expr(); err != nil {
+			return err
+		}
+		if p.skipSpace(); p.pos >= len(p.src) || p.src[p.pos] != ')' {
+			return fmt.Errorf("expected %q at offset %d", ')', p.pos)
+		}
+		p.pos++
+		return nil
+	case c == '"':
+		start := p.pos
+		for p.pos++; p.pos < len(p.src) && p.src[p.pos] != '"'; p.pos++ {
+			if p.src[p.pos] == '\\' {
+				p.pos++
+			}
+		}
+		if p.pos >= len(p.src) {
+			return fmt.Errorf("unterminated quoted word at offset %d", start)
+		}
+		if p.pos == start+1 {
+			return fmt.Errorf("empty word at offset %d", start)
+		}
+		p.pos++
+	case isLTreeLabelChar(c):
+		for p.pos < len(p.src) && isLTreeLabelChar(p.src[p.pos]) {
+			p.pos++
+		}
+	default:
+		return fmt.Errorf("unexpected %q at offset %d", c, p.pos)
+	}
+
+	for p.pos < len(p.src) && strings.IndexByte("@*%", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	return nil
+}
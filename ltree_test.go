@@ -0,0 +1,87 @@
+package pg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLTreeScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(LTree), ``, ""},
+		{new(LTree), `Top`, ""},
+		{new(LTree), `Top.Science.Astronomy`, ""},
+		{new(LTree), `a_b.c-d.0`, ""},
+		{new(LTree), `Россия.Москва`, ""},
+	})
+}
+
+func TestLQueryScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(LQuery), `*.Science.!Astronomy|Physics.*{1,2}`, ""},
+		{new(LQuery), `Top.*.Astro*%@`, ""},
+		{new(LQuery), `*{,3}.a`, ""},
+		{new(LTXTQuery), `Europe & Russia*@ & !Transportation`, ""},
+		{new(LTXTQuery), `(a | b) & c`, ""},
+	})
+}
+
+func TestLTreeScan(t *testing.T) {
+	var tr LTree
+	if err := tr.Scan([]byte("Top.Наука.a b")); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Top", "Наука", "a b"}; !reflect.DeepEqual(tr.Labels, want) {
+		t.Fatalf("Scan = %q, want %q", tr.Labels, want)
+	}
+}
+
+func TestLTreeValueInvalid(t *testing.T) {
+	for _, labels := range [][]string{
+		{"a b"},
+		{"a", ""},
+		{"a.b"},
+		{strings.Repeat("a", maxLTreeLabel+1)},
+	} {
+		tr := LTree{Labels: labels}
+		if v, err := tr.Value(); err == nil {
+			t.Errorf("Value of %q = %q, want error", labels, v)
+		}
+	}
+
+	tr := LTree{Labels: []string{strings.Repeat("я", maxLTreeLabel)}}
+	if _, err := tr.Value(); err != nil {
+		t.Errorf("Value of a label of %d runes: %v", maxLTreeLabel, err)
+	}
+}
+
+func TestLQueryValueInvalid(t *testing.T) {
+	for _, s := range []string{
+		``,
+		`a.`,
+		`.a`,
+		`a..b`,
+		`a{2,1}`,
+		`a{`,
+		`a|`,
+		`a b`,
+	} {
+		if v, err := (LQuery{Query: s}).Value(); err == nil {
+			t.Errorf("Value of LQuery %q = %q, want error", s, v)
+		}
+	}
+}
+
+func TestLTXTQueryValueInvalid(t *testing.T) {
+	for _, s := range []string{
+		``,
+		`a &`,
+		`& a`,
+		`(a | b`,
+		`a | b)`,
+	} {
+		if v, err := (LTXTQuery{Query: s}).Value(); err == nil {
+			t.Errorf("Value of LTXTQuery %q = %q, want error", s, v)
+		}
+	}
+}
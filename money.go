@@ -0,0 +1,173 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MoneyFormat describes how the server formats money values, which depends
+// on its lc_monetary setting.
+type MoneyFormat struct {
+	// Symbol is the currency symbol, such as "$" or "€".
+	Symbol string
+	// SymbolAfter places the symbol after the amount, separated by a
+	// space, as in "1.234,56 €".
+	SymbolAfter bool
+	// DecimalSep separates the whole units from the minor units.
+	DecimalSep string
+	// GroupSep separates groups of thousands. It may be empty.
+	GroupSep string
+	// FracDigits is the number of minor unit digits.
+	FracDigits int
+}
+
+// DefaultMoneyFormat is the format of money values when lc_monetary is
+// en_US or C.
+var DefaultMoneyFormat = MoneyFormat{
+	Symbol:     "$",
+	DecimalSep: ".",
+	GroupSep:   ",",
+	FracDigits: 2,
+}
+
+// Money represents a PostgreSQL money value as an amount of minor units,
+// such as cents. Format describes the server's lc_monetary setting; if it is
+// nil DefaultMoneyFormat is used. Use a *Money to scan nullable columns.
+type Money struct {
+	Amount int64
+	Format *MoneyFormat
+}
+
+// Scan implements the sql.Scanner interface. The currency symbol, group
+// separators and a negative sign or parentheses around negative amounts are
+// accepted.
+func (m *Money) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return m.scanString(string(src))
+	case string:
+		return m.scanString(src)
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Money", src)
+}
+
+func (m *Money) scanString(src string) error {
+	amount, err := m.format().parse(src)
+	if err != nil {
+		return parseError("Money", src, -1, err)
+	}
+
+	m.Amount = amount
+	return nil
+}
+
+// Value implements the driver.Valuer interface. The amount is sent without
+// currency symbol and group separators.
+func (m Money) Value() (driver.Value, error) {
+	f := m.format()
+	return f.format(m.Amount, "", ""), nil
+}
+
+// String returns m the way the server formats it, such as "-$1,234.56".
+func (m Money) String() string {
+	f := m.format()
+	return f.format(m.Amount, f.Symbol, f.GroupSep)
+}
+
+func (m Money) format() *MoneyFormat {
+	if m.Format == nil {
+		return &DefaultMoneyFormat
+	}
+	return m.Format
+}
+
+func (f *MoneyFormat) parse(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	neg := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		neg = true
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	if strings.HasPrefix(s, "-") {
+		neg = true
+		s = strings.TrimSpace(s[1:])
+	}
+	if f.Symbol != "" {
+		s = strings.TrimSpace(strings.Replace(s, f.Symbol, "", 1))
+	}
+	if strings.HasPrefix(s, "-") {
+		neg = true
+		s = strings.TrimSpace(s[1:])
+	} else if strings.HasSuffix(s, "-") {
+		neg = true
+		s = strings.TrimSpace(s[:len(s)-1])
+	}
+	if f.GroupSep != "" {
+		s = strings.ReplaceAll(s, f.GroupSep, "")
+	}
+
+	whole, frac := s, ""
+	if f.DecimalSep != "" {
+		if i := strings.Index(s, f.DecimalSep); i >= 0 {
+			whole, frac = s[:i], s[i+len(f.DecimalSep):]
+		}
+	}
+	if len(frac) > f.FracDigits {
+		return 0, fmt.Errorf("more than %d fractional digits", f.FracDigits)
+	}
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("no digits")
+	}
+	digits := whole + frac + strings.Repeat("0", f.FracDigits-len(frac))
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return 0, fmt.Errorf("unexpected %q", digits[i])
+		}
+	}
+	if neg {
+		digits = "-" + digits
+	}
+
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("value out of range")
+	}
+	return amount, nil
+}
+
+func (f *MoneyFormat) format(amount int64, symbol, groupSep string) string {
+	digits := strconv.FormatUint(uint64(amount), 10)
+	if amount < 0 {
+		digits = strconv.FormatUint(uint64(-amount), 10)
+	}
+	if len(digits) <= f.FracDigits {
+		digits = strings.Repeat("0", f.FracDigits-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-f.FracDigits], digits[len(digits)-f.FracDigits:]
+
+	var b strings.Builder
+	if amount < 0 {
+		b.WriteByte('-')
+	}
+	if !f.SymbolAfter {
+		b.WriteString(symbol)
+	}
+	for i := 0; i < len(whole); i++ {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(groupSep)
+		}
+		b.WriteByte(whole[i])
+	}
+	if f.FracDigits > 0 {
+		b.WriteString(f.DecimalSep)
+		b.WriteString(frac)
+	}
+	if f.SymbolAfter && symbol != "" {
+		b.WriteByte(' ')
+		b.WriteString(symbol)
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package pg
+
+import (
+	"database/sql"
+	"testing"
+)
+
+var euroFormat = MoneyFormat{
+	Symbol:      "€",
+	SymbolAfter: true,
+	DecimalSep:  ",",
+	GroupSep:    ".",
+	FracDigits:  2,
+}
+
+func TestMoneyScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Money), `$1,234.56`, `1234.56`},
+		{new(Money), `-$1,234.56`, `-1234.56`},
+		{new(Money), `($1,234.56)`, `-1234.56`},
+		{new(Money), `$-1.50`, `-1.50`},
+		{new(Money), `$0.05`, `0.05`},
+		{new(Money), `12`, `12.00`},
+		{new(Money), `$92,233,720,368,547,758.07`, `92233720368547758.07`},
+		{new(Money), `-$92,233,720,368,547,758.08`, `-92233720368547758.08`},
+		{&Money{Format: &euroFormat}, `1.234,56 €`, `1234,56`},
+		{&Money{Format: &euroFormat}, `-1.234,56 €`, `-1234,56`},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(Money) },
+		``,
+		`$`,
+		`$1.234`,
+		`$1.2x`,
+		`$92,233,720,368,547,758.08`,
+	)
+	if err := new(Money).Scan(nil); err == nil {
+		t.Error("expected error scanning NULL into Money")
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	for _, tt := range []struct {
+		m    Money
+		want string
+	}{
+		{Money{Amount: 123456}, `$1,234.56`},
+		{Money{Amount: -123456}, `-$1,234.56`},
+		{Money{Amount: 5}, `$0.05`},
+		{Money{Amount: 0}, `$0.00`},
+		{Money{Amount: 123456789, Format: &euroFormat}, `1.234.567,89 €`},
+		{Money{Amount: 1234, Format: &MoneyFormat{Symbol: "¥"}}, `¥1234`},
+	} {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("String() of %d = %s, want %s", tt.m.Amount, got, tt.want)
+		}
+	}
+}
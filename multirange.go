@@ -0,0 +1,200 @@
+package pg
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Multirange represents a PostgreSQL multirange value, as a list of ranges
+// over bounds of type T. A nil Ranges is NULL, while an empty non-nil one is
+// the empty multirange {}.
+type Multirange[T any, C RangeCodec[T]] struct {
+	Ranges []Range[T, C]
+}
+
+// Int4Multirange represents a PostgreSQL int4multirange value.
+type Int4Multirange = Multirange[int32, Int4Codec]
+
+// Int8Multirange represents a PostgreSQL int8multirange value.
+type Int8Multirange = Multirange[int64, Int8Codec]
+
+// NumMultirange represents a PostgreSQL nummultirange value.
+type NumMultirange = Multirange[string, NumCodec]
+
+// TsMultirange represents a PostgreSQL tsmultirange value.
+type TsMultirange = Multirange[Timestamp, TsCodec]
+
+// TstzMultirange represents a PostgreSQL tstzmultirange value.
+type TstzMultirange = Multirange[Timestamp, TstzCodec]
+
+// DateMultirange represents a PostgreSQL datemultirange value.
+type DateMultirange = Multirange[Date, DateCodec]
+
+// Scan implements the sql.Scanner interface.
+func (m *Multirange[T, C]) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return m.scanBytes(src)
+	case string:
+		return m.scanBytes([]byte(src))
+	case nil:
+		m.Ranges = nil
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Multirange of %s", src, rangeTypeName[C]())
+}
+
+func (m *Multirange[T, C]) scanBytes(src []byte) error {
+	elems, err := parseMultirange(src)
+	if err != nil {
+		return err
+	}
+
+	ranges := make([]Range[T, C], 0, len(elems))
+	for _, elem := range elems {
+		var r Range[T, C]
+		if err := r.scanBytes(elem); err != nil {
+			return err
+		}
+		// The server drops empty ranges from multiranges.
+		if !r.Empty {
+			ranges = append(ranges, r)
+		}
+	}
+
+	m.Ranges = ranges
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (m Multirange[T, C]) Value() (driver.Value, error) {
+	if m.Ranges == nil {
+		return nil, nil
+	}
+
+	b := []byte{'{'}
+	n := 0
+	for _, r := range m.Ranges {
+		if r.Null {
+			return nil, fmt.Errorf("pg: Multirange of %s cannot contain a NULL range", rangeTypeName[C]())
+		}
+		if r.IsEmpty() {
+			continue
+		}
+		if n > 0 {
+			b = append(b, ',')
+		}
+		var err error
+		if b, err = r.appendText(b); err != nil {
+			return nil, err
+		}
+		n++
+	}
+
+	return string(append(b, '}')), nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. A multirange is
+// encoded as an array of ranges, leaving out empty ranges, and NULL as null.
+func (m Multirange[T, C]) MarshalJSON() ([]byte, error) {
+	if m.Ranges == nil {
+		return []byte("null"), nil
+	}
+	ranges := make([]Range[T, C], 0, len(m.Ranges))
+	for _, r := range m.Ranges {
+		if !r.IsEmpty() {
+			ranges = append(ranges, r)
+		}
+	}
+	return json.Marshal(ranges)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (m *Multirange[T, C]) UnmarshalJSON(data []byte) error {
+	var ranges []Range[T, C]
+	if err := json.Unmarshal(data, &ranges); err != nil {
+		return err
+	}
+
+	m.Ranges = ranges[:0]
+	for _, r := range ranges {
+		if !r.Empty {
+			m.Ranges = append(m.Ranges, r)
+		}
+	}
+	return nil
+}
+
+// parseMultirange splits the text representation of a multirange, such as
+// `{[1,3),[5,7)}`, into the text of its ranges.
+func parseMultirange(src []byte) ([][]byte, error) {
+	s := bytes.TrimSpace(src)
+	if len(s) < 2 || s[0] != '{' {
+		return nil, parseErrorf("multirange", s, 0, "expected %q", '{')
+	}
+
+	elems := [][]byte{}
+	i := skipSpace(s, 1)
+	if i < len(s) && s[i] == '}' {
+		if i != len(s)-1 {
+			return nil, parseErrorf("multirange", s, i+1, "unexpected %q", s[i+1])
+		}
+		return elems, nil
+	}
+
+	for {
+		start := i
+		if i < len(s) && (s[i] == '[' || s[i] == '(') {
+			var quoted bool
+		Range:
+			for i++; i < len(s); i++ {
+				switch s[i] {
+				case '\\':
+					i++
+				case '"':
+					quoted = !quoted
+				case ']', ')':
+					if !quoted {
+						break Range
+					}
+				}
+			}
+			if i >= len(s) {
+				return nil, parseErrorf("multirange", s, start, "unterminated range")
+			}
+			i++
+		} else if i+5 <= len(s) && bytes.EqualFold(s[i:i+5], []byte("empty")) {
+			i += 5
+		} else {
+			return nil, parseErrorf("multirange", s, i, "expected range")
+		}
+		elems = append(elems, s[start:i])
+
+		i = skipSpace(s, i)
+		if i >= len(s) {
+			return nil, parseErrorf("multirange", s, i, "expected %q", '}')
+		}
+		if s[i] == '}' {
+			break
+		}
+		if s[i] != ',' {
+			return nil, parseErrorf("multirange", s, i, "unexpected %q", s[i])
+		}
+		i = skipSpace(s, i+1)
+	}
+	if i != len(s)-1 {
+		return nil, parseErrorf("multirange", s, i+1, "unexpected %q", s[i+1])
+	}
+
+	return elems, nil
+}
+
+func skipSpace(s []byte, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r' || s[i] == '\v' || s[i] == '\f') {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,80 @@
+package pg
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMultirangeScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Int4Multirange), `{}`, ""},
+		{new(Int4Multirange), `{[1,3),[5,7)}`, ""},
+		{new(Int4Multirange), `{(,3),[10,)}`, ""},
+		{new(Int4Multirange), `{ [1,3) , [5,7) }`, `{[1,3),[5,7)}`},
+		{new(Int4Multirange), `{empty,[1,3)}`, `{[1,3)}`},
+		{new(NumMultirange), `{[1.5,2),(3,)}`, ""},
+		{new(DateMultirange), `{[2020-01-01,2020-02-01),[2021-01-01,infinity)}`, ""},
+		{new(TsMultirange), `{["2020-01-01 00:00:00","2020-01-02 00:00:00")}`, ""},
+	})
+}
+
+func TestMultirangeScan(t *testing.T) {
+	var m Int4Multirange
+	if err := m.Scan(`{[1,3),(,0)}`); err != nil {
+		t.Fatal(err)
+	}
+	want := []Int4Range{
+		{Lower: 1, Upper: 3, LowerInc: true},
+		{LowerInf: true, Upper: 0},
+	}
+	if !reflect.DeepEqual(m.Ranges, want) {
+		t.Fatalf("Scan = %+v, want %+v", m.Ranges, want)
+	}
+
+}
+
+func TestMultirangeNull(t *testing.T) {
+	testScanNull(t, new(Int4Multirange), new(DateMultirange))
+
+	for _, tt := range []struct {
+		m    Int4Multirange
+		json string
+	}{
+		{Int4Multirange{}, `null`},
+		{Int4Multirange{Ranges: []Int4Range{}}, `[]`},
+	} {
+		b, err := json.Marshal(tt.m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.json {
+			t.Errorf("Marshal(%+v) = %s, want %s", tt.m, b, tt.json)
+		}
+		var u Int4Multirange
+		if err := json.Unmarshal(b, &u); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(u, tt.m) {
+			t.Errorf("Unmarshal(%s) = %#v, want %#v", b, u, tt.m)
+		}
+	}
+
+	m := Int4Multirange{Ranges: []Int4Range{{Null: true}}}
+	if v, err := m.Value(); err == nil {
+		t.Fatalf("Value of a NULL range in a multirange = %v, want error", v)
+	}
+}
+
+func TestMultirangeScanInvalid(t *testing.T) {
+	testScanInvalid(t, func() sql.Scanner { return new(Int4Multirange) },
+		``,
+		`[1,3)`,
+		`{[1,3)`,
+		`{[1,3),}`,
+		`{[1,3) [5,7)}`,
+		`{[1,3)} x`,
+		`{[a,3)}`,
+	)
+}
@@ -0,0 +1,424 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// Inet represents a PostgreSQL inet value: a host address with an optional
+// netmask. An address without a netmask has a prefix of the full address
+// length. The zero value is NULL.
+type Inet struct {
+	Prefix netip.Prefix
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *Inet) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return n.scanString(string(src))
+	case string:
+		return n.scanString(src)
+	case nil:
+		n.Prefix = netip.Prefix{}
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Inet", src)
+}
+
+func (n *Inet) scanString(src string) error {
+	p, err := parseInet(src)
+	if err != nil {
+		return parseError("Inet", src, -1, err)
+	}
+
+	n.Prefix = p
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (n Inet) Value() (driver.Value, error) {
+	if !n.Prefix.IsValid() {
+		return nil, nil
+	}
+
+	return formatInet(n.Prefix), nil
+}
+
+// Addr returns the host address of n.
+func (n Inet) Addr() netip.Addr {
+	return n.Prefix.Addr()
+}
+
+// Family returns 4 for an IPv4 address and 6 for an IPv6 address, like the
+// server's family function, or 0 if n is NULL.
+func (n Inet) Family() int {
+	return prefixFamily(n.Prefix)
+}
+
+// ContainsIP reports whether ip is in the network of n, ignoring the host
+// bits of n.
+func (n Inet) ContainsIP(ip netip.Addr) bool {
+	return n.Prefix.Masked().Contains(ip)
+}
+
+// Overlaps reports whether the networks of n and o have any addresses in
+// common, like the server's && operator.
+func (n Inet) Overlaps(o Inet) bool {
+	return n.Prefix.Masked().Overlaps(o.Prefix.Masked())
+}
+
+// MarshalJSON implements the json.Marshaler interface. A non-NULL value is
+// encoded as a string in the server's output format.
+func (n Inet) MarshalJSON() ([]byte, error) {
+	return marshalJSONValue(n)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (n *Inet) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		n.Prefix = netip.Prefix{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return n.scanString(s)
+}
+
+// parseInet parses an address with an optional /bits netmask. Zones are not
+// supported by the server and are rejected.
+func parseInet(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		a, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		if a.Zone() != "" {
+			return netip.Prefix{}, fmt.Errorf("address %q has a zone", s)
+		}
+		return netip.PrefixFrom(a, a.BitLen()), nil
+	}
+
+	return netip.ParsePrefix(s)
+}
+
+// formatInet formats p the way the server does for inet, leaving out the
+// netmask if it covers the whole address.
+func formatInet(p netip.Prefix) string {
+	if p.Bits() == p.Addr().BitLen() {
+		return p.Addr().String()
+	}
+	return p.String()
+}
+
+// CIDR represents a PostgreSQL cidr value: a network address, which unlike
+// Inet cannot have bits set to the right of the netmask. The zero value is
+// NULL.
+type CIDR struct {
+	Prefix netip.Prefix
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *CIDR) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return n.scanString(string(src))
+	case string:
+		return n.scanString(src)
+	case nil:
+		n.Prefix = netip.Prefix{}
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to CIDR", src)
+}
+
+func (n *CIDR) scanString(src string) error {
+	p, err := parseInet(src)
+	if err == nil {
+		err = checkCIDR(p)
+	}
+	if err != nil {
+		return parseError("CIDR", src, -1, err)
+	}
+
+	n.Prefix = p
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (n CIDR) Value() (driver.Value, error) {
+	if !n.Prefix.IsValid() {
+		return nil, nil
+	}
+	if err := checkCIDR(n.Prefix); err != nil {
+		return nil, fmt.Errorf("pg: invalid CIDR: %w", err)
+	}
+
+	return n.Prefix.String(), nil
+}
+
+// Family returns 4 for an IPv4 network and 6 for an IPv6 network, or 0 if n
+// is NULL.
+func (n CIDR) Family() int {
+	return prefixFamily(n.Prefix)
+}
+
+// ContainsIP reports whether ip is in the network n.
+func (n CIDR) ContainsIP(ip netip.Addr) bool {
+	return n.Prefix.Contains(ip)
+}
+
+// Overlaps reports whether the networks n and o have any addresses in common.
+func (n CIDR) Overlaps(o CIDR) bool {
+	return n.Prefix.Overlaps(o.Prefix)
+}
+
+// MarshalJSON implements the json.Marshaler interface. A non-NULL value is
+// encoded as a string such as "10.0.0.0/8".
+func (n CIDR) MarshalJSON() ([]byte, error) {
+	return marshalJSONValue(n)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (n *CIDR) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		n.Prefix = netip.Prefix{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return n.scanString(s)
+}
+
+func prefixFamily(p netip.Prefix) int {
+	switch {
+	case !p.IsValid():
+		return 0
+	case p.Addr().Is4():
+		return 4
+	}
+	return 6
+}
+
+// checkCIDR checks that p has no bits set to the right of the netmask.
+func checkCIDR(p netip.Prefix) error {
+	if p.Masked() != p {
+		return fmt.Errorf("%s has bits set to right of mask", p)
+	}
+	return nil
+}
+
+// MacAddr represents a PostgreSQL macaddr value. Any of the input formats
+// accepted by the server can be scanned, such as "08:00:2b:01:02:03",
+// "08-00-2b-01-02-03", "08002b:010203" or "0800.2b01.0203"; values are sent
+// in the canonical colon-separated form. A nil Addr is NULL.
+type MacAddr struct {
+	Addr net.HardwareAddr
+}
+
+// Scan implements the sql.Scanner interface.
+func (m *MacAddr) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return m.scanString(string(src))
+	case string:
+		return m.scanString(src)
+	case nil:
+		m.Addr = nil
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to MacAddr", src)
+}
+
+func (m *MacAddr) scanString(src string) error {
+	addr, err := parseMacAddr(src)
+	if err == nil && len(addr) != 6 {
+		err = fmt.Errorf("expected 6 bytes, got %d", len(addr))
+	}
+	if err != nil {
+		return parseError("MacAddr", src, -1, err)
+	}
+
+	m.Addr = addr
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (m MacAddr) Value() (driver.Value, error) {
+	if m.Addr == nil {
+		return nil, nil
+	}
+	if len(m.Addr) != 6 {
+		return nil, fmt.Errorf("pg: invalid MacAddr: expected 6 bytes, got %d", len(m.Addr))
+	}
+
+	return m.Addr.String(), nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. A non-NULL value is
+// encoded as a string in colon-separated form.
+func (m MacAddr) MarshalJSON() ([]byte, error) {
+	return marshalJSONValue(m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (m *MacAddr) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		m.Addr = nil
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return m.scanString(s)
+}
+
+// parseMacAddr parses a MAC address written as pairs of hex digits, which may
+// be separated by ':', '-' or '.' between any two pairs as long as the same
+// separator is used throughout.
+func parseMacAddr(s string) (net.HardwareAddr, error) {
+	s = strings.TrimSpace(s)
+	addr := make(net.HardwareAddr, 0, 8)
+	var sep byte
+	for i := 0; i < len(s); {
+		if len(addr) > 0 && (s[i] == ':' || s[i] == '-' || s[i] == '.') {
+			if sep != 0 && s[i] != sep {
+				return nil, fmt.Errorf("unexpected %q at offset %d", s[i], i)
+			}
+			sep = s[i]
+			i++
+		}
+		if i+2 > len(s) {
+			return nil, fmt.Errorf("unexpected end of input")
+		}
+		hi, ok1 := unhex(s[i])
+		lo, ok2 := unhex(s[i+1])
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("invalid hex digit at offset %d", i)
+		}
+		if len(addr) == 8 {
+			return nil, fmt.Errorf("too many bytes")
+		}
+		addr = append(addr, hi<<4|lo)
+		i += 2
+	}
+	if len(addr) == 0 {
+		return nil, fmt.Errorf("unexpected end of input")
+	}
+	return addr, nil
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// MacAddr8 represents a PostgreSQL macaddr8 value, a MAC address in EUI-64
+// format. A 6-byte address is scanned the way the server stores it, with
+// ff:fe inserted after the third byte; the same expansion is applied when a
+// 6-byte Addr is sent. A nil Addr is NULL.
+type MacAddr8 struct {
+	Addr net.HardwareAddr
+}
+
+// Scan implements the sql.Scanner interface.
+func (m *MacAddr8) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return m.scanString(string(src))
+	case string:
+		return m.scanString(src)
+	case nil:
+		m.Addr = nil
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to MacAddr8", src)
+}
+
+func (m *MacAddr8) scanString(src string) error {
+	addr, err := parseMacAddr(src)
+	if err == nil {
+		addr, err = expandMacAddr8(addr)
+	}
+	if err != nil {
+		return parseError("MacAddr8", src, -1, err)
+	}
+
+	m.Addr = addr
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (m MacAddr8) Value() (driver.Value, error) {
+	if m.Addr == nil {
+		return nil, nil
+	}
+	addr, err := expandMacAddr8(m.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("pg: invalid MacAddr8: %w", err)
+	}
+
+	return addr.String(), nil
+}
+
+// expandMacAddr8 returns addr as an 8-byte EUI-64 address, converting a 6-byte
+// EUI-48 address by inserting ff:fe in the middle.
+func expandMacAddr8(addr net.HardwareAddr) (net.HardwareAddr, error) {
+	switch len(addr) {
+	case 8:
+		return addr, nil
+	case 6:
+		return net.HardwareAddr{addr[0], addr[1], addr[2], 0xff, 0xfe, addr[3], addr[4], addr[5]}, nil
+	}
+	return nil, fmt.Errorf("expected 6 or 8 bytes, got %d", len(addr))
+}
+
+// MarshalJSON implements the json.Marshaler interface. A non-NULL value is
+// encoded as a string in colon-separated form.
+func (m MacAddr8) MarshalJSON() ([]byte, error) {
+	return marshalJSONValue(m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (m *MacAddr8) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		m.Addr = nil
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return m.scanString(s)
+}
+
+// marshalJSONValue encodes the text value of v as a JSON string, or NULL as
+// null.
+func marshalJSONValue(v driver.Valuer) ([]byte, error) {
+	dv, err := v.Value()
+	if err != nil || dv == nil {
+		return []byte("null"), err
+	}
+	return json.Marshal(dv)
+}
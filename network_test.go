@@ -0,0 +1,142 @@
+package pg
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/netip"
+	"testing"
+)
+
+func TestInetScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Inet), `192.168.0.1`, ""},
+		{new(Inet), `192.168.0.1/24`, ""},
+		{new(Inet), `192.168.0.1/32`, `192.168.0.1`},
+		{new(Inet), `10.0.0.0/8`, ""},
+		{new(Inet), `::1`, ""},
+		{new(Inet), `2001:db8::1/64`, ""},
+		{new(Inet), `::ffff:1.2.3.4`, ""},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(Inet) },
+		``,
+		`192.168.0`,
+		`192.168.0.1/33`,
+		`host`,
+	)
+	testScanNull(t, new(Inet))
+}
+
+func TestCIDRScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(CIDR), `10.0.0.0/8`, ""},
+		{new(CIDR), `192.168.1.5/32`, ""},
+		{new(CIDR), `2001:db8::/32`, ""},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(CIDR) },
+		`192.168.1.5/24`,
+		`2001:db8::1/32`,
+	)
+	testScanNull(t, new(CIDR))
+}
+
+func TestMacAddrScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(MacAddr), `08:00:2b:01:02:03`, ""},
+		{new(MacAddr), `08-00-2B-01-02-03`, `08:00:2b:01:02:03`},
+		{new(MacAddr), `0800.2b01.0203`, `08:00:2b:01:02:03`},
+		{new(MacAddr), `08002b010203`, `08:00:2b:01:02:03`},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(MacAddr) },
+		``,
+		`08:00:2b:01:02`,
+		`08:00:2b:01:02:03:04:05`,
+		`08:00-2b:01:02:03`,
+		`08:00:2g:01:02:03`,
+	)
+	testScanNull(t, new(MacAddr))
+}
+
+func TestMacAddr8ScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(MacAddr8), `08:00:2b:01:02:03:04:05`, ""},
+		{new(MacAddr8), `08:00:2b:01:02:03`, `08:00:2b:ff:fe:01:02:03`},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(MacAddr8) },
+		`08:00:2b:01:02:03:04`,
+		`08:00:2b:01:02:03:04:05:06`,
+	)
+	testScanNull(t, new(MacAddr8))
+}
+
+func TestNetworkHelpers(t *testing.T) {
+	host := Inet{netip.MustParsePrefix("192.168.1.5/24")}
+	if got := host.Addr(); got != netip.MustParseAddr("192.168.1.5") {
+		t.Errorf("Addr() = %s", got)
+	}
+	if !host.ContainsIP(netip.MustParseAddr("192.168.1.200")) {
+		t.Error("192.168.1.5/24 should contain 192.168.1.200")
+	}
+	if host.ContainsIP(netip.MustParseAddr("192.168.2.1")) {
+		t.Error("192.168.1.5/24 should not contain 192.168.2.1")
+	}
+	if !host.Overlaps(Inet{netip.MustParsePrefix("192.168.0.0/16")}) {
+		t.Error("192.168.1.5/24 should overlap 192.168.0.0/16")
+	}
+
+	nw := CIDR{netip.MustParsePrefix("2001:db8::/32")}
+	if !nw.ContainsIP(netip.MustParseAddr("2001:db8::1")) {
+		t.Error("2001:db8::/32 should contain 2001:db8::1")
+	}
+	if nw.Overlaps(CIDR{netip.MustParsePrefix("2001:db9::/32")}) {
+		t.Error("2001:db8::/32 should not overlap 2001:db9::/32")
+	}
+
+	for _, tt := range []struct {
+		family int
+		want   int
+	}{
+		{host.Family(), 4},
+		{nw.Family(), 6},
+		{Inet{}.Family(), 0},
+		{CIDR{}.Family(), 0},
+	} {
+		if tt.family != tt.want {
+			t.Errorf("Family() = %d, want %d", tt.family, tt.want)
+		}
+	}
+}
+
+func TestNetworkJSON(t *testing.T) {
+	for _, tt := range []struct {
+		v interface {
+			json.Marshaler
+			json.Unmarshaler
+		}
+		data string
+	}{
+		{new(Inet), `"192.168.1.5/24"`},
+		{new(CIDR), `"10.0.0.0/8"`},
+		{new(MacAddr), `"08:00:2b:01:02:03"`},
+		{new(MacAddr8), `"08:00:2b:01:02:03:04:05"`},
+		{new(Inet), `null`},
+		{new(CIDR), `null`},
+		{new(MacAddr), `null`},
+		{new(MacAddr8), `null`},
+	} {
+		if err := tt.v.UnmarshalJSON([]byte(tt.data)); err != nil {
+			t.Errorf("%T: UnmarshalJSON(%s): %v", tt.v, tt.data, err)
+			continue
+		}
+		b, err := tt.v.MarshalJSON()
+		if err != nil || string(b) != tt.data {
+			t.Errorf("%T: MarshalJSON = %s, %v, want %s", tt.v, b, err, tt.data)
+		}
+	}
+
+	if err := new(CIDR).UnmarshalJSON([]byte(`"10.0.0.1/8"`)); err == nil {
+		t.Error("CIDR: expected error for host bits set")
+	}
+	if err := new(Inet).UnmarshalJSON([]byte(`1`)); err == nil {
+		t.Error("Inet: expected error for a number")
+	}
+}
@@ -0,0 +1,232 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Numeric represents a PostgreSQL numeric value without loss of precision or
+// scale, as the decimal Int * 10^Exp. The number 1.50 for example has an Int
+// of 150 and an Exp of -2. NaN and Inf represent the special values NaN and
+// ±Infinity, with Inf set to 1 or -1. The zero value, with a nil Int, is
+// NULL.
+type Numeric struct {
+	Int *big.Int
+	Exp int32
+	NaN bool
+	Inf int
+}
+
+// The limits of the numeric type: up to 131072 digits before the decimal
+// point and up to 16383 digits after it.
+const (
+	numericMaxWeight = 131072
+	numericMaxScale  = 16383
+)
+
+// ParseNumeric parses a decimal number with an optional exponent, such as
+// "-12.50" or "1.5e3", or one of the special values NaN, Infinity and
+// -Infinity. Numbers outside the range of the numeric type are rejected.
+func ParseNumeric(s string) (Numeric, error) {
+	src := s
+	s = strings.TrimSpace(s)
+	if !isNumeric(s) {
+		return Numeric{}, parseError("Numeric", src, -1, nil)
+	}
+
+	switch strings.ToLower(s) {
+	case "nan", "+nan", "-nan":
+		return Numeric{NaN: true}, nil
+	case "infinity", "inf", "+infinity", "+inf":
+		return Numeric{Inf: 1}, nil
+	case "-infinity", "-inf":
+		return Numeric{Inf: -1}, nil
+	}
+
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(s[i+1:], 10, 32); err != nil {
+			return Numeric{}, parseErrorf("Numeric", src, -1, "exponent out of range")
+		}
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		exp -= int64(len(s) - i - 1)
+		s = s[:i] + s[i+1:]
+	}
+	digits := len(strings.TrimLeft(strings.TrimLeft(s, "+-"), "0"))
+	if digits == 0 {
+		digits = 1
+	}
+	if -exp > numericMaxScale || int64(digits)+exp > numericMaxWeight {
+		return Numeric{}, parseErrorf("Numeric", src, -1, "value overflows numeric format")
+	}
+
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Numeric{}, parseError("Numeric", src, -1, nil)
+	}
+	return Numeric{Int: n, Exp: int32(exp)}, nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *Numeric) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return n.scanString(string(src))
+	case string:
+		return n.scanString(src)
+	case nil:
+		*n = Numeric{}
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Numeric", src)
+}
+
+func (n *Numeric) scanString(src string) error {
+	v, err := ParseNumeric(src)
+	if err != nil {
+		return err
+	}
+
+	*n = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (n Numeric) Value() (driver.Value, error) {
+	if n.IsNull() {
+		return nil, nil
+	}
+	if err := n.checkRange(); err != nil {
+		return nil, err
+	}
+
+	return n.String(), nil
+}
+
+// AppendValue appends the text of n to dst, like Value but without
+// allocating a string. A NULL value appends nothing.
+func (n Numeric) AppendValue(dst []byte) ([]byte, error) {
+	if n.IsNull() {
+		return dst, nil
+	}
+	if err := n.checkRange(); err != nil {
+		return dst, err
+	}
+	return n.appendText(dst), nil
+}
+
+// checkRange checks that the exponent of n is within the limits of the
+// numeric type, so that its text form has a bounded length.
+func (n Numeric) checkRange() error {
+	if n.Int != nil && (-int64(n.Exp) > numericMaxScale || int64(n.Exp) > numericMaxWeight) {
+		return fmt.Errorf("pg: Numeric exponent %d out of range", n.Exp)
+	}
+	return nil
+}
+
+// IsNull reports whether n is NULL.
+func (n Numeric) IsNull() bool {
+	return n.Int == nil && !n.NaN && n.Inf == 0
+}
+
+// String returns n as a decimal number keeping its scale, such as "1.50",
+// or as NaN, Infinity or -Infinity. NULL is returned as "NULL".
+func (n Numeric) String() string {
+	switch {
+	case n.NaN:
+		return "NaN"
+	case n.Inf > 0:
+		return "Infinity"
+	case n.Inf < 0:
+		return "-Infinity"
+	case n.Int == nil:
+		return "NULL"
+	}
+	return string(n.appendText(nil))
+}
+
+func (n Numeric) appendText(b []byte) []byte {
+	switch {
+	case n.NaN:
+		return append(b, "NaN"...)
+	case n.Inf > 0:
+		return append(b, "Infinity"...)
+	case n.Inf < 0:
+		return append(b, "-Infinity"...)
+	}
+
+	if n.Int.Sign() < 0 {
+		b = append(b, '-')
+	}
+	start := len(b)
+	b = new(big.Int).Abs(n.Int).Append(b, 10)
+	if n.Exp >= 0 {
+		if n.Int.Sign() != 0 {
+			for i := int32(0); i < n.Exp; i++ {
+				b = append(b, '0')
+			}
+		}
+		return b
+	}
+
+	// Pad with leading zeros so that there is a digit before the point,
+	// then insert the point.
+	scale := -int(n.Exp)
+	if pad := scale + 1 - (len(b) - start); pad > 0 {
+		b = append(b, make([]byte, pad)...)
+		copy(b[start+pad:], b[start:len(b)-pad])
+		for i := start; i < start+pad; i++ {
+			b[i] = '0'
+		}
+	}
+	b = append(b, 0)
+	point := len(b) - 1 - scale
+	copy(b[point+1:], b[point:len(b)-1])
+	b[point] = '.'
+	return b
+}
+
+// Rat returns n as a rational number. It returns nil for NULL, NaN and
+// infinite values.
+func (n Numeric) Rat() *big.Rat {
+	if n.Int == nil || n.NaN || n.Inf != 0 {
+		return nil
+	}
+
+	r := new(big.Rat).SetInt(n.Int)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(n.Exp))), nil)
+	if n.Exp >= 0 {
+		return r.Mul(r, new(big.Rat).SetInt(scale))
+	}
+	return r.Quo(r, new(big.Rat).SetInt(scale))
+}
+
+// Float64 returns the nearest float64 value of n, with NaN and infinite
+// values mapped to their float64 counterparts.
+func (n Numeric) Float64() (float64, error) {
+	switch {
+	case n.NaN:
+		return strconv.ParseFloat("NaN", 64)
+	case n.Inf != 0:
+		return strconv.ParseFloat(n.String(), 64)
+	case n.Int == nil:
+		return 0, fmt.Errorf("pg: cannot convert NULL Numeric to float64")
+	}
+
+	f, _ := n.Rat().Float64()
+	return f, nil
+}
+
+func abs32(v int32) int64 {
+	if v < 0 {
+		return -int64(v)
+	}
+	return int64(v)
+}
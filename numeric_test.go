@@ -0,0 +1,112 @@
+package pg
+
+import (
+	"database/sql"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestNumericScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Numeric), `0`, ""},
+		{new(Numeric), `1.50`, ""},
+		{new(Numeric), `-12.50`, ""},
+		{new(Numeric), `0.001`, ""},
+		{new(Numeric), `-0.5`, ""},
+		{new(Numeric), `.5`, `0.5`},
+		{new(Numeric), `+7`, `7`},
+		{new(Numeric), `1.5e3`, `1500`},
+		{new(Numeric), `15E-3`, `0.015`},
+		{new(Numeric), `123456789012345678901234567890.123456789`, ""},
+		{new(Numeric), `NaN`, ""},
+		{new(Numeric), `nan`, `NaN`},
+		{new(Numeric), `Infinity`, ""},
+		{new(Numeric), `-Infinity`, ""},
+		{new(Numeric), `inf`, `Infinity`},
+		{new(Numeric), ` 42 `, `42`},
+	})
+	testScanNull(t, new(Numeric))
+}
+
+func TestNumericScanInvalid(t *testing.T) {
+	testScanInvalid(t, func() sql.Scanner { return new(Numeric) },
+		``,
+		`abc`,
+		`1.2.3`,
+		`1e`,
+		`--1`,
+		`1e99999999999`,
+	)
+}
+
+func TestNumericLimits(t *testing.T) {
+	for _, s := range []string{
+		`1e-2147483648`,
+		`1e2147483647`,
+		`1e131072`,
+		`1e-16384`,
+		`0.` + strings.Repeat("0", numericMaxScale) + `1`,
+		`1` + strings.Repeat("0", numericMaxWeight),
+	} {
+		if _, err := ParseNumeric(s); err == nil {
+			t.Errorf("%.20s...: expected error", s)
+		}
+	}
+
+	for _, s := range []string{
+		`1e131071`,
+		`1e-16383`,
+		`0.` + strings.Repeat("0", numericMaxScale-1) + `1`,
+		`0` + strings.Repeat("0", numericMaxWeight) + `1`,
+	} {
+		n, err := ParseNumeric(s)
+		if err != nil {
+			t.Errorf("%.20s...: %v", s, err)
+			continue
+		}
+		if _, err := n.Value(); err != nil {
+			t.Errorf("%.20s...: Value: %v", s, err)
+		}
+	}
+
+	for _, exp := range []int32{math.MinInt32, math.MaxInt32} {
+		n := Numeric{Int: big.NewInt(1), Exp: exp}
+		if _, err := n.Value(); err == nil {
+			t.Errorf("Exp %d: expected error from Value", exp)
+		}
+	}
+}
+
+func TestNumericConversions(t *testing.T) {
+	n, err := ParseNumeric("-12.50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Int.Int64() != -1250 || n.Exp != -2 {
+		t.Errorf("got Int %s, Exp %d", n.Int, n.Exp)
+	}
+	if r := n.Rat(); r.Cmp(big.NewRat(-25, 2)) != 0 {
+		t.Errorf("Rat() = %s", r)
+	}
+	if f, err := n.Float64(); err != nil || f != -12.5 {
+		t.Errorf("Float64() = %v, %v", f, err)
+	}
+
+	if f, err := (Numeric{Inf: -1}).Float64(); err != nil || !math.IsInf(f, -1) {
+		t.Errorf("Float64() of -Infinity = %v, %v", f, err)
+	}
+	if f, err := (Numeric{NaN: true}).Float64(); err != nil || !math.IsNaN(f) {
+		t.Errorf("Float64() of NaN = %v, %v", f, err)
+	}
+	if (Numeric{NaN: true}).Rat() != nil {
+		t.Error("Rat() of NaN should be nil")
+	}
+	if _, err := (Numeric{}).Float64(); err == nil {
+		t.Error("expected error from Float64 of NULL")
+	}
+	if s := (Numeric{}).String(); s != "NULL" {
+		t.Errorf("String() of NULL = %s", s)
+	}
+}
@@ -0,0 +1,198 @@
+package pg
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// ParseOptions tunes how values are parsed by the types that have an
+// Options field. A nil *ParseOptions uses the defaults.
+type ParseOptions struct {
+	// ZeroCopy builds strings that share memory with the source bytes
+	// instead of copying them. It is only safe when the caller owns the
+	// source and never modifies it while the scanned values are in use.
+	// The []byte passed to a Scanner by database/sql is owned by the
+	// driver and reused for the next row, so ZeroCopy must not be set
+	// when scanning with database/sql directly, unless the driver is
+	// known to hand out fresh buffers.
+	ZeroCopy bool
+
+	// Intern, if set, is used to build the strings of elements, so that
+	// repeated values share one allocation. It takes precedence over
+	// ZeroCopy.
+	Intern Interner
+
+	// Strict parses arrays as the server formats them for output, where
+	// whitespace is never added: it is taken as part of an unquoted
+	// element and rejected elsewhere. By default whitespace around braces,
+	// delimiters and elements is skipped, as the server does for input,
+	// so that hand-written literals such as {1, 2} parse as well.
+	Strict bool
+
+	// MaxDepth, MaxElements and MaxElementBytes, if positive, limit the
+	// number of dimensions of an array, its number of elements and their
+	// total length in bytes, so that values from untrusted sources cannot
+	// make parsing use unbounded memory. Input exceeding a limit fails
+	// with an error matching ErrLimitExceeded.
+	MaxDepth        int
+	MaxElements     int
+	MaxElementBytes int
+
+	// Null is what the array types whose elements cannot be NULL, such
+	// as StringArray, do with NULL elements.
+	Null NullPolicy
+
+	// UTF8 is what the array types with string elements, such as
+	// StringArray, do with elements that are not valid UTF-8, as may be
+	// stored in a SQL_ASCII database.
+	UTF8 UTF8Policy
+}
+
+// NullPolicy is what to do with NULL elements of an array scanned into a
+// type that cannot represent them.
+type NullPolicy int
+
+const (
+	// NullError fails with an error matching ErrNullElement.
+	NullError NullPolicy = iota
+	// NullZero scans NULL elements as the zero value, such as "".
+	NullZero
+	// NullSkip leaves NULL elements out.
+	NullSkip
+)
+
+// Interner returns a string with the contents of b, reusing one returned
+// earlier for the same contents where possible. It must not retain b.
+type Interner interface {
+	Intern(b []byte) string
+}
+
+// StringInterner is an Interner remembering up to a fixed number of
+// distinct strings. Values seen once it is full are not remembered. It is
+// safe for concurrent use.
+type StringInterner struct {
+	mu      sync.Mutex
+	strings map[string]string
+	max     int
+}
+
+// NewStringInterner returns a StringInterner remembering up to max distinct
+// strings.
+func NewStringInterner(max int) *StringInterner {
+	return &StringInterner{strings: map[string]string{}, max: max}
+}
+
+// Intern implements the Interner interface.
+func (in *StringInterner) Intern(b []byte) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if s, ok := in.strings[string(b)]; ok {
+		return s
+	}
+	s := string(b)
+	if len(in.strings) < in.max {
+		in.strings[s] = s
+	}
+	return s
+}
+
+// UTF8Policy is what to do with string elements of an array that are not
+// valid UTF-8.
+type UTF8Policy int
+
+const (
+	// UTF8Keep keeps the elements as they are, without checking them.
+	UTF8Keep UTF8Policy = iota
+	// UTF8Error fails with an error matching ErrInvalidUTF8.
+	UTF8Error
+	// UTF8Replace replaces each invalid sequence with the Unicode
+	// replacement character U+FFFD.
+	UTF8Replace
+)
+
+// checkUTF8 returns an error for the first element of elems that is not
+// valid UTF-8 if the UTF-8 policy of o is UTF8Error.
+func (o *ParseOptions) checkUTF8(elems [][]byte) error {
+	if o == nil || o.UTF8 != UTF8Error {
+		return nil
+	}
+	for i, v := range elems {
+		if !utf8.Valid(v) {
+			return fmt.Errorf("%w at index %d", ErrInvalidUTF8, i)
+		}
+	}
+	return nil
+}
+
+// string returns b as a string, interned if Intern is set or sharing its
+// memory if ZeroCopy is set. Invalid UTF-8 is replaced first if the UTF-8
+// policy is UTF8Replace.
+func (o *ParseOptions) string(b []byte) string {
+	if o != nil && o.UTF8 == UTF8Replace && !utf8.Valid(b) {
+		b = bytes.ToValidUTF8(b, []byte(string(utf8.RuneError)))
+	}
+	if o != nil && o.Intern != nil {
+		return o.Intern.Intern(b)
+	}
+	if o != nil && o.ZeroCopy && len(b) > 0 {
+		return *(*string)(unsafe.Pointer(&b))
+	}
+	return string(b)
+}
+
+func (o *ParseOptions) strict() bool {
+	return o != nil && o.Strict
+}
+
+// nulls applies the NULL policy of o to the NULL elements of an array
+// scanned into typ. Unless the policy is NullZero, no NULL elements are
+// left in the result; NullSkip removes them from elems in place.
+func (o *ParseOptions) nulls(elems [][]byte, typ string) ([][]byte, error) {
+	policy := NullError
+	if o != nil {
+		policy = o.Null
+	}
+	if policy == NullZero {
+		return elems, nil
+	}
+
+	n := 0
+	for i, v := range elems {
+		if v == nil {
+			if policy != NullSkip {
+				return nil, fmt.Errorf("%w at index %d: cannot convert nil to %s", ErrNullElement, i, typ)
+			}
+			continue
+		}
+		elems[n] = v
+		n++
+	}
+	return elems[:n], nil
+}
+
+// checkDepth returns an error if an array of the type typ nested depth
+// levels deep exceeds the MaxDepth of o.
+func (o *ParseOptions) checkDepth(typ string, src []byte, offset, depth int) error {
+	if o != nil && o.MaxDepth > 0 && depth > o.MaxDepth {
+		return limitErrorf(typ, src, offset, "more than %d dimensions", o.MaxDepth)
+	}
+	return nil
+}
+
+// checkElems returns an error if n elements of an array of the type typ,
+// totalling size bytes, exceed the MaxElements or MaxElementBytes of o.
+func (o *ParseOptions) checkElems(typ string, src []byte, offset, n, size int) error {
+	if o == nil {
+		return nil
+	}
+	if o.MaxElements > 0 && n > o.MaxElements {
+		return limitErrorf(typ, src, offset, "more than %d elements", o.MaxElements)
+	}
+	if o.MaxElementBytes > 0 && size > o.MaxElementBytes {
+		return limitErrorf(typ, src, offset, "more than %d bytes of elements", o.MaxElementBytes)
+	}
+	return nil
+}
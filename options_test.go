@@ -0,0 +1,131 @@
+package pg
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+// stringData returns the address of the bytes of s.
+func stringData(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+func TestStringInterner(t *testing.T) {
+	// Strings of one byte are never allocated, so longer ones are used.
+	in := NewStringInterner(2)
+	a1, a2 := in.Intern([]byte("aa")), in.Intern([]byte("aa"))
+	if a1 != "aa" || stringData(a1) != stringData(a2) {
+		t.Error("repeated value was not interned")
+	}
+
+	// Once full, new values are not remembered.
+	in.Intern([]byte("b"))
+	c1, c2 := in.Intern([]byte("cc")), in.Intern([]byte("cc"))
+	if c1 != "cc" || stringData(c1) == stringData(c2) {
+		t.Error("value seen when full was remembered")
+	}
+	if len(in.strings) != 2 {
+		t.Errorf("remembered %d values, want 2", len(in.strings))
+	}
+
+	// The bytes passed in are not retained.
+	b := []byte("d")
+	d := NewStringInterner(1).Intern(b)
+	b[0] = 'x'
+	if d != "d" {
+		t.Errorf("interned string changed to %q with its source", d)
+	}
+}
+
+func TestStringInternerConcurrent(t *testing.T) {
+	in := NewStringInterner(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if s := in.Intern([]byte{'a' + byte(j%20)}); s != string(rune('a'+j%20)) {
+					t.Errorf("got %q", s)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if len(in.strings) != 10 {
+		t.Errorf("remembered %d values, want 10", len(in.strings))
+	}
+}
+
+func TestStringArrayIntern(t *testing.T) {
+	opts := &ParseOptions{Intern: NewStringInterner(10), ZeroCopy: true}
+	var a, b StringArray
+	a.Options, b.Options = opts, opts
+	src := []byte(`{xx,yy,xx}`)
+	if err := a.Scan(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Scan([]byte(`{yy,"xx"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if stringData(a.Strings[0]) != stringData(a.Strings[2]) || stringData(a.Strings[0]) != stringData(b.Strings[1]) {
+		t.Error("repeated elements were not interned")
+	}
+	if stringData(a.Strings[1]) != stringData(b.Strings[0]) {
+		t.Error("repeated elements of different arrays were not interned")
+	}
+
+	// Intern takes precedence over ZeroCopy.
+	src[1] = 'z'
+	if a.Strings[0] != "xx" {
+		t.Errorf("interned element shares memory with the source")
+	}
+}
+
+func TestUTF8Policy(t *testing.T) {
+	src := []byte("{ok,\"a\xffb\",\xc3}")
+	tests := []struct {
+		policy UTF8Policy
+		want   []string
+	}{
+		{UTF8Keep, []string{"ok", "a\xffb", "\xc3"}},
+		{UTF8Replace, []string{"ok", "a�b", "�"}},
+	}
+	for _, tt := range tests {
+		a := StringArray{Options: &ParseOptions{UTF8: tt.policy}}
+		if err := a.Scan(src); err != nil {
+			t.Errorf("Scan with UTF8Policy %d: %v", tt.policy, err)
+			continue
+		}
+		if !reflect.DeepEqual(a.Strings, tt.want) {
+			t.Errorf("Scan with UTF8Policy %d = %q, want %q", tt.policy, a.Strings, tt.want)
+		}
+	}
+
+	a := StringArray{Options: &ParseOptions{UTF8: UTF8Error}}
+	err := a.Scan(src)
+	if !errors.Is(err, ErrInvalidUTF8) || !strings.Contains(err.Error(), "index 1") {
+		t.Errorf("Scan with UTF8Error = %v, want %v at index 1", err, ErrInvalidUTF8)
+	}
+	if err := a.Scan([]byte(`{ok,é}`)); err != nil {
+		t.Errorf("Scan of valid UTF-8 with UTF8Error: %v", err)
+	}
+
+	// Replacement applies to interned strings as well.
+	a.Options = &ParseOptions{UTF8: UTF8Replace, Intern: NewStringInterner(10)}
+	if err := a.Scan(src); err != nil {
+		t.Fatal(err)
+	}
+	if a.Strings[1] != "a�b" {
+		t.Errorf("got %q, want the invalid byte replaced", a.Strings[1])
+	}
+
+	c := CitextArray{Options: &ParseOptions{UTF8: UTF8Error}}
+	if err := c.Scan(src); !errors.Is(err, ErrInvalidUTF8) {
+		t.Errorf("CitextArray Scan with UTF8Error = %v, want %v", err, ErrInvalidUTF8)
+	}
+}
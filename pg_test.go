@@ -0,0 +1,116 @@
+package pg
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+// scanValueTest is a value as printed by the server, scanned into scanner
+// and formatted again by its Value method.
+type scanValueTest struct {
+	scanner sql.Scanner
+	src     string
+	// want is the Value if it is not src.
+	want string
+}
+
+func testScanValue(t *testing.T, tests []scanValueTest) {
+	t.Helper()
+	for _, tt := range tests {
+		if err := tt.scanner.Scan([]byte(tt.src)); err != nil {
+			t.Errorf("%T: Scan(%q): %v", tt.scanner, tt.src, err)
+			continue
+		}
+		v, err := tt.scanner.(driver.Valuer).Value()
+		if err != nil {
+			t.Errorf("%T: Value of %q: %v", tt.scanner, tt.src, err)
+			continue
+		}
+		want := tt.want
+		if want == "" {
+			want = tt.src
+		}
+		if v != want {
+			t.Errorf("%T: Value of %q = %q, want %q", tt.scanner, tt.src, v, want)
+		}
+	}
+}
+
+// testScanInvalid checks that each of srcs fails to scan into the value
+// returned by scanner.
+func testScanInvalid(t *testing.T, scanner func() sql.Scanner, srcs ...string) {
+	t.Helper()
+	for _, src := range srcs {
+		s := scanner()
+		if err := s.Scan([]byte(src)); err == nil {
+			t.Errorf("%T: Scan(%q) = %+v, want error", s, src, s)
+		}
+	}
+}
+
+// testScanNull checks that scanning NULL into each of scanners gives a
+// value whose Value is NULL again.
+func testScanNull(t *testing.T, scanners ...sql.Scanner) {
+	t.Helper()
+	for _, s := range scanners {
+		if err := s.Scan(nil); err != nil {
+			t.Errorf("%T: Scan(nil): %v", s, err)
+			continue
+		}
+		if v, err := s.(driver.Valuer).Value(); err != nil || v != nil {
+			t.Errorf("%T: Value of NULL = %#v, %v, want nil", s, v, err)
+		}
+	}
+}
+
+func TestAppendValue(t *testing.T) {
+	tests := []scanValueTest{
+		{scanner: new(StringArray), src: `{a,"b c","NULL",""}`},
+		{scanner: new(CitextArray), src: `{Ab,"c,d"}`},
+		{scanner: new(ByteaArray), src: `{"\\x6869",NULL}`},
+		{scanner: new(ACLItemArray), src: `{=r/postgres,alice=arw/postgres}`},
+		{scanner: new(Bytea), src: `\x6869`},
+		{scanner: new(Numeric), src: "-12.340"},
+		{scanner: new(Numeric), src: "NaN"},
+		{scanner: new(Date), src: "2024-02-29"},
+		{scanner: new(Timestamp), src: "2024-02-29 12:34:56.789"},
+		{scanner: new(Time), src: "12:34:56"},
+		{scanner: new(TimeTz), src: "12:34:56+02"},
+		{scanner: new(Interval), src: "1 year 2 mons 3 days 04:05:06"},
+		{scanner: new(LSN), src: "16/B374D848"},
+		{scanner: new(Uint64), src: "18446744073709551615"},
+		{scanner: new(UUID), src: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+	}
+	prefix := []byte("prefix")
+	for _, tt := range tests {
+		if err := tt.scanner.Scan([]byte(tt.src)); err != nil {
+			t.Errorf("%T: Scan(%q): %v", tt.scanner, tt.src, err)
+			continue
+		}
+		v, err := tt.scanner.(driver.Valuer).Value()
+		if err != nil {
+			t.Errorf("%T: Value of %q: %v", tt.scanner, tt.src, err)
+			continue
+		}
+		b, err := tt.scanner.(valueAppender).AppendValue(prefix[:len(prefix):len(prefix)])
+		if err != nil {
+			t.Errorf("%T: AppendValue of %q: %v", tt.scanner, tt.src, err)
+			continue
+		}
+		if want := string(prefix) + v.(string); string(b) != want {
+			t.Errorf("%T: AppendValue of %q = %q, want %q", tt.scanner, tt.src, b, want)
+		}
+	}
+
+	// NULL appends nothing.
+	for _, s := range []sql.Scanner{new(StringArray), new(CitextArray), new(ByteaArray), new(ACLItemArray), new(Bytea), new(Numeric)} {
+		if err := s.Scan(nil); err != nil {
+			t.Errorf("%T: Scan(nil): %v", s, err)
+			continue
+		}
+		if b, err := s.(valueAppender).AppendValue(prefix); err != nil || string(b) != string(prefix) {
+			t.Errorf("%T: AppendValue of NULL = %q, %v, want %q", s, b, err, prefix)
+		}
+	}
+}
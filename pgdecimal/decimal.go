@@ -0,0 +1,121 @@
+// Package pgdecimal provides codecs mapping PostgreSQL numeric values onto
+// github.com/shopspring/decimal. It is a separate module so that the pg
+// module does not depend on the decimal package.
+package pgdecimal
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/onrik/pg"
+	"github.com/shopspring/decimal"
+)
+
+// NullDecimal represents a numeric value that may be NULL. NaN and infinite
+// values cannot be represented by decimal.Decimal and are rejected.
+type NullDecimal struct {
+	Decimal decimal.Decimal
+	Valid   bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (d *NullDecimal) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return d.scanString(string(src))
+	case string:
+		return d.scanString(src)
+	case nil:
+		*d = NullDecimal{}
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to NullDecimal", src)
+}
+
+func (d *NullDecimal) scanString(src string) error {
+	v, err := parseDecimal(src)
+	if err != nil {
+		return err
+	}
+
+	*d = NullDecimal{Decimal: v, Valid: true}
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (d NullDecimal) Value() (driver.Value, error) {
+	if !d.Valid {
+		return nil, nil
+	}
+
+	return d.Decimal.String(), nil
+}
+
+// DecimalArray represents a one-dimensional array of numeric values. NULL
+// elements cannot be represented and are rejected.
+type DecimalArray struct {
+	Decimals []decimal.Decimal
+}
+
+// Scan implements the sql.Scanner interface.
+func (a *DecimalArray) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return a.scanBytes(src)
+	case string:
+		return a.scanBytes([]byte(src))
+	case nil:
+		a.Decimals = nil
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to DecimalArray", src)
+}
+
+func (a *DecimalArray) scanBytes(src []byte) error {
+	elems, err := pg.ParseArray(src)
+	if err != nil {
+		return err
+	}
+
+	ds := make([]decimal.Decimal, len(elems))
+	for i, elem := range elems {
+		if elem == nil {
+			return fmt.Errorf("%w at index %d: cannot convert nil to decimal.Decimal", pg.ErrNullElement, i)
+		}
+		if ds[i], err = parseDecimal(string(elem)); err != nil {
+			return fmt.Errorf("pg: parsing array element index %d: %w", i, err)
+		}
+	}
+
+	a.Decimals = ds
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (a DecimalArray) Value() (driver.Value, error) {
+	if a.Decimals == nil {
+		return nil, nil
+	}
+
+	elems := make([][]byte, len(a.Decimals))
+	for i, d := range a.Decimals {
+		elems[i] = []byte(d.String())
+	}
+	return pg.FormatArray(elems), nil
+}
+
+// parseDecimal parses the text of a numeric value with pg.ParseNumeric,
+// keeping its scale.
+func parseDecimal(s string) (decimal.Decimal, error) {
+	n, err := pg.ParseNumeric(s)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	if n.NaN || n.Inf != 0 {
+		return decimal.Decimal{}, fmt.Errorf("pg: cannot convert %s to decimal.Decimal", n)
+	}
+
+	return decimal.NewFromBigInt(n.Int, n.Exp), nil
+}
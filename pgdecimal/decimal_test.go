@@ -0,0 +1,109 @@
+package pgdecimal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/onrik/pg"
+	"github.com/shopspring/decimal"
+)
+
+func TestNullDecimalScanValue(t *testing.T) {
+	for _, tt := range []struct {
+		src  string
+		want string
+	}{
+		{`0`, `0`},
+		{`1.50`, `1.5`},
+		{`-12.345`, `-12.345`},
+		{`1.5e3`, `1500`},
+		{`123456789012345678901234567890.1`, `123456789012345678901234567890.1`},
+	} {
+		var d NullDecimal
+		if err := d.Scan([]byte(tt.src)); err != nil {
+			t.Errorf("%s: %v", tt.src, err)
+			continue
+		}
+		if !d.Valid {
+			t.Errorf("%s: Valid = false", tt.src)
+		}
+		v, err := d.Value()
+		if err != nil || v != tt.want {
+			t.Errorf("%s: Value() = %v, %v, want %s", tt.src, v, err, tt.want)
+		}
+	}
+}
+
+func TestNullDecimalNull(t *testing.T) {
+	d := NullDecimal{Decimal: decimal.NewFromInt(1), Valid: true}
+	if err := d.Scan(nil); err != nil {
+		t.Fatal(err)
+	}
+	if d.Valid {
+		t.Error("Valid = true after scanning NULL")
+	}
+	if v, err := d.Value(); err != nil || v != nil {
+		t.Errorf("Value() = %v, %v, want nil", v, err)
+	}
+}
+
+func TestNullDecimalScanInvalid(t *testing.T) {
+	for _, src := range []interface{}{
+		[]byte(`abc`),
+		[]byte(`NaN`),
+		[]byte(`Infinity`),
+		`-Infinity`,
+		1.5,
+	} {
+		var d NullDecimal
+		if err := d.Scan(src); err == nil {
+			t.Errorf("%v: expected error", src)
+		}
+	}
+}
+
+func TestDecimalArray(t *testing.T) {
+	var a DecimalArray
+	if err := a.Scan([]byte(`{1.50,-2,3e2}`)); err != nil {
+		t.Fatal(err)
+	}
+	want := []decimal.Decimal{
+		decimal.New(150, -2),
+		decimal.New(-2, 0),
+		decimal.New(3, 2),
+	}
+	if len(a.Decimals) != len(want) {
+		t.Fatalf("got %v", a.Decimals)
+	}
+	for i := range want {
+		if !a.Decimals[i].Equal(want[i]) {
+			t.Errorf("index %d: got %s, want %s", i, a.Decimals[i], want[i])
+		}
+	}
+	v, err := a.Value()
+	if err != nil || v != `{1.5,-2,300}` {
+		t.Errorf("Value() = %s, %v", v, err)
+	}
+
+	if err := a.Scan(`{}`); err != nil || a.Decimals == nil || len(a.Decimals) != 0 {
+		t.Errorf("empty array: %v, %v", a.Decimals, err)
+	}
+	if err := a.Scan(nil); err != nil || a.Decimals != nil {
+		t.Errorf("NULL: %v, %v", a.Decimals, err)
+	}
+	if v, err := a.Value(); err != nil || v != nil {
+		t.Errorf("Value() of NULL = %v, %v", v, err)
+	}
+}
+
+func TestDecimalArrayInvalid(t *testing.T) {
+	var a DecimalArray
+	if err := a.Scan(`{1,NULL}`); !errors.Is(err, pg.ErrNullElement) {
+		t.Errorf("NULL element: got %v, want ErrNullElement", err)
+	}
+	for _, src := range []string{`{1,x}`, `{NaN}`, `{1`} {
+		if err := a.Scan(src); err == nil {
+			t.Errorf("%s: expected error", src)
+		}
+	}
+}
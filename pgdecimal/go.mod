@@ -0,0 +1,13 @@
+module github.com/onrik/pg/pgdecimal
+
+go 1.18
+
+require (
+	github.com/onrik/pg v0.0.0
+	github.com/shopspring/decimal v1.3.1
+)
+
+// Build against the pg module in the parent directory. When working on both
+// modules, "go work init . ./pgdecimal" in the repository root does the same
+// for the go command without editing this file.
+replace github.com/onrik/pg => ../
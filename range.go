@@ -0,0 +1,928 @@
+package pg
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RangeCodec converts the bounds of a Range to and from their text
+// representation. Implementations are usually empty structs.
+type RangeCodec[T any] interface {
+	// ParseBound parses the text representation of a bound.
+	ParseBound(src []byte) (T, error)
+	// AppendBound appends the text representation of v to b.
+	AppendBound(b []byte, v T) ([]byte, error)
+	// Compare returns -1, 0 or +1 depending on whether a is less than, equal
+	// to or greater than b.
+	Compare(a, b T) int
+}
+
+// DiscreteRangeCodec is implemented by codecs of discrete bound types. Range
+// values using such a codec are converted to the canonical [) form on Value,
+// as the server does for int4range, int8range and daterange.
+type DiscreteRangeCodec[T any] interface {
+	RangeCodec[T]
+	// Next returns the value following v.
+	Next(v T) (T, error)
+}
+
+// Range represents a PostgreSQL range value over bounds of type T, which are
+// converted by the codec C.
+//
+// Custom range types can be declared with their own codec:
+//
+//	type PriceRange = pg.Range[string, pg.NumCodec]
+//
+// The zero value of a bounded range such as Int4Range is empty, since (0,0)
+// contains no values. Set LowerInf and UpperInf for (,) or a range unbounded
+// on one side.
+type Range[T any, C RangeCodec[T]] struct {
+	// Lower and Upper are the bounds. They are ignored on an unbounded side
+	// and for the empty range.
+	Lower, Upper T
+	// LowerInc and UpperInc report whether the bounds are inclusive.
+	LowerInc, UpperInc bool
+	// LowerInf and UpperInf report whether a side is unbounded, like the
+	// lower_inf and upper_inf functions.
+	LowerInf, UpperInf bool
+	// Empty marks the empty range, written as `empty`.
+	Empty bool
+	// Null marks a SQL NULL: Scan sets it for a NULL column, and Value
+	// then returns nil, so that NULL is written back as NULL rather than
+	// as the empty range. The other fields are ignored.
+	Null bool
+}
+
+// Int4Range represents a PostgreSQL int4range value.
+type Int4Range = Range[int32, Int4Codec]
+
+// Int8Range represents a PostgreSQL int8range value.
+type Int8Range = Range[int64, Int8Codec]
+
+// NumRange represents a PostgreSQL numrange value. Bounds are kept in their
+// decimal text form, such as "1.50" or "Infinity", so that no precision is
+// lost.
+type NumRange = Range[string, NumCodec]
+
+// TsRange represents a PostgreSQL tsrange value. Bounds are in UTC. A bound
+// of infinity, such as the upper bound of ["2020-01-01",infinity), is kept
+// as a Timestamp with Infinite set, which differs from an unbounded side
+// like that of ["2020-01-01",), as on the server.
+type TsRange = Range[Timestamp, TsCodec]
+
+// TstzRange represents a PostgreSQL tstzrange value. Bounds keep the zone
+// offset they were reported with. A bound of infinity is kept as a
+// Timestamp with Infinite set.
+type TstzRange = Range[Timestamp, TstzCodec]
+
+// DateRange represents a PostgreSQL daterange value. A bound of infinity is
+// kept as a Date with Infinite set.
+type DateRange = Range[Date, DateCodec]
+
+// Scan implements the sql.Scanner interface.
+func (r *Range[T, C]) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return r.scanBytes(src)
+	case string:
+		return r.scanBytes([]byte(src))
+	case nil:
+		*r = Range[T, C]{Null: true}
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to %s", src, rangeTypeName[C]())
+}
+
+func (r *Range[T, C]) scanBytes(src []byte) error {
+	typ := rangeTypeName[C]()
+	lit, err := parseRange(src, typ)
+	if err != nil {
+		return err
+	}
+
+	var v Range[T, C]
+	if lit.empty {
+		v.Empty = true
+		*r = v
+		return nil
+	}
+
+	var c C
+	v.LowerInc, v.UpperInc = lit.lowerInc, lit.upperInc
+	if v.LowerInf = lit.lower == nil; !v.LowerInf {
+		if v.Lower, err = c.ParseBound(lit.lower); err != nil {
+			return fmt.Errorf("pg: parsing %s lower bound: %w", typ, err)
+		}
+	}
+	if v.UpperInf = lit.upper == nil; !v.UpperInf {
+		if v.Upper, err = c.ParseBound(lit.upper); err != nil {
+			return fmt.Errorf("pg: parsing %s upper bound: %w", typ, err)
+		}
+	}
+
+	*r = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (r Range[T, C]) Value() (driver.Value, error) {
+	if r.Null {
+		return nil, nil
+	}
+	b, err := r.appendText(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (r Range[T, C]) appendText(b []byte) ([]byte, error) {
+	r, err := r.Canonical()
+	if err != nil {
+		return nil, err
+	}
+	if r.Empty {
+		return appendRange(b, rangeLiteral{empty: true}), nil
+	}
+
+	var c C
+	lit := rangeLiteral{lowerInc: r.LowerInc, upperInc: r.UpperInc}
+	if !r.LowerInf {
+		if lit.lower, err = c.AppendBound(nil, r.Lower); err != nil {
+			return nil, fmt.Errorf("pg: %s lower bound: %w", rangeTypeName[C](), err)
+		}
+	}
+	if !r.UpperInf {
+		if lit.upper, err = c.AppendBound(nil, r.Upper); err != nil {
+			return nil, fmt.Errorf("pg: %s upper bound: %w", rangeTypeName[C](), err)
+		}
+	}
+
+	return appendRange(b, lit), nil
+}
+
+// Canonical returns r normalized the way the server stores it: ranges
+// without any values are marked empty, and ranges with a discrete codec,
+// such as Int4Range, Int8Range and DateRange, are converted to the [) form.
+// A bound with no next value, such as a date of infinity, is left as it is.
+// Ranges scanned from the database are already canonical, and a NULL range
+// is returned as it is.
+func (r Range[T, C]) Canonical() (Range[T, C], error) {
+	var c C
+	if r.Null {
+		return r, nil
+	}
+	if r.Empty {
+		return Range[T, C]{Empty: true}, nil
+	}
+	if r.LowerInf {
+		r.Lower, r.LowerInc = *new(T), false
+	}
+	if r.UpperInf {
+		r.Upper, r.UpperInc = *new(T), false
+	}
+	if !r.LowerInf && !r.UpperInf {
+		switch cmp := c.Compare(r.Lower, r.Upper); {
+		case cmp > 0:
+			return r, fmt.Errorf("pg: %s lower bound must be less than or equal to upper bound", rangeTypeName[C]())
+		case cmp == 0 && !(r.LowerInc && r.UpperInc):
+			return Range[T, C]{Empty: true}, nil
+		}
+	}
+
+	d, ok := interface{}(c).(DiscreteRangeCodec[T])
+	if !ok {
+		return r, nil
+	}
+	if !r.LowerInf && !r.LowerInc {
+		next, err := d.Next(r.Lower)
+		if err != nil {
+			return r, fmt.Errorf("pg: %s lower bound: %w", rangeTypeName[C](), err)
+		}
+		if c.Compare(next, r.Lower) != 0 {
+			r.Lower, r.LowerInc = next, true
+		}
+	}
+	if !r.UpperInf && r.UpperInc {
+		next, err := d.Next(r.Upper)
+		if err != nil {
+			return r, fmt.Errorf("pg: %s upper bound: %w", rangeTypeName[C](), err)
+		}
+		if c.Compare(next, r.Upper) != 0 {
+			r.Upper, r.UpperInc = next, false
+		}
+	}
+	if !r.LowerInf && !r.UpperInf && c.Compare(r.Lower, r.Upper) >= 0 {
+		return Range[T, C]{Empty: true}, nil
+	}
+	return r, nil
+}
+
+// IsEmpty reports whether r contains no values, like the isempty function.
+// This is the case if Empty is set or if the bounds leave no values in
+// between, such as [5,5).
+func (r Range[T, C]) IsEmpty() bool {
+	return r.normalized().Empty
+}
+
+// Equal reports whether r and o contain the same values, comparing their
+// canonical forms like the = operator.
+func (r Range[T, C]) Equal(o Range[T, C]) bool {
+	r, o = r.normalized(), o.normalized()
+	if r.Empty || o.Empty {
+		return r.Empty == o.Empty
+	}
+	return compareRangeBounds(r.lowerBound(), o.lowerBound()) == 0 &&
+		compareRangeBounds(r.upperBound(), o.upperBound()) == 0
+}
+
+// Contains reports whether v is within r, like the @> operator.
+func (r Range[T, C]) Contains(v T) bool {
+	r = r.normalized()
+	if r.Empty {
+		return false
+	}
+
+	var c C
+	if !r.LowerInf {
+		if cmp := c.Compare(r.Lower, v); cmp > 0 || (cmp == 0 && !r.LowerInc) {
+			return false
+		}
+	}
+	if !r.UpperInf {
+		if cmp := c.Compare(r.Upper, v); cmp < 0 || (cmp == 0 && !r.UpperInc) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsRange reports whether o is within r, like the @> operator. Every
+// range contains the empty range.
+func (r Range[T, C]) ContainsRange(o Range[T, C]) bool {
+	r, o = r.normalized(), o.normalized()
+	if o.Empty {
+		return true
+	}
+	if r.Empty {
+		return false
+	}
+	return compareRangeBounds(r.lowerBound(), o.lowerBound()) <= 0 &&
+		compareRangeBounds(r.upperBound(), o.upperBound()) >= 0
+}
+
+// Overlaps reports whether r and o have values in common, like the &&
+// operator.
+func (r Range[T, C]) Overlaps(o Range[T, C]) bool {
+	r, o = r.normalized(), o.normalized()
+	if r.Empty || o.Empty {
+		return false
+	}
+	return compareRangeBounds(r.lowerBound(), o.upperBound()) <= 0 &&
+		compareRangeBounds(o.lowerBound(), r.upperBound()) <= 0
+}
+
+// Union returns the smallest range containing both r and o, like the +
+// operator. As on the server, it is an error if r and o neither overlap nor
+// are adjacent.
+func (r Range[T, C]) Union(o Range[T, C]) (Range[T, C], error) {
+	r, o = r.normalized(), o.normalized()
+	if r.Empty {
+		return o, nil
+	}
+	if o.Empty {
+		return r, nil
+	}
+	if !r.Overlaps(o) && !r.adjacent(o) && !o.adjacent(r) {
+		return Range[T, C]{}, fmt.Errorf("pg: result of %s union would not be contiguous", rangeTypeName[C]())
+	}
+
+	lower, upper := r.lowerBound(), r.upperBound()
+	if compareRangeBounds(o.lowerBound(), lower) < 0 {
+		lower = o.lowerBound()
+	}
+	if compareRangeBounds(o.upperBound(), upper) > 0 {
+		upper = o.upperBound()
+	}
+	return newRange[T, C](lower, upper), nil
+}
+
+// Intersect returns the range of values in both r and o, like the *
+// operator.
+func (r Range[T, C]) Intersect(o Range[T, C]) Range[T, C] {
+	r, o = r.normalized(), o.normalized()
+	if !r.Overlaps(o) {
+		return Range[T, C]{Empty: true}
+	}
+
+	lower, upper := r.lowerBound(), r.upperBound()
+	if compareRangeBounds(o.lowerBound(), lower) > 0 {
+		lower = o.lowerBound()
+	}
+	if compareRangeBounds(o.upperBound(), upper) < 0 {
+		upper = o.upperBound()
+	}
+	return newRange[T, C](lower, upper).normalized()
+}
+
+// adjacent reports whether the upper bound of r meets the lower bound of o
+// with no values in between.
+func (r Range[T, C]) adjacent(o Range[T, C]) bool {
+	if r.UpperInf || o.LowerInf {
+		return false
+	}
+	var c C
+	return c.Compare(r.Upper, o.Lower) == 0 && r.UpperInc != o.LowerInc
+}
+
+// normalized returns the canonical form of r, or r itself if it cannot be
+// canonicalized.
+func (r Range[T, C]) normalized() Range[T, C] {
+	if n, err := r.Canonical(); err == nil {
+		return n
+	}
+	return r
+}
+
+func (r Range[T, C]) lowerBound() rangeBound[T, C] {
+	return rangeBound[T, C]{value: r.Lower, inc: r.LowerInc, inf: r.LowerInf, lower: true}
+}
+
+func (r Range[T, C]) upperBound() rangeBound[T, C] {
+	return rangeBound[T, C]{value: r.Upper, inc: r.UpperInc, inf: r.UpperInf}
+}
+
+// rangeBound is one side of a range.
+type rangeBound[T any, C RangeCodec[T]] struct {
+	value T
+	inc   bool
+	inf   bool
+	lower bool
+}
+
+func newRange[T any, C RangeCodec[T]](lower, upper rangeBound[T, C]) Range[T, C] {
+	return Range[T, C]{
+		Lower:    lower.value,
+		Upper:    upper.value,
+		LowerInc: lower.inc,
+		UpperInc: upper.inc,
+		LowerInf: lower.inf,
+		UpperInf: upper.inf,
+	}
+}
+
+// compareRangeBounds compares two range bounds, which may be lower or upper
+// bounds, in the same way as the server's range_cmp_bounds.
+func compareRangeBounds[T any, C RangeCodec[T]](a, b rangeBound[T, C]) int {
+	if a.inf && b.inf {
+		if a.lower == b.lower {
+			return 0
+		}
+	}
+	if a.inf {
+		if a.lower {
+			return -1
+		}
+		return 1
+	}
+	if b.inf {
+		if b.lower {
+			return 1
+		}
+		return -1
+	}
+
+	var c C
+	cmp := c.Compare(a.value, b.value)
+	if cmp != 0 {
+		return cmp
+	}
+	switch {
+	case !a.inc && !b.inc:
+		if a.lower == b.lower {
+			return 0
+		}
+		if a.lower {
+			return 1
+		}
+		return -1
+	case !a.inc:
+		if a.lower {
+			return 1
+		}
+		return -1
+	case !b.inc:
+		if b.lower {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// rangeJSON is the JSON representation of a range. Unbounded sides have a
+// null bound and Bounds holds the inclusivity brackets, such as "[)".
+type rangeJSON struct {
+	Lower  json.RawMessage `json:"lower"`
+	Upper  json.RawMessage `json:"upper"`
+	Bounds string          `json:"bounds,omitempty"`
+	Empty  bool            `json:"empty,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. A range is encoded as
+// {"lower":1,"upper":10,"bounds":"[)"}, with null for an unbounded side, and
+// the empty range as {"empty":true}. A NULL range is encoded as null.
+func (r Range[T, C]) MarshalJSON() ([]byte, error) {
+	if r.Null {
+		return []byte("null"), nil
+	}
+	r = r.normalized()
+	if r.Empty {
+		return []byte(`{"empty":true}`), nil
+	}
+
+	v := rangeJSON{
+		Lower:  json.RawMessage("null"),
+		Upper:  json.RawMessage("null"),
+		Bounds: "()",
+	}
+	var err error
+	if !r.LowerInf {
+		if v.Lower, err = json.Marshal(r.Lower); err != nil {
+			return nil, err
+		}
+		if r.LowerInc {
+			v.Bounds = "[" + v.Bounds[1:]
+		}
+	}
+	if !r.UpperInf {
+		if v.Upper, err = json.Marshal(r.Upper); err != nil {
+			return nil, err
+		}
+		if r.UpperInc {
+			v.Bounds = v.Bounds[:1] + "]"
+		}
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Bounds defaults
+// to "[)" when omitted, and null is a NULL range.
+func (r *Range[T, C]) UnmarshalJSON(data []byte) error {
+	if jsonNullState(data) == JSONNull {
+		*r = Range[T, C]{Null: true}
+		return nil
+	}
+	var v rangeJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Empty {
+		*r = Range[T, C]{Empty: true}
+		return nil
+	}
+
+	switch v.Bounds {
+	case "":
+		v.Bounds = "[)"
+	case "[)", "[]", "(]", "()":
+	default:
+		return fmt.Errorf("pg: invalid %s bounds %q", rangeTypeName[C](), v.Bounds)
+	}
+
+	n := Range[T, C]{
+		LowerInc: v.Bounds[0] == '[',
+		UpperInc: v.Bounds[1] == ']',
+	}
+	if n.LowerInf = isJSONNull(v.Lower); n.LowerInf {
+		n.LowerInc = false
+	} else if err := json.Unmarshal(v.Lower, &n.Lower); err != nil {
+		return err
+	}
+	if n.UpperInf = isJSONNull(v.Upper); n.UpperInf {
+		n.UpperInc = false
+	} else if err := json.Unmarshal(v.Upper, &n.Upper); err != nil {
+		return err
+	}
+
+	*r = n
+	return nil
+}
+
+func isJSONNull(v json.RawMessage) bool {
+	return len(v) == 0 || jsonNullState(v) == JSONNull
+}
+
+// rangeTypeName returns the name used for ranges using C in error messages.
+func rangeTypeName[C any]() string {
+	var c C
+	if n, ok := interface{}(c).(interface{ rangeType() string }); ok {
+		return n.rangeType()
+	}
+	return fmt.Sprintf("Range[%T]", c)
+}
+
+// Int4Codec is the RangeCodec of int4range bounds.
+type Int4Codec struct{}
+
+func (Int4Codec) rangeType() string { return "Int4Range" }
+
+// ParseBound implements the RangeCodec interface.
+func (Int4Codec) ParseBound(src []byte) (int32, error) {
+	n, err := strconv.ParseInt(string(bytes.TrimSpace(src)), 10, 32)
+	return int32(n), err
+}
+
+// AppendBound implements the RangeCodec interface.
+func (Int4Codec) AppendBound(b []byte, v int32) ([]byte, error) {
+	return strconv.AppendInt(b, int64(v), 10), nil
+}
+
+// Compare implements the RangeCodec interface.
+func (Int4Codec) Compare(a, b int32) int {
+	return compareInt(int64(a), int64(b))
+}
+
+// Next implements the DiscreteRangeCodec interface.
+func (Int4Codec) Next(v int32) (int32, error) {
+	if v == math.MaxInt32 {
+		return 0, fmt.Errorf("value %d out of range", v)
+	}
+	return v + 1, nil
+}
+
+// Int8Codec is the RangeCodec of int8range bounds.
+type Int8Codec struct{}
+
+func (Int8Codec) rangeType() string { return "Int8Range" }
+
+// ParseBound implements the RangeCodec interface.
+func (Int8Codec) ParseBound(src []byte) (int64, error) {
+	return strconv.ParseInt(string(bytes.TrimSpace(src)), 10, 64)
+}
+
+// AppendBound implements the RangeCodec interface.
+func (Int8Codec) AppendBound(b []byte, v int64) ([]byte, error) {
+	return strconv.AppendInt(b, v, 10), nil
+}
+
+// Compare implements the RangeCodec interface.
+func (Int8Codec) Compare(a, b int64) int {
+	return compareInt(a, b)
+}
+
+// Next implements the DiscreteRangeCodec interface.
+func (Int8Codec) Next(v int64) (int64, error) {
+	if v == math.MaxInt64 {
+		return 0, fmt.Errorf("value %d out of range", v)
+	}
+	return v + 1, nil
+}
+
+// NumCodec is the RangeCodec of numrange bounds.
+type NumCodec struct{}
+
+func (NumCodec) rangeType() string { return "NumRange" }
+
+// ParseBound implements the RangeCodec interface.
+func (NumCodec) ParseBound(src []byte) (string, error) {
+	s := string(bytes.TrimSpace(src))
+	if !isNumeric(s) {
+		return "", fmt.Errorf("invalid numeric %q", src)
+	}
+	return s, nil
+}
+
+// AppendBound implements the RangeCodec interface.
+func (NumCodec) AppendBound(b []byte, v string) ([]byte, error) {
+	if !isNumeric(v) {
+		return nil, fmt.Errorf("invalid numeric %q", v)
+	}
+	return append(b, v...), nil
+}
+
+// Compare implements the RangeCodec interface. Invalid numbers compare
+// as zero.
+func (NumCodec) Compare(a, b string) int {
+	return compareNumeric(a, b)
+}
+
+// TsCodec is the RangeCodec of tsrange bounds.
+type TsCodec struct{}
+
+func (TsCodec) rangeType() string { return "TsRange" }
+
+// ParseBound implements the RangeCodec interface.
+func (TsCodec) ParseBound(src []byte) (Timestamp, error) {
+	var t Timestamp
+	err := t.scanBytes(src)
+	return t, err
+}
+
+// AppendBound implements the RangeCodec interface. A finite bound is sent as
+// the local timestamp of its own location, without a zone offset.
+func (TsCodec) AppendBound(b []byte, v Timestamp) ([]byte, error) {
+	if v.Infinite != 0 {
+		return v.AppendValue(b)
+	}
+	return appendTimestamp(b, v.Time, false), nil
+}
+
+// Compare implements the RangeCodec interface. Finite bounds are compared by
+// their local timestamps.
+func (TsCodec) Compare(a, b Timestamp) int {
+	if a.Infinite != 0 || b.Infinite != 0 {
+		return compareInt(int64(a.Infinite), int64(b.Infinite))
+	}
+	wall := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+	return compareTime(wall(a.Time), wall(b.Time))
+}
+
+// TstzCodec is the RangeCodec of tstzrange bounds.
+type TstzCodec struct{}
+
+func (TstzCodec) rangeType() string { return "TstzRange" }
+
+// ParseBound implements the RangeCodec interface.
+func (TstzCodec) ParseBound(src []byte) (Timestamp, error) {
+	var t Timestamp
+	err := t.scanBytes(src)
+	return t, err
+}
+
+// AppendBound implements the RangeCodec interface.
+func (TstzCodec) AppendBound(b []byte, v Timestamp) ([]byte, error) {
+	return v.AppendValue(b)
+}
+
+// Compare implements the RangeCodec interface.
+func (TstzCodec) Compare(a, b Timestamp) int {
+	if a.Infinite != 0 || b.Infinite != 0 {
+		return compareInt(int64(a.Infinite), int64(b.Infinite))
+	}
+	return compareTime(a.Time, b.Time)
+}
+
+// DateCodec is the RangeCodec of daterange bounds.
+type DateCodec struct{}
+
+func (DateCodec) rangeType() string { return "DateRange" }
+
+// ParseBound implements the RangeCodec interface.
+func (DateCodec) ParseBound(src []byte) (Date, error) {
+	var d Date
+	err := d.scanBytes(src)
+	return d, err
+}
+
+// AppendBound implements the RangeCodec interface.
+func (DateCodec) AppendBound(b []byte, v Date) ([]byte, error) {
+	return v.AppendValue(b)
+}
+
+// Compare implements the RangeCodec interface.
+func (DateCodec) Compare(a, b Date) int {
+	if a.Infinite != 0 || b.Infinite != 0 {
+		return compareInt(int64(a.Infinite), int64(b.Infinite))
+	}
+	return compareTime(a.Time(time.UTC), b.Time(time.UTC))
+}
+
+// Next implements the DiscreteRangeCodec interface. As on the server, the
+// next value of infinity or -infinity is itself.
+func (DateCodec) Next(v Date) (Date, error) {
+	if v.Infinite != 0 {
+		return v, nil
+	}
+	return DateOf(v.Time(time.UTC).AddDate(0, 0, 1)), nil
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// compareNumeric compares two numeric values in text form. As on the
+// server, NaN sorts after every other value, including Infinity.
+func compareNumeric(a, b string) int {
+	rank := func(s string) (int, *big.Rat) {
+		switch strings.ToLower(strings.TrimLeft(s, "+")) {
+		case "nan":
+			return 2, nil
+		case "infinity", "inf":
+			return 1, nil
+		case "-infinity", "-inf":
+			return -1, nil
+		}
+		r, _ := new(big.Rat).SetString(s)
+		if r == nil {
+			r = new(big.Rat)
+		}
+		return 0, r
+	}
+
+	ra, va := rank(a)
+	rb, vb := rank(b)
+	if ra != rb || ra != 0 {
+		return compareInt(int64(ra), int64(rb))
+	}
+	return va.Cmp(vb)
+}
+
+// isNumeric reports whether s is valid numeric input: a decimal number with
+// an optional exponent, Infinity or NaN.
+func isNumeric(s string) bool {
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	switch strings.ToLower(s) {
+	case "infinity", "inf", "nan":
+		return true
+	}
+
+	var digits int
+	i := 0
+	for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		digits++
+	}
+	if i < len(s) && s[i] == '.' {
+		for i++; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			digits++
+		}
+	}
+	if digits == 0 {
+		return false
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		start := i
+		for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		}
+		if i == start {
+			return false
+		}
+	}
+
+	return i == len(s)
+}
+
+// rangeLiteral holds the parts of a range literal. A nil bound is unbounded.
+type rangeLiteral struct {
+	lower, upper       []byte
+	lowerInc, upperInc bool
+	empty              bool
+}
+
+// parseRange parses the text representation of a range, such as `[1,10)`,
+// `(,5]` or `empty`, following the rules of the server's range_in.
+func parseRange(src []byte, typ string) (lit rangeLiteral, err error) {
+	s := bytes.TrimSpace(src)
+	if bytes.EqualFold(s, []byte("empty")) {
+		return rangeLiteral{empty: true}, nil
+	}
+	if len(s) < 3 {
+		return lit, parseError(typ, src, -1, nil)
+	}
+
+	switch s[0] {
+	case '[':
+		lit.lowerInc = true
+	case '(':
+	default:
+		return lit, parseErrorf(typ, s, 0, "expected %q or %q", '[', '(')
+	}
+
+	i := 1
+	if lit.lower, i, err = parseRangeBound(s, i, typ); err != nil {
+		return lit, err
+	}
+	if i >= len(s) || s[i] != ',' {
+		return lit, parseErrorf(typ, s, i, "expected %q", ',')
+	}
+	if lit.upper, i, err = parseRangeBound(s, i+1, typ); err != nil {
+		return lit, err
+	}
+	switch {
+	case i < len(s) && s[i] == ']':
+		lit.upperInc = true
+	case i < len(s) && s[i] == ')':
+	default:
+		return lit, parseErrorf(typ, s, i, "expected %q or %q", ']', ')')
+	}
+	if i != len(s)-1 {
+		return lit, parseErrorf(typ, s, i+1, "unexpected %q", s[i+1])
+	}
+
+	// The server never reports an unbounded side as inclusive.
+	if lit.lower == nil {
+		lit.lowerInc = false
+	}
+	if lit.upper == nil {
+		lit.upperInc = false
+	}
+	return lit, nil
+}
+
+// parseRangeBound parses one bound starting at offset i and returns it along
+// with the offset of the byte following it. An empty unquoted bound is
+// reported as nil.
+func parseRangeBound(s []byte, i int, typ string) ([]byte, int, error) {
+	if i < len(s) && (s[i] == ',' || s[i] == ')' || s[i] == ']') {
+		return nil, i, nil
+	}
+
+	bound := []byte{}
+	var quoted bool
+	for i < len(s) {
+		switch c := s[i]; {
+		case c == '\\':
+			if i+1 >= len(s) {
+				return nil, i, parseErrorf(typ, s, len(s), "unexpected end of input")
+			}
+			bound = append(bound, s[i+1])
+			i += 2
+		case c == '"':
+			if quoted && i+1 < len(s) && s[i+1] == '"' {
+				bound = append(bound, '"')
+				i += 2
+				continue
+			}
+			quoted = !quoted
+			i++
+		case !quoted && (c == ',' || c == ')' || c == ']'):
+			return bound, i, nil
+		default:
+			bound = append(bound, c)
+			i++
+		}
+	}
+	if quoted {
+		return nil, i, parseErrorf(typ, s, -1, "unterminated quoted bound")
+	}
+
+	return bound, i, nil
+}
+
+// appendRange appends the text representation of lit to b, quoting bounds
+// where necessary.
+func appendRange(b []byte, lit rangeLiteral) []byte {
+	if lit.empty {
+		return append(b, "empty"...)
+	}
+
+	if lit.lower != nil && lit.lowerInc {
+		b = append(b, '[')
+	} else {
+		b = append(b, '(')
+	}
+	if lit.lower != nil {
+		b = appendRangeBound(b, lit.lower)
+	}
+	b = append(b, ',')
+	if lit.upper != nil {
+		b = appendRangeBound(b, lit.upper)
+	}
+	if lit.upper != nil && lit.upperInc {
+		b = append(b, ']')
+	} else {
+		b = append(b, ')')
+	}
+	return b
+}
+
+func appendRangeBound(b, v []byte) []byte {
+	if len(v) > 0 && bytes.IndexAny(v, "\"\\,()[] \t\n\r\v\f") < 0 {
+		return append(b, v...)
+	}
+
+	return appendArrayQuotedBytes(b, v)
+}
@@ -0,0 +1,210 @@
+package pg
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestInt4RangeScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Int4Range), `empty`, ""},
+		{new(Int4Range), `[1,10)`, ""},
+		{new(Int4Range), `(,5)`, ""},
+		{new(Int4Range), `[-3,)`, ""},
+		{new(Int4Range), `(,)`, ""},
+		{new(Int4Range), `[-2147483648,2147483647)`, ""},
+	})
+}
+
+func TestInt4RangeScanInvalid(t *testing.T) {
+	testScanInvalid(t, func() sql.Scanner { return new(Int4Range) },
+		``,
+		`[1,2`,
+		`1,2)`,
+		`[1)`,
+		`[a,2)`,
+		`[1,2) x`,
+		`[1,2147483648)`,
+	)
+}
+
+func TestInt8RangeScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Int8Range), `[1,10)`, ""},
+		{new(Int8Range), `[-9223372036854775808,9223372036854775807)`, ""},
+		{new(Int8Range), `(1,10]`, `[2,11)`},
+		{new(Int8Range), `empty`, ""},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(Int8Range) }, `[1,9223372036854775808)`, `[1.5,2)`)
+}
+
+func TestNumRangeScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(NumRange), `[1.5,2.25]`, ""},
+		{new(NumRange), `(,-0.5)`, ""},
+		{new(NumRange), `(1,1.0000000000000000001)`, ""},
+		{new(NumRange), `empty`, ""},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(NumRange) }, `[1,x)`, `[1e,2)`)
+}
+
+// testFloatCodec is a user-defined codec for a range over float64, such as
+// a floatrange type.
+type testFloatCodec struct{}
+
+func (testFloatCodec) ParseBound(src []byte) (float64, error) {
+	return strconv.ParseFloat(string(src), 64)
+}
+
+func (testFloatCodec) AppendBound(b []byte, v float64) ([]byte, error) {
+	return strconv.AppendFloat(b, v, 'g', -1, 64), nil
+}
+
+func (testFloatCodec) Compare(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func TestRangeCustomCodec(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Range[float64, testFloatCodec]), `[1.5,2.5)`, ""},
+		{new(Range[float64, testFloatCodec]), `(1,1]`, `empty`},
+		{new(Range[float64, testFloatCodec]), `(,1e+100]`, ""},
+	})
+	testScanNull(t, new(Range[float64, testFloatCodec]))
+
+	r := Range[float64, testFloatCodec]{Lower: 2, Upper: 1}
+	if v, err := r.Value(); err == nil {
+		t.Fatalf("Value of %+v = %v, want error", r, v)
+	}
+}
+
+func TestRangeCanonical(t *testing.T) {
+	tests := []struct {
+		r    Int4Range
+		want Int4Range
+	}{
+		{Int4Range{Lower: 1, Upper: 5, UpperInc: true}, Int4Range{Lower: 2, Upper: 6, LowerInc: true}},
+		{Int4Range{Lower: 1, Upper: 5, LowerInc: true}, Int4Range{Lower: 1, Upper: 5, LowerInc: true}},
+		{Int4Range{Lower: 1, Upper: 2}, Int4Range{Empty: true}},
+		{Int4Range{LowerInf: true, Upper: 5, UpperInc: true}, Int4Range{LowerInf: true, Upper: 6}},
+	}
+	for _, tt := range tests {
+		got, err := tt.r.Canonical()
+		if err != nil {
+			t.Errorf("Canonical(%+v): %v", tt.r, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Canonical(%+v) = %+v, want %+v", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestRangeNull(t *testing.T) {
+	testScanNull(t, new(Int4Range))
+
+	r := Int4Range{Null: true}
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "null" {
+		t.Fatalf("Marshal of NULL = %s, want null", b)
+	}
+	var u Int4Range
+	if err := json.Unmarshal(b, &u); err != nil {
+		t.Fatal(err)
+	}
+	if u != r {
+		t.Fatalf("Unmarshal(%s) = %+v, want %+v", b, u, r)
+	}
+
+	// The zero value is the empty range, not NULL.
+	if v, err := (Int4Range{}).Value(); err != nil || v != "empty" {
+		t.Fatalf("Value of the zero Int4Range = %#v, %v, want empty", v, err)
+	}
+}
+
+func TestTimeRangeInfinity(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(TsRange), `["2020-01-01 00:00:00",infinity)`, ""},
+		{new(TsRange), `(-infinity,"2020-01-01 12:30:00"]`, ""},
+		{new(TsRange), `["2020-01-01 00:00:00",)`, ""},
+		{new(TsRange), `[-infinity,infinity]`, ""},
+		{new(TstzRange), `["2020-01-01 00:00:00+02",infinity)`, `["2020-01-01 00:00:00+02:00",infinity)`},
+		{new(TstzRange), `(,infinity)`, ""},
+		{new(DateRange), `[2020-01-01,infinity)`, ""},
+		{new(DateRange), `[2020-01-01,infinity]`, ""},
+		{new(DateRange), `(-infinity,2020-01-02)`, ""},
+		{new(DateRange), `[2020-01-01,)`, ""},
+	})
+}
+
+func TestTsRangeInfiniteBound(t *testing.T) {
+	var r TsRange
+	if err := r.Scan("[2020-01-01 00:00:00,infinity)"); err != nil {
+		t.Fatal(err)
+	}
+	want := TsRange{
+		Lower:    Timestamp{Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Upper:    Timestamp{Infinite: 1},
+		LowerInc: true,
+	}
+	if r != want {
+		t.Fatalf("Scan = %+v, want %+v", r, want)
+	}
+	if r.UpperInf {
+		t.Fatal("upper bound of infinity reported as unbounded")
+	}
+	if !r.Contains(Timestamp{Time: time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)}) {
+		t.Error("range does not contain a later finite timestamp")
+	}
+	if r.Contains(Timestamp{Infinite: 1}) {
+		t.Error("range contains its exclusive upper bound of infinity")
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"lower":"2020-01-01T00:00:00Z","upper":"infinity","bounds":"[)"}`; string(b) != want {
+		t.Fatalf("Marshal = %s, want %s", b, want)
+	}
+	var u TsRange
+	if err := json.Unmarshal(b, &u); err != nil {
+		t.Fatal(err)
+	}
+	if u != r {
+		t.Fatalf("Unmarshal(%s) = %+v, want %+v", b, u, r)
+	}
+}
+
+func TestDateRangeCanonicalInfinity(t *testing.T) {
+	tests := []struct {
+		r    DateRange
+		want string
+	}{
+		{DateRange{Lower: Date{Infinite: -1}, Upper: Date{Year: 2020, Month: 1, Day: 1}, UpperInc: true}, `(-infinity,2020-01-02)`},
+		{DateRange{Lower: Date{Year: 2020, Month: 1, Day: 1}, Upper: Date{Infinite: 1}, LowerInc: true, UpperInc: true}, `[2020-01-01,infinity]`},
+		{DateRange{Lower: Date{Year: 2019, Month: 12, Day: 31}, Upper: Date{Infinite: 1}}, `[2020-01-01,infinity)`},
+	}
+	for _, tt := range tests {
+		v, err := tt.r.Value()
+		if err != nil {
+			t.Errorf("Value of %+v: %v", tt.r, err)
+			continue
+		}
+		if v != tt.want {
+			t.Errorf("Value of %+v = %q, want %q", tt.r, v, tt.want)
+		}
+	}
+}
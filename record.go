@@ -0,0 +1,94 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// recordOID is the OID of the record pseudo-type.
+const recordOID = 2249
+
+func init() {
+	registerType(&registeredType{
+		name:   "record",
+		oid:    recordOID,
+		goType: reflect.TypeOf(Record{}),
+		decode: func(src []byte) (interface{}, error) {
+			var r Record
+			if err := r.scanBytes(src); err != nil {
+				return nil, err
+			}
+			return r, nil
+		},
+	})
+}
+
+// Record represents an anonymous record value, such as the result of a
+// function returning record or of SELECT ROW(...). Each field is either a
+// string holding the text representation of the value or nil for NULL, and
+// is left to the caller to convert. DecodeValue decodes values of type
+// record and record[] into Record and []Record.
+type Record struct {
+	Fields []interface{}
+}
+
+// Scan implements the sql.Scanner interface.
+func (r *Record) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return r.scanBytes(src)
+	case string:
+		return r.scanBytes([]byte(src))
+	case nil:
+		r.Fields = nil
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Record", src)
+}
+
+func (r *Record) scanBytes(src []byte) error {
+	values, err := ParseComposite(src)
+	if err != nil {
+		return err
+	}
+
+	fields := make([]interface{}, len(values))
+	for i, v := range values {
+		if v != nil {
+			fields[i] = string(v)
+		}
+	}
+	r.Fields = fields
+	return nil
+}
+
+// Strings returns the fields as strings, with NULL fields as empty strings.
+func (r Record) Strings() []string {
+	ss := make([]string, len(r.Fields))
+	for i, f := range r.Fields {
+		if s, ok := f.(string); ok {
+			ss[i] = s
+		}
+	}
+	return ss
+}
+
+// Value implements the driver.Valuer interface. Fields may be nil, strings,
+// []byte or any other driver.Value.
+func (r Record) Value() (driver.Value, error) {
+	if r.Fields == nil {
+		return nil, nil
+	}
+
+	values := make([][]byte, len(r.Fields))
+	for i, f := range r.Fields {
+		var err error
+		if values[i], err = encodeDriverValue(f); err != nil {
+			return nil, fmt.Errorf("pg: record field index %d: %w", i, err)
+		}
+	}
+
+	return FormatComposite(values), nil
+}
@@ -0,0 +1,117 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Regclass represents a PostgreSQL regclass value, the name of a relation
+// such as "public.users" as the server outputs it, possibly schema-qualified
+// and quoted. OID may be set as well, for instance by selecting the value
+// cast to oid into it; the server outputs the bare OID for a relation that
+// no longer exists, which is scanned into OID with an empty Name. Use a
+// *Regclass to scan nullable columns.
+type Regclass struct {
+	Name string
+	OID  uint32
+}
+
+// Scan implements the sql.Scanner interface.
+func (r *Regclass) Scan(src interface{}) error {
+	return scanReg(src, "Regclass", &r.Name, &r.OID)
+}
+
+// Value implements the driver.Valuer interface. If OID is set it is sent,
+// otherwise Name.
+func (r Regclass) Value() (driver.Value, error) {
+	return regValue(r.Name, r.OID)
+}
+
+// Regtype represents a PostgreSQL regtype value, the name of a data type
+// such as "integer" or "character varying". See Regclass for the use of
+// OID. Use a *Regtype to scan nullable columns.
+type Regtype struct {
+	Name string
+	OID  uint32
+}
+
+// Scan implements the sql.Scanner interface.
+func (r *Regtype) Scan(src interface{}) error {
+	return scanReg(src, "Regtype", &r.Name, &r.OID)
+}
+
+// Value implements the driver.Valuer interface. If OID is set it is sent,
+// otherwise Name.
+func (r Regtype) Value() (driver.Value, error) {
+	return regValue(r.Name, r.OID)
+}
+
+// Regproc represents a PostgreSQL regproc value, the name of a function
+// such as "now". See Regclass for the use of OID. Use a *Regproc to scan
+// nullable columns.
+type Regproc struct {
+	Name string
+	OID  uint32
+}
+
+// Scan implements the sql.Scanner interface.
+func (r *Regproc) Scan(src interface{}) error {
+	return scanReg(src, "Regproc", &r.Name, &r.OID)
+}
+
+// Value implements the driver.Valuer interface. If OID is set it is sent,
+// otherwise Name.
+func (r Regproc) Value() (driver.Value, error) {
+	return regValue(r.Name, r.OID)
+}
+
+// scanReg scans the output of a reg* type: a name, a bare OID for an object
+// that does not exist or "-" for the invalid OID 0.
+func scanReg(src interface{}, typ string, name *string, oid *uint32) error {
+	var s string
+	switch src := src.(type) {
+	case []byte:
+		s = string(src)
+	case string:
+		s = src
+	case int64:
+		if src < 0 || src > 1<<32-1 {
+			return fmt.Errorf("pg: %s OID %d out of range", typ, src)
+		}
+		*name, *oid = "", uint32(src)
+		return nil
+	default:
+		return fmt.Errorf("pg: cannot convert %T to %s", src, typ)
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return parseErrorf(typ, s, -1, "empty name")
+	}
+	if s == "-" {
+		*name, *oid = "", 0
+		return nil
+	}
+	if isDigits(s) {
+		v, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return parseErrorf(typ, s, -1, "OID out of range")
+		}
+		*name, *oid = "", uint32(v)
+		return nil
+	}
+	*name, *oid = s, 0
+	return nil
+}
+
+func regValue(name string, oid uint32) (driver.Value, error) {
+	switch {
+	case oid != 0:
+		return strconv.FormatUint(uint64(oid), 10), nil
+	case name == "":
+		return "-", nil
+	}
+	return name, nil
+}
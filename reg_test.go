@@ -0,0 +1,50 @@
+package pg
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestRegScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Regclass), `public.users`, ""},
+		{new(Regclass), `"My Table"`, ""},
+		{new(Regclass), `16384`, ""},
+		{new(Regclass), `-`, ""},
+		{new(Regtype), `character varying`, ""},
+		{new(Regtype), `integer[]`, ""},
+		{new(Regproc), `now`, ""},
+		{new(Regproc), ` pg_catalog.now `, `pg_catalog.now`},
+	})
+	for _, fn := range []func() sql.Scanner{
+		func() sql.Scanner { return new(Regclass) },
+		func() sql.Scanner { return new(Regtype) },
+		func() sql.Scanner { return new(Regproc) },
+	} {
+		testScanInvalid(t, fn, ``, `4294967296`)
+		if err := fn().Scan(nil); err == nil {
+			t.Errorf("%T: expected error scanning NULL", fn())
+		}
+	}
+}
+
+func TestRegScanOID(t *testing.T) {
+	r := Regclass{Name: "users"}
+	if err := r.Scan(int64(16384)); err != nil {
+		t.Fatal(err)
+	}
+	if r != (Regclass{OID: 16384}) {
+		t.Errorf("Scan(int64) = %+v", r)
+	}
+	if v, err := r.Value(); err != nil || v != "16384" {
+		t.Errorf("Value = %v, %v", v, err)
+	}
+	if err := r.Scan(int64(-1)); err == nil {
+		t.Error("expected error for a negative OID")
+	}
+
+	// An OID takes precedence over the name.
+	if v, err := (Regtype{Name: "integer", OID: 23}).Value(); err != nil || v != "23" {
+		t.Errorf("Value = %v, %v", v, err)
+	}
+}
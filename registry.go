@@ -0,0 +1,203 @@
+package pg
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// registeredType is a Go type registered for a named database type.
+type registeredType struct {
+	name   string
+	oid    uint32
+	goType reflect.Type
+	decode func(src []byte) (interface{}, error)
+}
+
+var registry = struct {
+	sync.RWMutex
+	byName map[string]*registeredType
+	byOID  map[uint32]*registeredType
+}{
+	byName: map[string]*registeredType{},
+	byOID:  map[uint32]*registeredType{},
+}
+
+// RegisterComposite registers the struct T as the Go type of the named
+// composite type, so that DecodeValue produces a T for its values and a []T
+// for arrays of it. The name may be schema-qualified. If the OID of the type
+// is known it can be given as well, otherwise pass 0. Registering a name
+// again replaces the previous registration.
+//
+//	pg.RegisterComposite[Address]("address", 0)
+func RegisterComposite[T any](name string, oid uint32) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("pg: RegisterComposite: %s is not a struct", t))
+	}
+
+	registerType(&registeredType{
+		name:   name,
+		oid:    oid,
+		goType: t,
+		decode: func(src []byte) (interface{}, error) {
+			var c Composite[T]
+			if err := c.scanBytes(src); err != nil {
+				return nil, err
+			}
+			return c.V, nil
+		},
+	})
+}
+
+// RegisterDomain registers T as the Go type of the named domain type, so
+// that DecodeValue produces a T for its values and a []T for arrays of it,
+// and composite attributes tagged with the domain's type name decode into
+// T. T is decoded like a composite attribute would be: typically it
+// implements sql.Scanner, but it may also be a string, number or other type
+// supported by Composite. The name may be schema-qualified. If the OID of
+// the type is known it can be given as well, otherwise pass 0.
+//
+//	type Email string
+//
+//	pg.RegisterDomain[Email]("email", 0)
+func RegisterDomain[T any](name string, oid uint32) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	registerType(&registeredType{
+		name:   name,
+		oid:    oid,
+		goType: t,
+		decode: func(src []byte) (interface{}, error) {
+			v := reflect.New(t).Elem()
+			if err := decodeCompositeField(v, src); err != nil {
+				return nil, fmt.Errorf("pg: decoding %s: %w", name, err)
+			}
+			return v.Interface(), nil
+		},
+	})
+}
+
+func registerType(t *registeredType) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	key := normalizeTypeName(t.name)
+	if old, ok := registry.byName[key]; ok && old.oid != 0 {
+		delete(registry.byOID, old.oid)
+	}
+	registry.byName[key] = t
+	if t.oid != 0 {
+		registry.byOID[t.oid] = t
+	}
+}
+
+// lookupType returns the registered type with the given name.
+func lookupType(name string) *registeredType {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.byName[normalizeTypeName(name)]
+}
+
+// lookupTypeOID returns the registered type with the given OID.
+func lookupTypeOID(oid uint32) *registeredType {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.byOID[oid]
+}
+
+// normalizeTypeName lower-cases unquoted parts of a type name and strips the
+// quotes of quoted ones, so that `Public."Address"` and `public."Address"`
+// refer to the same type.
+func normalizeTypeName(name string) string {
+	var b strings.Builder
+	quoted := false
+	for _, r := range strings.TrimSpace(name) {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+			b.WriteRune(r)
+		default:
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// DecodeValue decodes the text representation src of a value of the named
+// type into the Go type registered for it. Array types, named either with a
+// [] suffix or with the server's leading underscore, decode into a slice of
+// the registered element type. A nil src decodes as nil.
+func DecodeValue(typeName string, src []byte) (interface{}, error) {
+	if src == nil {
+		return nil, nil
+	}
+	if t := lookupType(typeName); t != nil {
+		return t.decode(src)
+	}
+
+	elemName, ok := arrayElemTypeName(typeName)
+	if !ok {
+		return nil, fmt.Errorf("pg: no Go type registered for type %q", typeName)
+	}
+	t := lookupType(elemName)
+	if t == nil {
+		return nil, fmt.Errorf("pg: no Go type registered for type %q", typeName)
+	}
+	return decodeRegisteredArray(t, src)
+}
+
+// DecodeValueOID is like DecodeValue for a type identified by its OID. Only
+// types registered with a non-zero OID can be found this way.
+func DecodeValueOID(oid uint32, src []byte) (interface{}, error) {
+	if src == nil {
+		return nil, nil
+	}
+	t := lookupTypeOID(oid)
+	if t == nil {
+		return nil, fmt.Errorf("pg: no Go type registered for type OID %d", oid)
+	}
+	return t.decode(src)
+}
+
+// arrayElemTypeName returns the element type name of an array type name
+// such as "address[]" or "_address".
+func arrayElemTypeName(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if strings.HasSuffix(name, "[]") {
+		return strings.TrimSpace(strings.TrimSuffix(name, "[]")), true
+	}
+
+	schema, typ := "", name
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		schema, typ = name[:i+1], name[i+1:]
+	}
+	if unquoted := strings.Trim(typ, `"`); strings.HasPrefix(unquoted, "_") {
+		return schema + strings.Replace(typ, "_", "", 1), true
+	}
+	return "", false
+}
+
+// decodeRegisteredArray decodes a one-dimensional array of the registered
+// type t into a slice of its Go type.
+func decodeRegisteredArray(t *registeredType, src []byte) (interface{}, error) {
+	elems, err := scanLinearArray(src, arrayDelimiterOf(t.goType), t.name+"[]", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := reflect.MakeSlice(reflect.SliceOf(t.goType), len(elems), len(elems))
+	for i, elem := range elems {
+		if elem == nil {
+			return nil, fmt.Errorf("%w at index %d: cannot convert nil to %s", ErrNullElement, i, t.goType)
+		}
+		e, err := t.decode(elem)
+		if err != nil {
+			return nil, err
+		}
+		v.Index(i).Set(reflect.ValueOf(e))
+	}
+	return v.Interface(), nil
+}
@@ -0,0 +1,142 @@
+package pg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegisterComposite(t *testing.T) {
+	RegisterComposite[testAddress]("test_registry.address", 900001)
+
+	want := testAddress{Street: "Main St"}
+	for _, name := range []string{
+		"test_registry.address",
+		`Test_Registry."address"`,
+		" TEST_REGISTRY.ADDRESS ",
+	} {
+		v, err := DecodeValue(name, []byte(`("Main St",)`))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("%s: got %#v, want %#v", name, v, want)
+		}
+	}
+
+	v, err := DecodeValueOID(900001, []byte(`("Main St",)`))
+	if err != nil || !reflect.DeepEqual(v, want) {
+		t.Errorf("DecodeValueOID = %#v, %v", v, err)
+	}
+
+	for _, name := range []string{"test_registry.address[]", "test_registry._address"} {
+		v, err := DecodeValue(name, []byte(`{"(\"Main St\",)","(Elm,12345)"}`))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		zip := "12345"
+		if want := []testAddress{{Street: "Main St"}, {Street: "Elm", Zip: &zip}}; !reflect.DeepEqual(v, want) {
+			t.Errorf("%s: got %#v, want %#v", name, v, want)
+		}
+	}
+
+	if v, err := DecodeValue("test_registry.address", nil); v != nil || err != nil {
+		t.Errorf("DecodeValue of NULL = %#v, %v", v, err)
+	}
+}
+
+func TestRegisterCompositeReplace(t *testing.T) {
+	RegisterComposite[testAddress]("test_registry.replaced", 900002)
+	RegisterComposite[testCustomer]("test_registry.replaced", 900003)
+
+	if _, err := DecodeValueOID(900002, []byte(`(a)`)); err == nil {
+		t.Error("expected the old OID to be unregistered")
+	}
+	v, err := DecodeValue("test_registry.replaced", []byte(`(1,x,t,0,{},"(a,)","2020-01-01 00:00:00+00")`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := v.(testCustomer); !ok {
+		t.Errorf("got %T, want testCustomer", v)
+	}
+}
+
+func TestDecodeValueUnregistered(t *testing.T) {
+	for _, name := range []string{"test_registry.missing", "test_registry.missing[]"} {
+		if _, err := DecodeValue(name, []byte(`()`)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := DecodeValueOID(900999, []byte(`()`)); err == nil {
+		t.Error("expected error for an unregistered OID")
+	}
+
+	RegisterComposite[testAddress]("test_registry.elements", 0)
+	if _, err := DecodeValue("test_registry.elements[]", []byte(`{NULL}`)); err == nil {
+		t.Error("expected error for a NULL element")
+	}
+}
+
+func TestRegisterCompositeNotStruct(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for a non-struct type")
+		}
+	}()
+	RegisterComposite[string]("test_registry.text", 0)
+}
+
+type testEmail string
+
+type testContact struct {
+	Name  string      `pg:"name"`
+	Email interface{} `pg:"email,type=test_registry.email"`
+	Other interface{} `pg:"other"`
+}
+
+func TestRegisterDomain(t *testing.T) {
+	RegisterDomain[testEmail]("test_registry.email", 900010)
+	RegisterDomain[Point]("test_registry.location", 0)
+
+	v, err := DecodeValue("test_registry.email", []byte("a@example.com"))
+	if err != nil || v != testEmail("a@example.com") {
+		t.Errorf("DecodeValue = %#v, %v", v, err)
+	}
+	v, err = DecodeValueOID(900010, []byte("b@example.com"))
+	if err != nil || v != testEmail("b@example.com") {
+		t.Errorf("DecodeValueOID = %#v, %v", v, err)
+	}
+	v, err = DecodeValue("test_registry.email[]", []byte(`{a@example.com,b@example.com}`))
+	if want := []testEmail{"a@example.com", "b@example.com"}; err != nil || !reflect.DeepEqual(v, want) {
+		t.Errorf("DecodeValue of array = %#v, %v", v, err)
+	}
+
+	v, err = DecodeValue("test_registry.location", []byte("(1,2)"))
+	if err != nil || v != (Point{X: 1, Y: 2}) {
+		t.Errorf("DecodeValue of Scanner domain = %#v, %v", v, err)
+	}
+	if _, err := DecodeValue("test_registry.location", []byte("(1,")); err == nil {
+		t.Error("expected error for an invalid point")
+	}
+}
+
+func TestCompositeTypedAttribute(t *testing.T) {
+	RegisterDomain[testEmail]("test_registry.email", 900010)
+
+	var c Composite[testContact]
+	if err := c.Scan([]byte(`(Alice,a@example.com,x)`)); err != nil {
+		t.Fatal(err)
+	}
+	want := testContact{Name: "Alice", Email: testEmail("a@example.com"), Other: "x"}
+	if !reflect.DeepEqual(c.V, want) {
+		t.Errorf("Scan = %#v, want %#v", c.V, want)
+	}
+
+	if err := c.Scan([]byte(`(Alice,,)`)); err != nil {
+		t.Fatal(err)
+	}
+	if c.V.Email != nil || c.V.Other != nil {
+		t.Errorf("NULL attributes = %#v, %#v, want nil", c.V.Email, c.V.Other)
+	}
+}
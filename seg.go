@@ -0,0 +1,159 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SegBound is a bound of a seg value. Value is the number as written, which
+// keeps its significant digits, or empty if the bound is open. Ext is the
+// certainty indicator of the bound: '<', '>', '~' or 0 for none.
+type SegBound struct {
+	Value string
+	Ext   byte
+}
+
+// Float returns the value of b.
+func (b SegBound) Float() (float64, error) {
+	return strconv.ParseFloat(b.Value, 32)
+}
+
+// Seg represents a value of the seg extension type, a line segment or
+// floating point interval such as "5.0 .. 7.0", "<1 .. ~2", "5.0 .." or a
+// single value such as "~6.5", for which Lower and Upper are equal. Values
+// written as "6.5(+-)0.3" are scanned as the interval they denote. Use a
+// *Seg to scan nullable columns.
+type Seg struct {
+	Lower SegBound
+	Upper SegBound
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *Seg) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return s.scanString(string(src))
+	case string:
+		return s.scanString(src)
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Seg", src)
+}
+
+func (s *Seg) scanString(src string) error {
+	v, err := parseSeg(strings.TrimSpace(src))
+	if err == nil {
+		err = v.check()
+	}
+	if err != nil {
+		return parseError("Seg", src, -1, err)
+	}
+
+	*s = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (s Seg) Value() (driver.Value, error) {
+	if err := s.check(); err != nil {
+		return nil, fmt.Errorf("pg: invalid Seg: %w", err)
+	}
+
+	return s.String(), nil
+}
+
+// String returns s in the extension's output format.
+func (s Seg) String() string {
+	if s.Lower == s.Upper {
+		return s.Lower.String()
+	}
+	switch {
+	case s.Lower.Value == "":
+		return ".. " + s.Upper.String()
+	case s.Upper.Value == "":
+		return s.Lower.String() + " .."
+	}
+	return s.Lower.String() + " .. " + s.Upper.String()
+}
+
+// String returns b with its certainty indicator.
+func (b SegBound) String() string {
+	if b.Ext == 0 {
+		return b.Value
+	}
+	return string(b.Ext) + b.Value
+}
+
+func (s Seg) check() error {
+	var lower, upper float64
+	var err error
+	if s.Lower.Value == "" && s.Upper.Value == "" {
+		return fmt.Errorf("both bounds are open")
+	}
+	for _, b := range []SegBound{s.Lower, s.Upper} {
+		if b.Ext != 0 && b.Ext != '<' && b.Ext != '>' && b.Ext != '~' {
+			return fmt.Errorf("invalid certainty indicator %q", b.Ext)
+		}
+		if b.Value == "" && b.Ext != 0 {
+			return fmt.Errorf("open bound with certainty indicator %q", b.Ext)
+		}
+	}
+	if s.Lower.Value != "" {
+		if lower, err = s.Lower.Float(); err != nil {
+			return fmt.Errorf("invalid lower bound %q", s.Lower.Value)
+		}
+	}
+	if s.Upper.Value != "" {
+		if upper, err = s.Upper.Float(); err != nil {
+			return fmt.Errorf("invalid upper bound %q", s.Upper.Value)
+		}
+	}
+	if s.Lower.Value != "" && s.Upper.Value != "" && lower > upper {
+		return fmt.Errorf("lower bound %s is greater than upper bound %s", s.Lower.Value, s.Upper.Value)
+	}
+	return nil
+}
+
+// parseSeg parses the text of a seg value.
+func parseSeg(s string) (Seg, error) {
+	if i := strings.Index(s, "(+-)"); i >= 0 {
+		center, err := strconv.ParseFloat(strings.TrimSpace(s[:i]), 32)
+		if err != nil {
+			return Seg{}, fmt.Errorf("invalid number %q", strings.TrimSpace(s[:i]))
+		}
+		delta, err := strconv.ParseFloat(strings.TrimSpace(s[i+4:]), 32)
+		if err != nil {
+			return Seg{}, fmt.Errorf("invalid number %q", strings.TrimSpace(s[i+4:]))
+		}
+		return Seg{
+			Lower: SegBound{Value: strconv.FormatFloat(float64(float32(center-delta)), 'g', -1, 32)},
+			Upper: SegBound{Value: strconv.FormatFloat(float64(float32(center+delta)), 'g', -1, 32)},
+		}, nil
+	}
+
+	lower, upper, interval := strings.Cut(s, "..")
+	l, err := parseSegBound(strings.TrimSpace(lower))
+	if err != nil || !interval {
+		return Seg{Lower: l, Upper: l}, err
+	}
+	u, err := parseSegBound(strings.TrimSpace(upper))
+	return Seg{Lower: l, Upper: u}, err
+}
+
+func parseSegBound(s string) (SegBound, error) {
+	var b SegBound
+	if s == "" {
+		return b, nil
+	}
+	if c := s[0]; c == '<' || c == '>' || c == '~' {
+		b.Ext = c
+		s = strings.TrimSpace(s[1:])
+	}
+	if _, err := strconv.ParseFloat(s, 32); err != nil {
+		return b, fmt.Errorf("invalid number %q", s)
+	}
+	b.Value = s
+	return b, nil
+}
@@ -0,0 +1,55 @@
+package pg
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestSegScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Seg), `6.5`, ""},
+		{new(Seg), `~6.50`, ""},
+		{new(Seg), `5.0 .. 7.0`, ""},
+		{new(Seg), `<1 .. ~2`, ""},
+		{new(Seg), `5.0 ..`, ""},
+		{new(Seg), `.. 7`, ""},
+		{new(Seg), `1..2`, `1 .. 2`},
+		{new(Seg), `6.5(+-)0.3`, `6.2 .. 6.8`},
+		{new(Seg), `2 .. 2`, `2`},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(Seg) },
+		``,
+		`..`,
+		`a`,
+		`2 .. 1`,
+		`1 .. b`,
+		`x(+-)1`,
+	)
+	if err := new(Seg).Scan(nil); err == nil {
+		t.Error("expected error scanning NULL into Seg")
+	}
+}
+
+func TestSegValueInvalid(t *testing.T) {
+	for _, s := range []Seg{
+		{},
+		{Lower: SegBound{Value: "1", Ext: '!'}, Upper: SegBound{Value: "2"}},
+		{Lower: SegBound{Ext: '<'}, Upper: SegBound{Value: "2"}},
+		{Lower: SegBound{Value: "x"}, Upper: SegBound{Value: "2"}},
+	} {
+		if v, err := s.Value(); err == nil {
+			t.Errorf("Value of %+v = %v, want error", s, v)
+		}
+	}
+
+	var s Seg
+	if err := s.Scan("~6.50 .. 7"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Lower.Ext != '~' || s.Lower.Value != "6.50" {
+		t.Errorf("Lower = %+v", s.Lower)
+	}
+	if f, err := s.Upper.Float(); err != nil || f != 7 {
+		t.Errorf("Upper.Float() = %v, %v", f, err)
+	}
+}
@@ -0,0 +1,124 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Snapshot represents a PostgreSQL pg_snapshot or txid_snapshot value, such
+// as "10:20:10,14,15", as returned by pg_current_snapshot(). Transactions
+// before Xmin are finished, those from Xmax on have not started yet, and
+// Xip lists the ones in between that were in progress. Use a *Snapshot to
+// scan nullable columns.
+type Snapshot struct {
+	Xmin uint64
+	Xmax uint64
+	Xip  []uint64
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *Snapshot) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return s.scanString(string(src))
+	case string:
+		return s.scanString(src)
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Snapshot", src)
+}
+
+func (s *Snapshot) scanString(src string) error {
+	parts := strings.Split(strings.TrimSpace(src), ":")
+	if len(parts) != 3 {
+		return parseError("Snapshot", src, -1, nil)
+	}
+
+	var v Snapshot
+	var err error
+	if v.Xmin, err = strconv.ParseUint(parts[0], 10, 64); err != nil {
+		return parseErrorf("Snapshot", src, -1, "invalid xmin")
+	}
+	if v.Xmax, err = strconv.ParseUint(parts[1], 10, 64); err != nil {
+		return parseErrorf("Snapshot", src, -1, "invalid xmax")
+	}
+	if parts[2] != "" {
+		for _, x := range strings.Split(parts[2], ",") {
+			xid, err := strconv.ParseUint(x, 10, 64)
+			if err != nil {
+				return parseErrorf("Snapshot", src, -1, "invalid xip %q", x)
+			}
+			v.Xip = append(v.Xip, xid)
+		}
+	}
+	if err := v.check(); err != nil {
+		return parseError("Snapshot", src, -1, err)
+	}
+
+	*s = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (s Snapshot) Value() (driver.Value, error) {
+	if err := s.check(); err != nil {
+		return nil, fmt.Errorf("pg: invalid Snapshot: %w", err)
+	}
+
+	return s.String(), nil
+}
+
+// String returns s in the server's output format, with the in-progress
+// transactions sorted.
+func (s Snapshot) String() string {
+	xip := append([]uint64(nil), s.Xip...)
+	sort.Slice(xip, func(i, j int) bool { return xip[i] < xip[j] })
+
+	b := strconv.AppendUint(nil, s.Xmin, 10)
+	b = append(b, ':')
+	b = strconv.AppendUint(b, s.Xmax, 10)
+	b = append(b, ':')
+	for i, xid := range xip {
+		if i > 0 && xid == xip[i-1] {
+			continue
+		}
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = strconv.AppendUint(b, xid, 10)
+	}
+	return string(b)
+}
+
+// VisibleTo reports whether the transaction txid is visible in s, that is,
+// whether it had committed or aborted when s was taken, like
+// pg_visible_in_snapshot(txid, s).
+func (s Snapshot) VisibleTo(txid uint64) bool {
+	if txid < s.Xmin {
+		return true
+	}
+	if txid >= s.Xmax {
+		return false
+	}
+	for _, xid := range s.Xip {
+		if xid == txid {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Snapshot) check() error {
+	if s.Xmin == 0 || s.Xmin > s.Xmax {
+		return fmt.Errorf("xmin %d must be positive and not greater than xmax %d", s.Xmin, s.Xmax)
+	}
+	for _, xid := range s.Xip {
+		if xid < s.Xmin || xid >= s.Xmax {
+			return fmt.Errorf("xip %d is not between xmin %d and xmax %d", xid, s.Xmin, s.Xmax)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package pg
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshotScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Snapshot), `10:20:`, ""},
+		{new(Snapshot), `10:20:10,14,15`, ""},
+		{new(Snapshot), `10:20:15,10,14`, `10:20:10,14,15`},
+		{new(Snapshot), `10:20:14,14`, `10:20:14`},
+		{new(Snapshot), `10:10:`, ""},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(Snapshot) },
+		``,
+		`10:20`,
+		`x:20:`,
+		`10:x:`,
+		`10:20:x`,
+		`10:20:,`,
+		`0:20:`,
+		`20:10:`,
+		`10:20:9`,
+		`10:20:20`,
+	)
+	if err := new(Snapshot).Scan(nil); err == nil {
+		t.Error("expected error scanning NULL into Snapshot")
+	}
+}
+
+func TestSnapshotScan(t *testing.T) {
+	var s Snapshot
+	if err := s.Scan("10:20:10,14,15"); err != nil {
+		t.Fatal(err)
+	}
+	want := Snapshot{Xmin: 10, Xmax: 20, Xip: []uint64{10, 14, 15}}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("Scan = %+v, want %+v", s, want)
+	}
+
+	for _, tt := range []struct {
+		txid uint64
+		want bool
+	}{
+		{9, true},
+		{10, false},
+		{11, true},
+		{14, false},
+		{19, true},
+		{20, false},
+	} {
+		if got := s.VisibleTo(tt.txid); got != tt.want {
+			t.Errorf("VisibleTo(%d) = %v", tt.txid, got)
+		}
+	}
+
+	if _, err := (Snapshot{Xmin: 10, Xmax: 20, Xip: []uint64{25}}).Value(); err == nil {
+		t.Error("expected error for xip past xmax")
+	}
+}
@@ -5,12 +5,22 @@ import (
 	"database/sql/driver"
 	"encoding/hex"
 	"fmt"
-	"strconv"
 	"strings"
+	"sync"
 )
 
+// StringArray represents a one-dimensional array of the PostgreSQL character
+// types. Scan reuses the backing array of Strings when it is large enough,
+// so copy the slice to keep it across Scans.
 type StringArray struct {
 	Strings []string
+
+	// Options tunes parsing. It may be nil.
+	Options *ParseOptions
+}
+
+func (a *StringArray) setOptions(opts *ParseOptions) {
+	a.Options = opts
 }
 
 // Scan implements the sql.Scanner interface.
@@ -21,134 +31,313 @@ func (a *StringArray) Scan(src interface{}) error {
 	case string:
 		return a.scanBytes([]byte(src))
 	case nil:
-		a = nil
+		// Options is kept, so that a reused StringArray keeps parsing the
+		// same way after a NULL.
+		a.Strings = nil
 		return nil
 	}
 
-	return fmt.Errorf("pq: cannot convert %T to StringArray", src)
+	return fmt.Errorf("pg: cannot convert %T to StringArray", src)
 }
 
 func (a *StringArray) scanBytes(src []byte) error {
-	elems, err := scanLinearArray(src, []byte{','}, "StringArray")
+	return a.scanArray(src, nil)
+}
+
+// scanArray is like scanBytes, parsing with scratch rather than a pooled
+// arrayScratch if it is not nil.
+func (a *StringArray) scanArray(src []byte, scratch *arrayScratch) error {
+	if isBinaryArray(src) {
+		oid, elems, err := scanLinearBinaryArray(src, "StringArray", a.Options)
+		if err != nil {
+			return err
+		}
+		if !isTextOID(oid) {
+			return fmt.Errorf("pg: cannot convert binary array of type OID %d to StringArray", oid)
+		}
+		return a.setElems(elems)
+	}
+
+	if scratch == nil {
+		scratch = getArrayScratch()
+		defer putArrayScratch(scratch)
+	}
+
+	elems, err := scratch.parseLinear(src, []byte{','}, "StringArray", a.Options)
 	if err != nil {
 		return err
 	}
-	if a != nil && a.Strings != nil && len(elems) == 0 {
-		a.Strings = a.Strings[:0]
-	} else {
-		ss := make([]string, len(elems))
-		for i, v := range elems {
-			if ss[i] = string(v); v == nil {
-				return fmt.Errorf("pq: parsing array element index %d: cannot convert nil to string", i)
-			}
-		}
-		*a = StringArray{
-			Strings: ss,
-		}
+	if a.Options != nil && a.Options.ZeroCopy {
+		// Zero-copy strings may refer to unescaped elements in the
+		// scratch buffer, so it must not be reused.
+		scratch.buf = nil
+	}
+	return a.setElems(elems)
+}
+
+func (a *StringArray) setElems(elems [][]byte) error {
+	elems, err := a.Options.nulls(elems, "string")
+	if err != nil {
+		return err
 	}
+	if err := a.Options.checkUTF8(elems); err != nil {
+		return err
+	}
+
+	ss := reuseSlice(a.Strings, len(elems))
+	for i, v := range elems {
+		ss[i] = a.Options.string(v)
+	}
+	a.Strings = ss
 	return nil
 }
 
-// Value implements the driver.Valuer interface.
+// Value implements the driver.Valuer interface. A nil Strings is NULL.
 func (a StringArray) Value() (driver.Value, error) {
+	if a.Strings == nil {
+		return nil, nil
+	}
 	if n := len(a.Strings); n > 0 {
-		// There will be at least two curly brackets, 2*N bytes of quotes,
-		// and N-1 bytes of delimiters.
-		b := make([]byte, 1, 1+3*n)
-		b[0] = '{'
+		// There will be two curly brackets and N-1 bytes of delimiters,
+		// and each element takes at most its quoted length.
+		size := 1 + n
+		for _, s := range a.Strings {
+			size += arrayQuotedLen(s)
+		}
+		b, _ := a.AppendValue(make([]byte, 0, size))
+		return string(b), nil
+	}
+
+	return "{}", nil
+}
+
+// AppendValue appends the text of a to dst, like Value but without
+// allocating a string. A NULL value appends nothing.
+func (a StringArray) AppendValue(dst []byte) ([]byte, error) {
+	if a.Strings == nil {
+		return dst, nil
+	}
 
-		b = appendArrayQuotedBytes(b, []byte(a.Strings[0]))
-		for i := 1; i < n; i++ {
-			b = append(b, ',')
-			b = appendArrayQuotedBytes(b, []byte(a.Strings[i]))
+	dst = append(dst, '{')
+	for i, s := range a.Strings {
+		if i > 0 {
+			dst = append(dst, ',')
 		}
+		dst = appendArrayElement(dst, []byte(s))
+	}
+	return append(dst, '}'), nil
+}
 
-		return string(append(b, '}')), nil
+// MarshalBinary implements the encoding.BinaryMarshaler interface, encoding
+// a as a text[] in the binary array format.
+func (a StringArray) MarshalBinary() ([]byte, error) {
+	elems := make([][]byte, len(a.Strings))
+	for i, s := range a.Strings {
+		elems[i] = []byte(s)
 	}
+	return appendBinaryArray(nil, textOID, elems), nil
+}
 
-	return "{}", nil
+// maxPooledArrayBuf is the largest scratch buffer kept for reuse, so that
+// one huge array does not pin its memory in the pool.
+const maxPooledArrayBuf = 64 * 1024
+
+// arrayScratch holds the buffers used while parsing an array. Elements
+// without escapes refer to the source directly, and escaped ones are
+// unescaped into buf.
+type arrayScratch struct {
+	elems [][]byte
+	buf   []byte
 }
 
-func parseArray(src, del []byte) (dims []int, elems [][]byte, err error) {
-	var depth, i int
+var arrayScratchPool = sync.Pool{
+	New: func() interface{} { return new(arrayScratch) },
+}
+
+// getArrayScratch returns a pooled arrayScratch. The elements it parses are
+// only valid until it is returned with putArrayScratch.
+func getArrayScratch() *arrayScratch {
+	return arrayScratchPool.Get().(*arrayScratch)
+}
 
-	if len(src) < 1 || src[0] != '{' {
-		return nil, nil, fmt.Errorf("pq: unable to parse array; expected %q at offset %d", '{', 0)
+func putArrayScratch(s *arrayScratch) {
+	if cap(s.buf) > maxPooledArrayBuf || cap(s.elems) > maxPooledArrayBuf {
+		return
+	}
+	for i := range s.elems {
+		s.elems[i] = nil
+	}
+	s.elems = s.elems[:0]
+	s.buf = s.buf[:0]
+	arrayScratchPool.Put(s)
+}
+
+func parseArray(src, del []byte, typ string, opts *ParseOptions) (dims []int, elems [][]byte, err error) {
+	return new(arrayScratch).parse(src, del, typ, opts)
+}
+
+// parse parses the text representation of an array into s, reporting
+// errors for the type named typ. The returned
+// elements alias src and s.buf. Unless opts is strict, whitespace around
+// braces, delimiters and elements is skipped, as the server does. All
+// sub-arrays at the same depth must have the same number of elements, so
+// that the number of elements is the product of dims.
+func (s *arrayScratch) parse(src, del []byte, typ string, opts *ParseOptions) (dims []int, elems [][]byte, err error) {
+	lenient := !opts.strict()
+	var depth, i, size int
+	var counts, starts []int
+	closeAt := -1
+	// The elements are counted before the input is validated, so the
+	// count is capped at the most elements an array of this length can
+	// have, each taking at least one byte and a delimiter.
+	n := countArrayElems(src, del)
+	if m := len(src)/2 + 1; n > m {
+		n = m
+	}
+	if opts != nil && opts.MaxElements > 0 && n > opts.MaxElements {
+		n = opts.MaxElements + 1
+	}
+	if cap(s.elems) < n {
+		s.elems = make([][]byte, 0, n)
+	}
+	elems = s.elems[:0]
+	s.buf = s.buf[:0]
+
+	if lenient {
+		i = skipArraySpace(src, i)
+	}
+	if i >= len(src) || src[i] != '{' {
+		return nil, nil, arrayParseErrorf(typ, src, i, "expected %q", '{')
 	}
 
 Open:
 	for i < len(src) {
-		switch src[i] {
-		case '{':
+		switch {
+		case src[i] == '{':
 			depth++
+			if err := opts.checkDepth(typ, src, i, depth); err != nil {
+				return nil, nil, err
+			}
 			i++
-		case '}':
+		case src[i] == '}':
 			elems = make([][]byte, 0)
 			goto Close
+		case lenient && isArraySpace(src[i]):
+			i++
 		default:
 			break Open
 		}
 	}
-	dims = make([]int, i)
+	// The dimensions are those of the first sub-array at each depth, and
+	// counts and starts hold the number of elements so far and the offset
+	// of the current sub-array at each depth.
+	dims = make([]int, 3*depth)
+	counts, starts = dims[depth:2*depth], dims[2*depth:]
+	dims = dims[:depth:depth]
 
 Element:
 	for i < len(src) {
+		if lenient && isArraySpace(src[i]) {
+			i++
+			continue
+		}
+		if depth < len(dims) && src[i] != '{' {
+			return nil, nil, arrayParseErrorf(typ, src, i, "expected %q", '{')
+		}
 		switch src[i] {
 		case '{':
 			if depth == len(dims) {
 				break Element
 			}
 			depth++
-			dims[depth-1] = 0
+			counts[depth-1] = 0
+			starts[depth-1] = i
 			i++
 		case '"':
-			var elem = []byte{}
-			var escape bool
-			for i++; i < len(src); i++ {
-				if escape {
-					elem = append(elem, src[i])
-					escape = false
-				} else {
-					switch src[i] {
-					default:
-						elem = append(elem, src[i])
-					case '\\':
-						escape = true
-					case '"':
-						elems = append(elems, elem)
-						i++
-						break Element
-					}
-				}
+			start := i + 1
+			end, escaped := quotedEnd(src, start)
+			if end < 0 {
+				i = len(src)
+				break Element
 			}
+			elem := src[start:end:end]
+			if escaped {
+				elem = s.unescape(elem, len(src))
+			}
+			size += len(elem)
+			if err := opts.checkElems(typ, src, i, len(elems)+1, size); err != nil {
+				return nil, nil, err
+			}
+			elems = append(elems, elem)
+			i = end + 1
+			break Element
 		default:
-			for start := i; i < len(src); i++ {
-				if bytes.HasPrefix(src[i:], del) || src[i] == '}' {
-					elem := src[start:i]
-					if len(elem) == 0 {
-						return nil, nil, fmt.Errorf("pq: unable to parse array; unexpected %q at offset %d", src[i], i)
-					}
-					if bytes.Equal(elem, []byte("NULL")) {
-						elem = nil
-					}
-					elems = append(elems, elem)
-					break Element
+			// An unquoted element ends at the first delimiter or
+			// closing brace. The position of the next closing brace
+			// is kept between elements, so that it is searched for
+			// only once per sub-array.
+			if closeAt < i {
+				if closeAt = bytes.IndexByte(src[i:], '}'); closeAt < 0 {
+					closeAt = len(src)
+				} else {
+					closeAt += i
 				}
 			}
+			end := closeAt
+			if d := bytes.Index(src[i:closeAt], del); d >= 0 {
+				end = i + d
+			}
+			elem := src[i:end]
+			escaped := false
+			if b := bytes.IndexByte(elem, '\\'); b >= 0 {
+				end, elem = s.unescapeUnquoted(src, i, i+b, del, lenient)
+				escaped = true
+			}
+			if end == len(src) {
+				i = len(src)
+				break Element
+			}
+			if lenient && !escaped {
+				elem = trimArraySpace(elem)
+			}
+			if len(elem) == 0 {
+				return nil, nil, arrayParseErrorf(typ, src, i, "unexpected %q", src[i])
+			}
+			if !escaped && isNullElement(elem) {
+				elem = nil
+			}
+			size += len(elem)
+			if err := opts.checkElems(typ, src, i, len(elems)+1, size); err != nil {
+				return nil, nil, err
+			}
+			elems = append(elems, elem)
+			i = end
+			break Element
 		}
 	}
 
 	for i < len(src) {
 		if bytes.HasPrefix(src[i:], del) && depth > 0 {
-			dims[depth-1]++
+			counts[depth-1]++
 			i += len(del)
 			goto Element
 		} else if src[i] == '}' && depth > 0 {
-			dims[depth-1]++
+			n := counts[depth-1] + 1
+			if dims[depth-1] == 0 {
+				dims[depth-1] = n
+			} else if n != dims[depth-1] {
+				e := arrayParseErrorf(typ, src, starts[depth-1], "sub-array has %d elements, expected %d", n, dims[depth-1])
+				e.kind = ErrDimensionMismatch
+				return nil, nil, e
+			}
 			depth--
 			i++
+		} else if lenient && isArraySpace(src[i]) {
+			i++
+		} else if depth == 0 {
+			return nil, nil, trailingDataError(typ, src, i)
 		} else {
-			return nil, nil, fmt.Errorf("pq: unable to parse array; unexpected %q at offset %d", src[i], i)
+			return nil, nil, arrayParseErrorf(typ, src, i, "unexpected %q", src[i])
 		}
 	}
 
@@ -157,42 +346,190 @@ Close:
 		if src[i] == '}' && depth > 0 {
 			depth--
 			i++
+		} else if lenient && isArraySpace(src[i]) {
+			i++
+		} else if depth == 0 {
+			return nil, nil, trailingDataError(typ, src, i)
 		} else {
-			return nil, nil, fmt.Errorf("pq: unable to parse array; unexpected %q at offset %d", src[i], i)
+			return nil, nil, arrayParseErrorf(typ, src, i, "unexpected %q", src[i])
 		}
 	}
 	if depth > 0 {
-		err = fmt.Errorf("pq: unable to parse array; expected %q at offset %d", '}', i)
+		err = arrayParseErrorf(typ, src, i, "expected %q", '}')
+	}
+	s.elems = elems
+	return
+}
+
+// trailingDataError returns the error for the data at offset i of src, after
+// the closing brace of an array parsed into typ.
+func trailingDataError(typ string, src []byte, i int) *ParseError {
+	return arrayParseErrorf(typ, src, i, "unexpected %q after the end of the array", src[i])
+}
+
+// isNullElement reports whether the unquoted element elem is NULL, which
+// the server accepts in any case.
+func isNullElement(elem []byte) bool {
+	return len(elem) == len("NULL") && bytes.EqualFold(elem, []byte("NULL"))
+}
+
+// isArraySpace reports whether c is whitespace the server skips in array
+// input.
+func isArraySpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\v', '\f':
+		return true
 	}
-	if err == nil {
-		for _, d := range dims {
-			if (len(elems) % d) != 0 {
-				err = fmt.Errorf("pq: multidimensional arrays must have elements with matching dimensions")
+	return false
+}
+
+// skipArraySpace returns the offset of the first byte of src at or after i
+// that is not whitespace.
+func skipArraySpace(src []byte, i int) int {
+	for i < len(src) && isArraySpace(src[i]) {
+		i++
+	}
+	return i
+}
+
+// trimArraySpace returns elem without trailing whitespace.
+func trimArraySpace(elem []byte) []byte {
+	for len(elem) > 0 && isArraySpace(elem[len(elem)-1]) {
+		elem = elem[:len(elem)-1]
+	}
+	return elem
+}
+
+// countArrayElems estimates the number of elements of the array src,
+// counting the delimiters outside of quoted elements.
+func countArrayElems(src, del []byte) int {
+	n := 1
+	for i := 0; i < len(src); {
+		q := bytes.IndexByte(src[i:], '"')
+		if q < 0 {
+			n += bytes.Count(src[i:], del)
+			break
+		}
+		n += bytes.Count(src[i:i+q], del)
+		end, _ := quotedEnd(src, i+q+1)
+		if end < 0 {
+			break
+		}
+		i = end + 1
+	}
+	return n
+}
+
+// quotedEnd returns the offset of the quote ending the quoted element that
+// starts at offset i, just after its opening quote, or -1 if it is not
+// terminated. It also reports whether the element has backslash escapes.
+func quotedEnd(src []byte, i int) (end int, escaped bool) {
+	// The position of the next quote is kept while skipping escapes,
+	// unless the quote itself was escaped.
+	q := -1
+	for i <= len(src) {
+		if q < i {
+			if q = bytes.IndexByte(src[i:], '"'); q < 0 {
+				return -1, escaped
 			}
+			q += i
+		}
+		b := bytes.IndexByte(src[i:q], '\\')
+		if b < 0 {
+			return q, escaped
 		}
+		escaped = true
+		i += b + 2
 	}
-	return
+	return -1, escaped
+}
+
+// unescape appends the quoted element v with its backslash escapes removed
+// to s.buf and returns it. The buffer is sized to n, the length of the whole
+// source, up front so that earlier elements never move.
+func (s *arrayScratch) unescape(v []byte, n int) []byte {
+	if cap(s.buf) < n {
+		s.buf = make([]byte, 0, n)
+	}
+	start := len(s.buf)
+	for len(v) > 0 {
+		b := bytes.IndexByte(v, '\\')
+		if b < 0 {
+			s.buf = append(s.buf, v...)
+			break
+		}
+		if b == len(v)-1 {
+			s.buf = append(s.buf, v[:b]...)
+			break
+		}
+		s.buf = append(s.buf, v[:b]...)
+		s.buf = append(s.buf, v[b+1])
+		v = v[b+2:]
+	}
+	return s.buf[start:len(s.buf):len(s.buf)]
 }
 
-func scanLinearArray(src, del []byte, typ string) (elems [][]byte, err error) {
-	dims, elems, err := parseArray(src, del)
+// unescapeUnquoted parses the unquoted element starting at offset i of src,
+// whose first backslash is at offset b. It returns the offset of the
+// delimiter or closing brace ending the element, or len(src) if there is
+// none, and the element with its backslash escapes removed, appended to
+// s.buf. Escaped characters never end the element and are not trimmed as
+// whitespace.
+func (s *arrayScratch) unescapeUnquoted(src []byte, i, b int, del []byte, lenient bool) (end int, elem []byte) {
+	if cap(s.buf) < len(src) {
+		s.buf = make([]byte, 0, len(src))
+	}
+	start := len(s.buf)
+	s.buf = append(s.buf, src[i:b]...)
+	// keep is the length of the element without trailing whitespace.
+	keep := len(s.buf)
+	if lenient {
+		keep = start + len(trimArraySpace(src[i:b]))
+	}
+	for j := b; j < len(src); j++ {
+		switch c := src[j]; {
+		case c == '\\':
+			if j++; j == len(src) {
+				return len(src), nil
+			}
+			s.buf = append(s.buf, src[j])
+			keep = len(s.buf)
+		case c == '}' || bytes.HasPrefix(src[j:], del):
+			return j, s.buf[start:keep:keep]
+		default:
+			s.buf = append(s.buf, c)
+			if !lenient || !isArraySpace(c) {
+				keep = len(s.buf)
+			}
+		}
+	}
+	return len(src), nil
+}
+
+func scanLinearArray(src, del []byte, typ string, opts *ParseOptions) (elems [][]byte, err error) {
+	return new(arrayScratch).parseLinear(src, del, typ, opts)
+}
+
+func (s *arrayScratch) parseLinear(src, del []byte, typ string, opts *ParseOptions) (elems [][]byte, err error) {
+	dims, elems, err := s.parse(src, del, typ, opts)
 	if err != nil {
 		return nil, err
 	}
 	if len(dims) > 1 {
-		return nil, fmt.Errorf("pq: cannot convert ARRAY%s to %s", strings.Replace(fmt.Sprint(dims), " ", "][", -1), typ)
+		return nil, fmt.Errorf("%w: cannot convert ARRAY%s to %s", ErrDimensionMismatch, strings.Replace(fmt.Sprint(dims), " ", "][", -1), typ)
 	}
 	return elems, err
 }
 
-func parseBytea(s []byte) (result []byte, err error) {
+func parseBytea(src []byte) (result []byte, err error) {
+	s := src
 	if len(s) >= 2 && bytes.Equal(s[:2], []byte("\\x")) {
 		// bytea_output = hex
 		s = s[2:] // trim off leading "\\x"
 		result = make([]byte, hex.DecodedLen(len(s)))
 		_, err := hex.Decode(result, s)
 		if err != nil {
-			return nil, err
+			return nil, byteaHexError(src)
 		}
 	} else {
 		// bytea_output = escape
@@ -206,14 +543,11 @@ func parseBytea(s []byte) (result []byte, err error) {
 				}
 
 				// '\\' followed by an octal number
-				if len(s) < 4 {
-					return nil, fmt.Errorf("invalid bytea sequence %v", s)
-				}
-				r, err := strconv.ParseInt(string(s[1:4]), 8, 9)
+				c, err := byteaOctal(src, len(src)-len(s))
 				if err != nil {
-					return nil, fmt.Errorf("could not parse bytea value: %s", err.Error())
+					return nil, err
 				}
-				result = append(result, byte(r))
+				result = append(result, c)
 				s = s[4:]
 			} else {
 				// We hit an unescaped, raw byte.  Try to read in as many as
@@ -232,7 +566,38 @@ func parseBytea(s []byte) (result []byte, err error) {
 	return result, nil
 }
 
+// appendArrayElement appends v as an array element, leaving it unquoted when
+// that is safe.
+func appendArrayElement(b, v []byte) []byte {
+	if len(v) > 0 && bytes.IndexAny(v, "{}\",\\ \t\n\r\v\f") < 0 && !isNullElement(v) {
+		return append(b, v...)
+	}
+
+	return appendArrayQuotedBytes(b, v)
+}
+
+// appendArrayElementDelim is like appendArrayElement for an array whose
+// elements are separated by del.
+func appendArrayElementDelim(b, v, del []byte) []byte {
+	if len(del) == 1 && del[0] == ',' {
+		return appendArrayElement(b, v)
+	}
+	if len(v) > 0 && bytes.IndexAny(v, "{}\"\\ \t\n\r\v\f") < 0 && !bytes.Contains(v, del) && !isNullElement(v) {
+		return append(b, v...)
+	}
+
+	return appendArrayQuotedBytes(b, v)
+}
+
+// arrayQuotedLen returns the length of s as a quoted array element.
+func arrayQuotedLen(s string) int {
+	return len(s) + 2 + strings.Count(s, `"`) + strings.Count(s, `\`)
+}
+
 func appendArrayQuotedBytes(b, v []byte) []byte {
+	if n := len(v) + 2 + bytes.Count(v, []byte{'"'}) + bytes.Count(v, []byte{'\\'}); cap(b)-len(b) < n {
+		b = append(b, make([]byte, n)...)[:len(b)]
+	}
 	b = append(b, '"')
 	for {
 		i := bytes.IndexAny(v, `"\`)
@@ -0,0 +1,541 @@
+package pg
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStringArrayNull(t *testing.T) {
+	testScanNull(t, new(StringArray))
+
+	opts := &ParseOptions{ZeroCopy: true}
+	a := StringArray{Strings: []string{"old"}, Options: opts}
+	if err := a.Scan(nil); err != nil {
+		t.Fatal(err)
+	}
+	if a.Strings != nil || a.Options != opts {
+		t.Fatalf("Scan(nil) = %+v, want nil Strings and the same Options", a)
+	}
+	if v, err := (StringArray{Strings: []string{}}).Value(); err != nil || v != "{}" {
+		t.Fatalf("Value of an empty array = %#v, %v, want {}", v, err)
+	}
+}
+
+func TestStringArrayScanPooled(t *testing.T) {
+	// The scratch buffers are returned to the pool after each Scan, so
+	// the strings must not refer to them.
+	var a, b StringArray
+	if err := a.Scan([]byte(`{"a\"b","c\\d",e}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Scan([]byte(`{"x\"y","z\\w",v}`)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{`a"b`, `c\d`, "e"}; !reflect.DeepEqual(a.Strings, want) {
+		t.Fatalf("first Scan = %q after a second Scan, want %q", a.Strings, want)
+	}
+
+	src := []byte(`{abc,"d e"}`)
+	if err := a.Scan(src); err != nil {
+		t.Fatal(err)
+	}
+	copy(src, `{xyz,"u v"}`)
+	if want := []string{"abc", "d e"}; !reflect.DeepEqual(a.Strings, want) {
+		t.Fatalf("Scan = %q after changing its source, want %q", a.Strings, want)
+	}
+}
+
+func TestArrayScratchPool(t *testing.T) {
+	s := &arrayScratch{buf: make([]byte, 0, maxPooledArrayBuf+1)}
+	putArrayScratch(s)
+	for i := 0; i < 10; i++ {
+		if got := getArrayScratch(); got == s {
+			t.Fatal("oversized scratch buffer was pooled")
+		}
+	}
+
+	s = getArrayScratch()
+	if _, err := s.parseLinear([]byte(`{"a\"b",c}`), []byte{','}, "StringArray", nil); err != nil {
+		t.Fatal(err)
+	}
+	putArrayScratch(s)
+	if len(s.elems) != 0 || len(s.buf) != 0 {
+		t.Fatalf("pooled scratch has %d elements and %d bytes", len(s.elems), len(s.buf))
+	}
+}
+
+func TestStringArrayScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(StringArray), `{}`, ""},
+		{new(StringArray), `{a,b,c}`, ""},
+		{new(StringArray), `{"a b","","c,d","e\"f","g\\h","NULL"}`, ""},
+		{new(StringArray), `{"{}","(1,2)",é}`, ""},
+		{new(StringArray), `{"a",b}`, `{a,b}`},
+		{new(StringArray), `{ a , b }`, `{a,b}`},
+		{new(StringArray), `{"null","Null"}`, ""},
+		{new(StringArray), `{a\,b,c\\d}`, `{"a,b","c\\d"}`},
+	})
+}
+
+func TestStringArrayValueQuoting(t *testing.T) {
+	tests := []struct {
+		strings []string
+		want    string
+	}{
+		{[]string{"abc", "x-y_z.1", "é"}, `{abc,x-y_z.1,é}`},
+		{[]string{""}, `{""}`},
+		{[]string{"NULL", "null", "Null"}, `{"NULL","null","Null"}`},
+		{[]string{"NULLS", "nul"}, `{NULLS,nul}`},
+		{[]string{"a b", "a\tb", "a\nb"}, "{\"a b\",\"a\tb\",\"a\nb\"}"},
+		{[]string{"{", "}", ",", `"`, `\`}, `{"{","}",",","\"","\\"}`},
+	}
+	for _, tt := range tests {
+		v, err := StringArray{Strings: tt.strings}.Value()
+		if err != nil || v != tt.want {
+			t.Errorf("Value of %q = %v, %v, want %s", tt.strings, v, err, tt.want)
+		}
+	}
+}
+
+func TestCountArrayElems(t *testing.T) {
+	tests := []struct {
+		src  string
+		want int
+	}{
+		{`{}`, 1},
+		{`{a}`, 1},
+		{`{a,b,c}`, 3},
+		{`{"a,b",c}`, 2},
+		{`{"a\",b",c}`, 2},
+		{`{{1,2},{3,4}}`, 4},
+		{`{"a,b`, 1},
+	}
+	for _, tt := range tests {
+		if got := countArrayElems([]byte(tt.src), []byte{','}); got != tt.want {
+			t.Errorf("countArrayElems(%s) = %d, want %d", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseArrayPrealloc(t *testing.T) {
+	elems, err := ParseArray([]byte(`{a,"b,c",d,"e\"f"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(elems) != 4 || cap(elems) != 4 {
+		t.Errorf("got %d elements with capacity %d, want 4 and 4", len(elems), cap(elems))
+	}
+
+	// Delimiters are counted before the input is validated, so the
+	// preallocation is bounded by the length of the input.
+	var s arrayScratch
+	src := []byte("{" + strings.Repeat(",", 1000))
+	if _, err := s.parseLinear(src, []byte{','}, "StringArray", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if max := len(src)/2 + 1; cap(s.elems) > max {
+		t.Errorf("preallocated %d elements for %d bytes, want at most %d", cap(s.elems), len(src), max)
+	}
+}
+
+func TestStringArrayZeroCopy(t *testing.T) {
+	opts := &ParseOptions{ZeroCopy: true}
+	a := StringArray{Options: opts}
+	src := []byte(`{abc,"d\"e"}`)
+	if err := a.Scan(src); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"abc", `d"e`}
+	if !reflect.DeepEqual(a.Strings, want) {
+		t.Fatalf("got %q, want %q", a.Strings, want)
+	}
+
+	// Unescaped elements share memory with the source.
+	src[1] = 'x'
+	if a.Strings[0] != "xbc" {
+		t.Errorf("got %q, want the element to share memory with the source", a.Strings[0])
+	}
+
+	// Escaped elements refer to the scratch buffer, which must not be
+	// handed to the next Scan.
+	escaped := a.Strings[1]
+	var b StringArray
+	if err := b.Scan([]byte(`{"x\"yz"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if escaped != `d"e` {
+		t.Errorf("escaped element changed to %q by a later Scan", escaped)
+	}
+}
+
+func TestStringArrayBinary(t *testing.T) {
+	for _, want := range [][]string{{}, {"a"}, {"", `"b,c"`, "NULL", "{d}"}} {
+		b, err := StringArray{Strings: want}.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var a StringArray
+		if err := a.Scan(b); err != nil {
+			t.Errorf("Scan(%v): %v", want, err)
+			continue
+		}
+		if len(a.Strings) != len(want) || (len(want) > 0 && !reflect.DeepEqual(a.Strings, want)) {
+			t.Errorf("got %q, want %q", a.Strings, want)
+		}
+	}
+
+	// int4[] cannot be scanned into a StringArray.
+	b := appendBinaryArray(nil, 23, [][]byte{{0, 0, 0, 1}})
+	var a StringArray
+	if err := a.Scan(b); err == nil || !strings.Contains(err.Error(), "OID 23") {
+		t.Errorf("got error %v, want an error naming OID 23", err)
+	}
+
+	// Two-dimensional arrays do not fit.
+	b = appendUint32(nil, 2)
+	b = appendUint32(b, 0)
+	b = appendUint32(b, textOID)
+	for i := 0; i < 2; i++ {
+		b = appendUint32(b, 1)
+		b = appendUint32(b, 1)
+	}
+	b = appendUint32(b, 1)
+	b = append(b, 'a')
+	if err := a.Scan(b); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("got error %v, want ErrDimensionMismatch", err)
+	}
+
+	valid, _ := StringArray{Strings: []string{"ab"}}.MarshalBinary()
+	for _, src := range [][]byte{
+		valid[:len(valid)-1],
+		append(valid[:len(valid):len(valid)], 'x'),
+		valid[:16],
+	} {
+		if err := new(StringArray).Scan(src); err == nil {
+			t.Errorf("Scan(%x): expected error", src)
+		}
+	}
+}
+
+func TestQuotedEnd(t *testing.T) {
+	tests := []struct {
+		src     string
+		end     int
+		escaped bool
+	}{
+		{`"`, 0, false},
+		{`abc"`, 3, false},
+		{`abc",d"`, 3, false},
+		{`a\"b"`, 4, true},
+		{`a\\"`, 3, true},
+		{`\\\""`, 4, true},
+		{`a\"`, -1, true},
+		{`abc`, -1, false},
+		{`a\`, -1, false},
+		{``, -1, false},
+	}
+	for _, tt := range tests {
+		end, escaped := quotedEnd([]byte(tt.src), 0)
+		if end != tt.end || escaped != tt.escaped {
+			t.Errorf("quotedEnd(%s) = %d, %v, want %d, %v", tt.src, end, escaped, tt.end, tt.escaped)
+		}
+	}
+}
+
+func TestStringArrayScanElementEnds(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string
+	}{
+		{`{a,bc,def}`, []string{"a", "bc", "def"}},
+		{`{"a\"",b,"}"}`, []string{`a"`, "b", "}"}},
+		{`{"\\\\",",",b}`, []string{`\\`, ",", "b"}},
+		{`{"",""}`, []string{"", ""}},
+	}
+	for _, tt := range tests {
+		var a StringArray
+		if err := a.Scan([]byte(tt.src)); err != nil {
+			t.Errorf("Scan(%s): %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(a.Strings, tt.want) {
+			t.Errorf("Scan(%s) = %q, want %q", tt.src, a.Strings, tt.want)
+		}
+	}
+
+	// The closing brace found for the first element of a sub-array is
+	// not the one of the next sub-array.
+	_, elems, err := ParseArrayDims([]byte(`{{a,b},{c,d}}`), ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}; !reflect.DeepEqual(elems, want) {
+		t.Errorf("got %q, want %q", elems, want)
+	}
+
+	testScanInvalid(t, func() sql.Scanner { return new(StringArray) },
+		`{"a\"}`,
+		`{a\`,
+		`{"a"b}`,
+		`{a,`,
+	)
+}
+
+func TestArrayQuotedLen(t *testing.T) {
+	for _, s := range []string{"", "a", `"`, `\`, `a"b\c`, `""\\`, "a b", "NULL", "{x,y}"} {
+		if got, want := arrayQuotedLen(s), len(appendArrayQuotedBytes(nil, []byte(s))); got != want {
+			t.Errorf("arrayQuotedLen(%q) = %d, want %d", s, got, want)
+		}
+		// Elements that need no quotes are shorter.
+		if n := len(appendArrayElement(nil, []byte(s))); n > arrayQuotedLen(s) {
+			t.Errorf("%q: element takes %d bytes, more than its quoted length %d", s, n, arrayQuotedLen(s))
+		}
+	}
+}
+
+func TestStringArrayValueAllocs(t *testing.T) {
+	a := StringArray{Strings: []string{"a", `b"c`, `d\e`, "f g", "NULL", ""}}
+	// The buffer is sized once, and converted to a string that is boxed
+	// in the driver.Value.
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := a.Value(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > 3 {
+		t.Errorf("Value allocated %v times, want at most 3", allocs)
+	}
+}
+
+func TestStringArrayScanReuse(t *testing.T) {
+	var a StringArray
+	if err := a.Scan([]byte(`{a,b,c}`)); err != nil {
+		t.Fatal(err)
+	}
+	first := &a.Strings[0]
+
+	// A shorter array reuses the backing array.
+	if err := a.Scan([]byte(`{d,e}`)); err != nil {
+		t.Fatal(err)
+	}
+	if &a.Strings[0] != first {
+		t.Error("backing array was not reused")
+	}
+	if want := []string{"d", "e"}; !reflect.DeepEqual(a.Strings, want) {
+		t.Errorf("got %q, want %q", a.Strings, want)
+	}
+
+	// An empty array is not NULL.
+	if err := a.Scan([]byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if a.Strings == nil || len(a.Strings) != 0 {
+		t.Errorf("got %#v, want an empty slice", a.Strings)
+	}
+
+	// A longer array does not fit.
+	if err := a.Scan([]byte(`{f,g,h,i}`)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"f", "g", "h", "i"}; !reflect.DeepEqual(a.Strings, want) {
+		t.Errorf("got %q, want %q", a.Strings, want)
+	}
+
+	// NULL drops the backing array, so an empty array scanned next is
+	// not nil.
+	if err := a.Scan(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Scan([]byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if a.Strings == nil {
+		t.Error("empty array scanned after NULL is nil")
+	}
+}
+
+func TestStringArrayScanStrict(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string
+	}{
+		{`{a,b}`, []string{"a", "b"}},
+		{`{ a , b }`, []string{" a ", " b "}},
+		{`{a, b}`, []string{"a", " b"}},
+		{`{" a ","b"}`, []string{" a ", "b"}},
+	}
+	for _, tt := range tests {
+		a := StringArray{Options: &ParseOptions{Strict: true}}
+		if err := a.Scan([]byte(tt.src)); err != nil {
+			t.Errorf("Scan(%s): %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(a.Strings, tt.want) {
+			t.Errorf("Scan(%s) = %q, want %q", tt.src, a.Strings, tt.want)
+		}
+	}
+
+	testScanInvalid(t, func() sql.Scanner { return &StringArray{Options: &ParseOptions{Strict: true}} },
+		` {a}`,
+		`{a} `,
+		`{"a" ,b}`,
+	)
+}
+
+func TestStringArrayScan(t *testing.T) {
+	a := StringArray{Strings: []string{"old"}}
+	if err := a.Scan(nil); err != nil || a.Strings != nil {
+		t.Fatalf("Scan(nil) = %q, %v, want nil", a.Strings, err)
+	}
+
+	if err := a.Scan(`{a,NULL,b}`); !errors.Is(err, ErrNullElement) {
+		t.Fatalf("Scan of a NULL element = %q, %v, want ErrNullElement", a.Strings, err)
+	}
+	for policy, want := range map[NullPolicy][]string{
+		NullZero: {"a", "", "b"},
+		NullSkip: {"a", "b"},
+	} {
+		a.Options = &ParseOptions{Null: policy}
+		if err := a.Scan(`{a,NULL,b}`); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(a.Strings, want) {
+			t.Fatalf("Scan with NullPolicy %d = %q, want %q", policy, a.Strings, want)
+		}
+	}
+
+	if err := a.Scan(`{{a},{b}}`); err == nil {
+		t.Fatalf("Scan of a two-dimensional array = %q, want error", a.Strings)
+	}
+}
+
+func TestNullPolicy(t *testing.T) {
+	for policy, want := range map[NullPolicy][]Citext{
+		NullZero: {"A", "", "b"},
+		NullSkip: {"A", "b"},
+	} {
+		a := CitextArray{Options: &ParseOptions{Null: policy}}
+		if err := a.Scan([]byte(`{A,null,b}`)); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(a.Citexts, want) {
+			t.Errorf("Scan with NullPolicy %d = %q, want %q", policy, a.Citexts, want)
+		}
+	}
+
+	// NullSkip also applies to binary arrays.
+	b := appendBinaryArray(nil, textOID, [][]byte{[]byte("a"), nil})
+	a := StringArray{Options: &ParseOptions{Null: NullSkip}}
+	if err := a.Scan(b); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a"}; !reflect.DeepEqual(a.Strings, want) {
+		t.Errorf("got %q, want %q", a.Strings, want)
+	}
+	a.Options = nil
+	if err := a.Scan(b); !errors.Is(err, ErrNullElement) {
+		t.Errorf("error = %v, want %v", err, ErrNullElement)
+	}
+}
+
+func FuzzParseArray(f *testing.F) {
+	for _, s := range []string{
+		`{}`,
+		`{a,b,c}`,
+		`{"a,b","c\"d","e\\f"}`,
+		`{a\,b,c\\d}`,
+		`{NULL,null,"NULL",""}`,
+		`{{1,2},{3,4}}`,
+		`{ a , "b" }`,
+		`[1:2]={a,b}`,
+		`{"unterminated`,
+		`{a,}`,
+		`{a}}`,
+		`{{a},b}`,
+		`{\`,
+		``,
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, src []byte) {
+		elems, err := ParseArray(src)
+		if err != nil {
+			return
+		}
+		out := FormatArray(elems)
+		got, err := ParseArray([]byte(out))
+		if err != nil {
+			t.Fatalf("ParseArray(FormatArray(%q)) = %q: %v", src, out, err)
+		}
+		if !equalElems(got, elems) {
+			t.Fatalf("ParseArray(%q) = %q, FormatArray = %q parsed as %q", src, elems, out, got)
+		}
+	})
+}
+
+// equalElems reports whether a and b hold the same elements, telling NULL
+// elements from empty ones.
+func equalElems(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if (a[i] == nil) != (b[i] == nil) || !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// benchArray is a text array of short elements, some of them quoted.
+var benchArray = func() []byte {
+	b := []byte{'{'}
+	for i := 0; i < 100; i++ {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if i%10 == 0 {
+			b = append(b, `"quoted, \"escaped\""`...)
+		} else {
+			b = append(b, "element"...)
+		}
+	}
+	return append(b, '}')
+}()
+
+func BenchmarkParseArray(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchArray)))
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseArray(benchArray); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStringArrayScan(b *testing.B) {
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(benchArray)))
+		var a StringArray
+		for i := 0; i < b.N; i++ {
+			if err := a.Scan(benchArray); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("BatchScanner", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(benchArray)))
+		bs := NewBatchScanner[StringArray]()
+		for i := 0; i < b.N; i++ {
+			if err := bs.Scan(benchArray); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TID represents a PostgreSQL tid value, such as the "(0,1)" of a ctid
+// column: the physical location of a row version as a block number and an
+// item offset within the block. Use a *TID to scan nullable columns.
+type TID struct {
+	Block  uint32
+	Offset uint16
+}
+
+// Scan implements the sql.Scanner interface.
+func (t *TID) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return t.scanString(string(src))
+	case string:
+		return t.scanString(src)
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to TID", src)
+}
+
+func (t *TID) scanString(src string) error {
+	s := strings.TrimSpace(src)
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return parseError("TID", src, -1, nil)
+	}
+	block, offset, ok := strings.Cut(s[1:len(s)-1], ",")
+	if !ok {
+		return parseError("TID", src, -1, nil)
+	}
+	b, err := strconv.ParseUint(strings.TrimSpace(block), 10, 32)
+	if err != nil {
+		return parseErrorf("TID", src, -1, "invalid block number")
+	}
+	o, err := strconv.ParseUint(strings.TrimSpace(offset), 10, 16)
+	if err != nil {
+		return parseErrorf("TID", src, -1, "invalid offset")
+	}
+
+	*t = TID{Block: uint32(b), Offset: uint16(o)}
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (t TID) Value() (driver.Value, error) {
+	return t.String(), nil
+}
+
+// String returns t in the server's output format, such as "(0,1)".
+func (t TID) String() string {
+	b := strconv.AppendUint([]byte{'('}, uint64(t.Block), 10)
+	b = append(b, ',')
+	b = strconv.AppendUint(b, uint64(t.Offset), 10)
+	return string(append(b, ')'))
+}
+
+// Compare returns -1, 0 or 1 depending on whether t is before, at or after
+// o in physical order, like the server's tid comparison operators.
+func (t TID) Compare(o TID) int {
+	if c := compareInt(int64(t.Block), int64(o.Block)); c != 0 {
+		return c
+	}
+	return compareInt(int64(t.Offset), int64(o.Offset))
+}
+
+// Less reports whether t is before o in physical order.
+func (t TID) Less(o TID) bool {
+	return t.Compare(o) < 0
+}
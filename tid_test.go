@@ -0,0 +1,46 @@
+package pg
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestTIDScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(TID), `(0,1)`, ""},
+		{new(TID), `(4294967295,65535)`, ""},
+		{new(TID), ` ( 12 , 3 ) `, `(12,3)`},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(TID) },
+		``,
+		`()`,
+		`(0)`,
+		`0,1`,
+		`(0,1`,
+		`(4294967296,1)`,
+		`(0,65536)`,
+		`(-1,1)`,
+		`(a,1)`,
+	)
+	if err := new(TID).Scan(nil); err == nil {
+		t.Error("expected error scanning NULL into TID")
+	}
+}
+
+func TestTIDCompare(t *testing.T) {
+	for _, tt := range []struct {
+		a, b TID
+		want int
+	}{
+		{TID{0, 1}, TID{0, 1}, 0},
+		{TID{0, 1}, TID{0, 2}, -1},
+		{TID{1, 1}, TID{0, 9}, 1},
+	} {
+		if got := tt.a.Compare(tt.b); got != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := tt.a.Less(tt.b); got != (tt.want < 0) {
+			t.Errorf("Less(%s, %s) = %v", tt.a, tt.b, got)
+		}
+	}
+}
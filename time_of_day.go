@@ -0,0 +1,153 @@
+package pg
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+)
+
+// usPerDay is the number of microseconds in a day. A time of day may equal
+// it, which the server writes as 24:00:00.
+const usPerDay = 24 * usPerHour
+
+// Time represents a PostgreSQL time value, a time of day without a date or
+// zone, as microseconds since midnight. Use a *Time to scan nullable
+// columns.
+type Time struct {
+	Microseconds int64
+}
+
+// Scan implements the sql.Scanner interface.
+func (t *Time) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return t.scanBytes(src)
+	case string:
+		return t.scanBytes([]byte(src))
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Time", src)
+}
+
+func (t *Time) scanBytes(src []byte) error {
+	s := bytes.TrimSpace(src)
+	us, i, err := parseTimeOfDay(s)
+	if err == nil && i != len(s) {
+		err = fmt.Errorf("unexpected %q at offset %d", s[i], i)
+	}
+	if err != nil {
+		return parseError("Time", src, -1, err)
+	}
+
+	t.Microseconds = us
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (t Time) Value() (driver.Value, error) {
+	b, err := t.AppendValue(nil)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// AppendValue appends the text of t to dst, like Value but without
+// allocating a string.
+func (t Time) AppendValue(dst []byte) ([]byte, error) {
+	if t.Microseconds < 0 || t.Microseconds > usPerDay {
+		return dst, fmt.Errorf("pg: invalid Time: %d microseconds out of range", t.Microseconds)
+	}
+
+	return appendTimeOfDay(dst, t.Microseconds), nil
+}
+
+// String returns t in the server's output format, such as "04:05:06.789".
+func (t Time) String() string {
+	return string(appendTimeOfDay(nil, t.Microseconds))
+}
+
+// TimeTz represents a PostgreSQL time with time zone value, as microseconds
+// since midnight and a zone offset in seconds east of UTC. Use a *TimeTz to
+// scan nullable columns.
+type TimeTz struct {
+	Microseconds int64
+	Offset       int
+}
+
+// Scan implements the sql.Scanner interface.
+func (t *TimeTz) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return t.scanBytes(src)
+	case string:
+		return t.scanBytes([]byte(src))
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to TimeTz", src)
+}
+
+func (t *TimeTz) scanBytes(src []byte) error {
+	s := bytes.TrimSpace(src)
+	us, i, err := parseTimeOfDay(s)
+	var offset int
+	if err == nil {
+		offset, i, err = parseZoneOffset(s, i)
+	}
+	if err == nil && i != len(s) {
+		err = fmt.Errorf("unexpected %q at offset %d", s[i], i)
+	}
+	if err != nil {
+		return parseError("TimeTz", src, -1, err)
+	}
+
+	*t = TimeTz{Microseconds: us, Offset: offset}
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (t TimeTz) Value() (driver.Value, error) {
+	b, err := t.AppendValue(nil)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// AppendValue appends the text of t to dst, like Value but without
+// allocating a string.
+func (t TimeTz) AppendValue(dst []byte) ([]byte, error) {
+	if t.Microseconds < 0 || t.Microseconds > usPerDay {
+		return dst, fmt.Errorf("pg: invalid TimeTz: %d microseconds out of range", t.Microseconds)
+	}
+	if t.Offset <= -16*3600 || t.Offset >= 16*3600 {
+		return dst, fmt.Errorf("pg: invalid TimeTz: zone offset %d out of range", t.Offset)
+	}
+
+	return appendZoneOffset(appendTimeOfDay(dst, t.Microseconds), t.Offset), nil
+}
+
+// String returns t in the server's output format, such as
+// "04:05:06.789+05:30".
+func (t TimeTz) String() string {
+	return string(appendZoneOffset(appendTimeOfDay(nil, t.Microseconds), t.Offset))
+}
+
+// parseTimeOfDay parses HH:MM:SS with an optional fractional part at the
+// start of s, returning it in microseconds since midnight.
+func parseTimeOfDay(s []byte) (us int64, end int, err error) {
+	hour, min, sec, nsec, end, err := parseTimePart(s, 0)
+	if err != nil {
+		return 0, end, err
+	}
+	// The server rounds fractions to microseconds.
+	us = int64(hour)*usPerHour + int64(min)*usPerMinute + int64(sec)*usPerSecond + (int64(nsec)+500)/1000
+	if us > usPerDay {
+		return 0, end, fmt.Errorf("time field value out of range")
+	}
+	return us, end, nil
+}
+
+func appendTimeOfDay(b []byte, us int64) []byte {
+	return appendClock(b, int(us/usPerHour), int(us%usPerHour/usPerMinute), int(us%usPerMinute/usPerSecond), int(us%usPerSecond)*1000)
+}
@@ -0,0 +1,62 @@
+package pg
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestTimeScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Time), `00:00:00`, ""},
+		{new(Time), `04:05:06`, ""},
+		{new(Time), `04:05:06.789`, ""},
+		{new(Time), `04:05:06.0000005`, `04:05:06.000001`},
+		{new(Time), `23:59:59.999999`, ""},
+		{new(Time), `24:00:00`, ""},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(Time) },
+		``,
+		`4:05:06`,
+		`04:05`,
+		`04:60:00`,
+		`24:00:01`,
+		`04:05:06+02`,
+	)
+	if err := new(Time).Scan(nil); err == nil {
+		t.Error("expected error scanning NULL into Time")
+	}
+
+	for _, us := range []int64{-1, usPerDay + 1} {
+		if _, err := (Time{Microseconds: us}).Value(); err == nil {
+			t.Errorf("Value of %d microseconds: expected error", us)
+		}
+	}
+}
+
+func TestTimeTzScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(TimeTz), `04:05:06+00:00`, ""},
+		{new(TimeTz), `04:05:06.789+05:30`, ""},
+		{new(TimeTz), `04:05:06-08`, `04:05:06-08:00`},
+		{new(TimeTz), `04:05:06+0530`, `04:05:06+05:30`},
+		{new(TimeTz), `04:05:06+00:09:21`, ""},
+		{new(TimeTz), `24:00:00-15:59`, ""},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(TimeTz) },
+		``,
+		`04:05:06`,
+		`04:05:06+16`,
+		`04:05:06 PST`,
+	)
+
+	var tz TimeTz
+	if err := tz.Scan("04:05:06.5-08:00"); err != nil {
+		t.Fatal(err)
+	}
+	if want := (TimeTz{Microseconds: 4*usPerHour + 5*usPerMinute + 6500000, Offset: -8 * 3600}); tz != want {
+		t.Errorf("Scan = %+v, want %+v", tz, want)
+	}
+	if _, err := (TimeTz{Offset: 16 * 3600}).Value(); err == nil {
+		t.Error("expected error for an offset of 16 hours")
+	}
+}
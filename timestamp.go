@@ -0,0 +1,120 @@
+package pg
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Timestamp represents a PostgreSQL timestamp or timestamptz value,
+// including the special values infinity and -infinity, for which Infinite
+// is 1 or -1 and Time is unused. Values without a zone offset, as timestamp
+// columns are output, are scanned as UTC. Use a *Timestamp to scan nullable
+// columns.
+type Timestamp struct {
+	Time     time.Time
+	Infinite int
+}
+
+// Scan implements the sql.Scanner interface.
+func (t *Timestamp) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case time.Time:
+		*t = Timestamp{Time: src}
+		return nil
+	case []byte:
+		return t.scanBytes(src)
+	case string:
+		return t.scanBytes([]byte(src))
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Timestamp", src)
+}
+
+func (t *Timestamp) scanBytes(src []byte) error {
+	if inf := infinitySign(src); inf != 0 {
+		*t = Timestamp{Infinite: inf}
+		return nil
+	}
+
+	v, err := parseTimestamp(src, time.UTC)
+	if err != nil {
+		return err
+	}
+
+	*t = Timestamp{Time: v}
+	return nil
+}
+
+// Value implements the driver.Valuer interface. Finite values are sent with
+// the zone offset of Time, which the server ignores for timestamp columns.
+func (t Timestamp) Value() (driver.Value, error) {
+	b, err := t.AppendValue(nil)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// AppendValue appends the text of t to dst, like Value but without
+// allocating a string.
+func (t Timestamp) AppendValue(dst []byte) ([]byte, error) {
+	switch {
+	case t.Infinite > 0:
+		return append(dst, "infinity"...), nil
+	case t.Infinite < 0:
+		return append(dst, "-infinity"...), nil
+	}
+
+	return appendTimestamp(dst, t.Time, true), nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. A finite value is
+// encoded like a time.Time, and an infinite one as the string "infinity" or
+// "-infinity".
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	switch {
+	case t.Infinite > 0:
+		return []byte(`"infinity"`), nil
+	case t.Infinite < 0:
+		return []byte(`"-infinity"`), nil
+	}
+	return json.Marshal(t.Time)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if inf := infinitySign([]byte(s)); inf != 0 {
+		*t = Timestamp{Infinite: inf}
+		return nil
+	}
+	var v time.Time
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*t = Timestamp{Time: v}
+	return nil
+}
+
+// infinitySign returns 1 for infinity, -1 for -infinity and 0 for anything
+// else.
+func infinitySign(src []byte) int {
+	s := bytes.TrimSpace(src)
+	switch {
+	case bytes.EqualFold(s, []byte("infinity")), bytes.EqualFold(s, []byte("+infinity")):
+		return 1
+	case bytes.EqualFold(s, []byte("-infinity")):
+		return -1
+	}
+	return 0
+}
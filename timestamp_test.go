@@ -0,0 +1,62 @@
+package pg
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestampScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Timestamp), `2006-01-02 15:04:05`, `2006-01-02 15:04:05+00:00`},
+		{new(Timestamp), `2006-01-02 15:04:05.123456+02`, `2006-01-02 15:04:05.123456+02:00`},
+		{new(Timestamp), `0044-03-15 12:00:00 BC`, `0044-03-15 12:00:00+00:00 BC`},
+		{new(Timestamp), `infinity`, ""},
+		{new(Timestamp), `-infinity`, ""},
+		{new(Timestamp), `Infinity`, `infinity`},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(Timestamp) },
+		``,
+		`infinite`,
+		`2006-01-02 15:04:05 xyz`,
+	)
+	if err := new(Timestamp).Scan(nil); err == nil {
+		t.Error("expected error scanning NULL into Timestamp")
+	}
+}
+
+func TestTimestampScanTime(t *testing.T) {
+	now := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	var ts Timestamp
+	if err := ts.Scan(now); err != nil {
+		t.Fatal(err)
+	}
+	if ts.Infinite != 0 || !ts.Time.Equal(now) {
+		t.Errorf("Scan(time.Time) = %+v", ts)
+	}
+}
+
+func TestTimestampJSON(t *testing.T) {
+	for _, tt := range []struct {
+		ts   Timestamp
+		data string
+	}{
+		{Timestamp{Time: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)}, `"2006-01-02T15:04:05Z"`},
+		{Timestamp{Infinite: 1}, `"infinity"`},
+		{Timestamp{Infinite: -1}, `"-infinity"`},
+	} {
+		b, err := json.Marshal(tt.ts)
+		if err != nil || string(b) != tt.data {
+			t.Errorf("Marshal(%+v) = %s, %v, want %s", tt.ts, b, err, tt.data)
+		}
+		var got Timestamp
+		if err := json.Unmarshal([]byte(tt.data), &got); err != nil || !got.Time.Equal(tt.ts.Time) || got.Infinite != tt.ts.Infinite {
+			t.Errorf("Unmarshal(%s) = %+v, %v", tt.data, got, err)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`"yesterday"`), new(Timestamp)); err == nil {
+		t.Error("expected error for an invalid timestamp")
+	}
+}
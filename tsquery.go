@@ -0,0 +1,355 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TSQueryOp is the kind of a TSQueryNode.
+type TSQueryOp uint8
+
+// The kinds of tsquery nodes.
+const (
+	TSQueryLexeme TSQueryOp = iota
+	TSQueryNot
+	TSQueryAnd
+	TSQueryOr
+	TSQueryPhrase
+)
+
+// maxTSDistance is the largest distance of a phrase operator.
+const maxTSDistance = 16384
+
+// TSQueryNode is a node of a tsquery expression tree. A TSQueryLexeme node
+// matches Lexeme, or any lexeme starting with it if Prefix is set, and only
+// with one of the weights in Weights, such as "AB", if it is not empty. A
+// TSQueryNot node negates Left. The binary nodes combine Left and Right; for
+// TSQueryPhrase, Right must follow Left at Distance positions.
+type TSQueryNode struct {
+	Op       TSQueryOp
+	Lexeme   string
+	Prefix   bool
+	Weights  string
+	Distance int
+	Left     *TSQueryNode
+	Right    *TSQueryNode
+}
+
+// TSLexeme returns a node matching lexeme.
+func TSLexeme(lexeme string) *TSQueryNode {
+	return &TSQueryNode{Op: TSQueryLexeme, Lexeme: lexeme}
+}
+
+// And returns a node matching both n and o, like the & operator.
+func (n *TSQueryNode) And(o *TSQueryNode) *TSQueryNode {
+	return &TSQueryNode{Op: TSQueryAnd, Left: n, Right: o}
+}
+
+// Or returns a node matching either n or o, like the | operator.
+func (n *TSQueryNode) Or(o *TSQueryNode) *TSQueryNode {
+	return &TSQueryNode{Op: TSQueryOr, Left: n, Right: o}
+}
+
+// Phrase returns a node matching o distance positions after n, like the
+// <N> operator. A distance of 1 is the <-> operator.
+func (n *TSQueryNode) Phrase(o *TSQueryNode, distance int) *TSQueryNode {
+	return &TSQueryNode{Op: TSQueryPhrase, Distance: distance, Left: n, Right: o}
+}
+
+// Not returns a node matching where n does not, like the ! operator.
+func (n *TSQueryNode) Not() *TSQueryNode {
+	return &TSQueryNode{Op: TSQueryNot, Left: n}
+}
+
+// TSQuery represents a PostgreSQL tsquery value, such as
+// `'fat' & ( 'rat' | 'cat' )`. A nil Root is an empty query. Use a *TSQuery
+// to scan nullable columns.
+type TSQuery struct {
+	Root *TSQueryNode
+}
+
+// Scan implements the sql.Scanner interface.
+func (q *TSQuery) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return q.scanBytes(src)
+	case string:
+		return q.scanBytes([]byte(src))
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to TSQuery", src)
+}
+
+func (q *TSQuery) scanBytes(src []byte) error {
+	p := tsQueryParser{src: src}
+	var root *TSQueryNode
+	if p.skipSpace(); p.pos < len(p.src) {
+		var err error
+		if root, err = p.or(); err != nil {
+			return err
+		}
+		if p.skipSpace(); p.pos < len(p.src) {
+			return p.errorf("unexpected %q", p.src[p.pos])
+		}
+	}
+
+	q.Root = root
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (q TSQuery) Value() (driver.Value, error) {
+	if q.Root == nil {
+		return "", nil
+	}
+	b, err := appendTSQueryNode(nil, q.Root, 0, false)
+	if err != nil {
+		return nil, fmt.Errorf("pg: invalid TSQuery: %w", err)
+	}
+	return string(b), nil
+}
+
+// String returns q in the server's output format.
+func (q TSQuery) String() string {
+	v, err := q.Value()
+	if err != nil {
+		return err.Error()
+	}
+	return v.(string)
+}
+
+func (op TSQueryOp) priority() int {
+	switch op {
+	case TSQueryOr:
+		return 1
+	case TSQueryAnd:
+		return 2
+	case TSQueryPhrase:
+		return 3
+	case TSQueryNot:
+		return 4
+	}
+	return 5
+}
+
+// appendTSQueryNode appends n the way the server formats it, adding
+// parentheses where the priority of n is lower than that of its parent, or
+// where n is a phrase on the right of another phrase.
+func appendTSQueryNode(b []byte, n *TSQueryNode, parent int, rightOfPhrase bool) ([]byte, error) {
+	if n == nil {
+		return nil, fmt.Errorf("missing operand")
+	}
+
+	if n.Op == TSQueryLexeme {
+		if n.Lexeme == "" {
+			return nil, fmt.Errorf("empty lexeme")
+		}
+		b = appendTSLexeme(b, n.Lexeme)
+		weights := strings.ToUpper(n.Weights)
+		if strings.Trim(weights, "ABCD") != "" {
+			return nil, fmt.Errorf("weights %q of %q are not among A, B, C, D", n.Weights, n.Lexeme)
+		}
+		if n.Prefix || weights != "" {
+			b = append(b, ':')
+			if n.Prefix {
+				b = append(b, '*')
+			}
+			for _, w := range []byte("ABCD") {
+				if strings.IndexByte(weights, w) >= 0 {
+					b = append(b, w)
+				}
+			}
+		}
+		return b, nil
+	}
+
+	priority := n.Op.priority()
+	paren := priority < parent || (n.Op == TSQueryPhrase && rightOfPhrase)
+	if paren {
+		b = append(b, "( "...)
+	}
+
+	var err error
+	switch n.Op {
+	case TSQueryNot:
+		b = append(b, '!')
+		if b, err = appendTSQueryNode(b, n.Left, priority, false); err != nil {
+			return nil, err
+		}
+	case TSQueryAnd, TSQueryOr, TSQueryPhrase:
+		if b, err = appendTSQueryNode(b, n.Left, priority, false); err != nil {
+			return nil, err
+		}
+		switch n.Op {
+		case TSQueryAnd:
+			b = append(b, " & "...)
+		case TSQueryOr:
+			b = append(b, " | "...)
+		default:
+			if n.Distance < 0 || n.Distance > maxTSDistance {
+				return nil, fmt.Errorf("distance %d out of range", n.Distance)
+			}
+			if n.Distance == 1 {
+				b = append(b, " <-> "...)
+			} else {
+				b = append(b, " <"...)
+				b = strconv.AppendInt(b, int64(n.Distance), 10)
+				b = append(b, "> "...)
+			}
+		}
+		if b, err = appendTSQueryNode(b, n.Right, priority, n.Op == TSQueryPhrase); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown operator %d", n.Op)
+	}
+
+	if paren {
+		b = append(b, " )"...)
+	}
+	return b, nil
+}
+
+// tsQueryParser is a recursive descent parser for the tsquery text format.
+// From lowest to highest the operators bind as |, &, <N> and !.
+type tsQueryParser struct {
+	src []byte
+	pos int
+}
+
+func (p *tsQueryParser) skipSpace() {
+	p.pos = skipSpace(p.src, p.pos)
+}
+
+func (p *tsQueryParser) errorf(format string, args ...interface{}) error {
+	return parseErrorf("TSQuery", p.src, p.pos, format, args...)
+}
+
+// accept consumes c if it is the next non-space byte.
+func (p *tsQueryParser) accept(c byte) bool {
+	if p.skipSpace(); p.pos < len(p.src) && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *tsQueryParser) or() (*TSQueryNode, error) {
+	n, err := p.and()
+	for err == nil && p.accept('|') {
+		var r *TSQueryNode
+		if r, err = p.and(); err == nil {
+			n = n.Or(r)
+		}
+	}
+	return n, err
+}
+
+func (p *tsQueryParser) and() (*TSQueryNode, error) {
+	n, err := p.phrase()
+	for err == nil && p.accept('&') {
+		var r *TSQueryNode
+		if r, err = p.phrase(); err == nil {
+			n = n.And(r)
+		}
+	}
+	return n, err
+}
+
+func (p *tsQueryParser) phrase() (*TSQueryNode, error) {
+	n, err := p.not()
+	for err == nil && p.accept('<') {
+		distance := 1
+		if p.pos < len(p.src) && p.src[p.pos] == '-' {
+			p.pos++
+		} else {
+			var end int
+			if distance, end, err = parseDigits(p.src, p.pos, -1); err != nil || distance > maxTSDistance {
+				return nil, p.errorf("invalid phrase distance")
+			}
+			p.pos = end
+		}
+		if p.pos >= len(p.src) || p.src[p.pos] != '>' {
+			return nil, p.errorf("expected %q", '>')
+		}
+		p.pos++
+		var r *TSQueryNode
+		if r, err = p.not(); err == nil {
+			n = n.Phrase(r, distance)
+		}
+	}
+	return n, err
+}
+
+func (p *tsQueryParser) not() (*TSQueryNode, error) {
+	if p.accept('!') {
+		n, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return n.Not(), nil
+	}
+	if p.accept('(') {
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(')') {
+			return nil, p.errorf("expected %q", ')')
+		}
+		return n, nil
+	}
+	return p.operand()
+}
+
+// operand parses a lexeme, quoted or not, with an optional suffix of a
+// prefix marker and weights, such as 'sup':*AB.
+func (p *tsQueryParser) operand() (*TSQueryNode, error) {
+	if p.skipSpace(); p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of input")
+	}
+
+	var lexeme []byte
+	if p.src[p.pos] == '\'' {
+		s, end, err := parseTSLexeme(p.src, p.pos)
+		if err != nil {
+			return nil, parseError("TSQuery", p.src, p.pos, err)
+		}
+		lexeme, p.pos = []byte(s), end
+	} else {
+	Word:
+		for ; p.pos < len(p.src); p.pos++ {
+			switch c := p.src[p.pos]; c {
+			case ' ', '\t', '\n', '\r', '\v', '\f', ':', '&', '|', '!', '(', ')', '<', '\'':
+				break Word
+			case '\\':
+				if p.pos+1 < len(p.src) {
+					p.pos++
+				}
+			}
+			lexeme = append(lexeme, p.src[p.pos])
+		}
+		if len(lexeme) == 0 {
+			return nil, p.errorf("unexpected %q", p.src[p.pos])
+		}
+	}
+
+	n := TSLexeme(string(lexeme))
+	if p.pos < len(p.src) && p.src[p.pos] == ':' {
+		for p.pos++; p.pos < len(p.src); p.pos++ {
+			c := p.src[p.pos]
+			if c == '*' {
+				n.Prefix = true
+			} else if w := c &^ 0x20; w >= 'A' && w <= 'D' {
+				if strings.IndexByte(n.Weights, w) < 0 {
+					n.Weights += string(w)
+				}
+			} else {
+				break
+			}
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,69 @@
+package pg
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestTSQueryScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(TSQuery), ``, ""},
+		{new(TSQuery), `'a'`, ""},
+		{new(TSQuery), `'fat' & 'rat'`, ""},
+		{new(TSQuery), `'fat' & ( 'rat' | 'cat' )`, ""},
+		{new(TSQuery), `'fat' | 'rat' & 'cat'`, ""},
+		{new(TSQuery), `!'a' <-> 'b'`, ""},
+		{new(TSQuery), `!( 'a' <-> 'b' )`, ""},
+		{new(TSQuery), `'a':*AB & 'b'`, ""},
+		{new(TSQuery), `'a':* | 'b':C`, ""},
+		{new(TSQuery), `'a' <2> 'b'`, ""},
+		{new(TSQuery), `'a' <-> 'b' <-> 'c'`, ""},
+		{new(TSQuery), `'a' <-> ( 'b' <-> 'c' )`, ""},
+		{new(TSQuery), `( 'a' | 'b' ) <-> 'c'`, ""},
+		{new(TSQuery), `'it''s' & 'a\\b'`, ""},
+		{new(TSQuery), `!!'a'`, ""},
+		{new(TSQuery), `(('a'))&'b'`, `'a' & 'b'`},
+	})
+}
+
+func TestTSQueryScan(t *testing.T) {
+	var q TSQuery
+	if err := q.Scan(`'fat':*A & !( 'rat' <3> 'cat' )`); err != nil {
+		t.Fatal(err)
+	}
+	fat := TSLexeme("fat")
+	fat.Prefix = true
+	fat.Weights = "A"
+	want := fat.And(TSLexeme("rat").Phrase(TSLexeme("cat"), 3).Not())
+	if !reflect.DeepEqual(q.Root, want) {
+		t.Fatalf("Scan = %+v, want %+v", q.Root, want)
+	}
+}
+
+func TestTSQueryScanInvalid(t *testing.T) {
+	testScanInvalid(t, func() sql.Scanner { return new(TSQuery) },
+		`'a`,
+		`'a' &`,
+		`& 'a'`,
+		`( 'a'`,
+		`'a' )`,
+		`'a' 'b'`,
+		`'a' <x> 'b'`,
+		`'a' <16385> 'b'`,
+		`'a':E`,
+	)
+}
+
+func TestTSQueryValueInvalid(t *testing.T) {
+	for _, q := range []TSQuery{
+		{Root: TSLexeme("")},
+		{Root: TSLexeme("a").And(nil)},
+		{Root: TSLexeme("a").Phrase(TSLexeme("b"), -1)},
+		{Root: &TSQueryNode{Op: TSQueryLexeme, Lexeme: "a", Weights: "E"}},
+	} {
+		if v, err := q.Value(); err == nil {
+			t.Errorf("Value of %+v = %q, want error", q.Root, v)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// TSPosition is a position of a lexeme in a tsvector, with its weight: one
+// of 'A', 'B', 'C' or 'D', the default.
+type TSPosition struct {
+	Pos    uint16
+	Weight byte
+}
+
+// TSVector represents a PostgreSQL tsvector value, such as
+// `'fat':2,4A 'rat':3`, as a map from each lexeme to its positions, which
+// may be empty. Use a *TSVector to scan nullable columns.
+type TSVector struct {
+	Lexemes map[string][]TSPosition
+}
+
+// Scan implements the sql.Scanner interface.
+func (v *TSVector) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return v.scanBytes(src)
+	case string:
+		return v.scanBytes([]byte(src))
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to TSVector", src)
+}
+
+func (v *TSVector) scanBytes(src []byte) error {
+	lexemes := map[string][]TSPosition{}
+	for i := skipSpace(src, 0); i < len(src); i = skipSpace(src, i) {
+		lexeme, j, err := parseTSLexeme(src, i)
+		if err != nil {
+			return parseError("TSVector", src, -1, err)
+		}
+		positions := lexemes[lexeme]
+		if j < len(src) && src[j] == ':' {
+			if positions, j, err = parseTSPositions(src, j+1, positions); err != nil {
+				return parseError("TSVector", src, -1, err)
+			}
+		}
+		if positions == nil {
+			positions = []TSPosition{}
+		}
+		lexemes[lexeme] = positions
+		if j < len(src) && !isTSSpace(src[j]) {
+			return parseErrorf("TSVector", src, j, "unexpected %q", src[j])
+		}
+		i = j
+	}
+
+	v.Lexemes = lexemes
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (v TSVector) Value() (driver.Value, error) {
+	for lexeme, positions := range v.Lexemes {
+		if lexeme == "" {
+			return nil, fmt.Errorf("pg: invalid TSVector: empty lexeme")
+		}
+		for _, p := range positions {
+			if p.Pos < 1 || p.Pos > 16383 {
+				return nil, fmt.Errorf("pg: invalid TSVector: position %d of %q out of range", p.Pos, lexeme)
+			}
+			if p.Weight != 0 && (p.Weight < 'A' || p.Weight > 'D') {
+				return nil, fmt.Errorf("pg: invalid TSVector: weight %q of %q is not one of A, B, C, D", p.Weight, lexeme)
+			}
+		}
+	}
+
+	return v.String(), nil
+}
+
+// String returns v in the server's output format, with lexemes sorted.
+func (v TSVector) String() string {
+	lexemes := make([]string, 0, len(v.Lexemes))
+	for lexeme := range v.Lexemes {
+		lexemes = append(lexemes, lexeme)
+	}
+	sort.Strings(lexemes)
+
+	var b []byte
+	for i, lexeme := range lexemes {
+		if i > 0 {
+			b = append(b, ' ')
+		}
+		b = appendTSLexeme(b, lexeme)
+		for j, p := range v.Lexemes[lexeme] {
+			if j == 0 {
+				b = append(b, ':')
+			} else {
+				b = append(b, ',')
+			}
+			b = strconv.AppendUint(b, uint64(p.Pos), 10)
+			if p.Weight != 0 && p.Weight != 'D' {
+				b = append(b, p.Weight)
+			}
+		}
+	}
+	return string(b)
+}
+
+func isTSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// parseTSLexeme parses a lexeme starting at offset i, either quoted with
+// single quotes, in which a quote is doubled, or unquoted. In both a
+// backslash escapes the next character.
+func parseTSLexeme(s []byte, i int) (string, int, error) {
+	var lexeme []byte
+	if s[i] == '\'' {
+		for i++; ; i++ {
+			if i >= len(s) {
+				return "", i, fmt.Errorf("unterminated quoted lexeme")
+			}
+			switch c := s[i]; {
+			case c == '\\' && i+1 < len(s):
+				i++
+				lexeme = append(lexeme, s[i])
+			case c == '\'' && i+1 < len(s) && s[i+1] == '\'':
+				i++
+				lexeme = append(lexeme, '\'')
+			case c == '\'':
+				if len(lexeme) == 0 {
+					return "", i, fmt.Errorf("empty lexeme at offset %d", i)
+				}
+				return string(lexeme), i + 1, nil
+			default:
+				lexeme = append(lexeme, c)
+			}
+		}
+	}
+
+	start := i
+	for ; i < len(s) && !isTSSpace(s[i]) && s[i] != ':'; i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		} else if s[i] == '\'' {
+			return "", i, fmt.Errorf("unexpected %q at offset %d", s[i], i)
+		}
+		lexeme = append(lexeme, s[i])
+	}
+	if i == start {
+		return "", i, fmt.Errorf("unexpected %q at offset %d", s[i], i)
+	}
+	return string(lexeme), i, nil
+}
+
+// parseTSPositions parses a comma-separated list of positions with optional
+// weights, such as "1,3A", starting at offset i.
+func parseTSPositions(s []byte, i int, positions []TSPosition) ([]TSPosition, int, error) {
+	for {
+		pos, j, err := parseDigits(s, i, -1)
+		if err != nil {
+			return nil, j, err
+		}
+		if pos < 1 || pos > 16383 {
+			return nil, j, fmt.Errorf("position %d out of range at offset %d", pos, i)
+		}
+		p := TSPosition{Pos: uint16(pos), Weight: 'D'}
+		if j < len(s) {
+			switch c := s[j] &^ 0x20; c {
+			case 'A', 'B', 'C', 'D':
+				p.Weight = c
+				j++
+			}
+		}
+		positions = append(positions, p)
+		if j >= len(s) || s[j] != ',' {
+			return positions, j, nil
+		}
+		i = j + 1
+	}
+}
+
+// appendTSLexeme appends lexeme quoted with single quotes, doubling quotes
+// and backslashes inside it.
+func appendTSLexeme(b []byte, lexeme string) []byte {
+	b = append(b, '\'')
+	for i := 0; i < len(lexeme); i++ {
+		if c := lexeme[i]; c == '\'' || c == '\\' {
+			b = append(b, c)
+		}
+		b = append(b, lexeme[i])
+	}
+	return append(b, '\'')
+}
@@ -0,0 +1,64 @@
+package pg
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestTSVectorScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(TSVector), ``, ""},
+		{new(TSVector), `'a' 'and' 'ate' 'cat' 'fat' 'mat' 'on' 'rat' 'sat'`, ""},
+		{new(TSVector), `'a':1A,2 'fat':2,4 'rat':3`, ""},
+		{new(TSVector), `'a':1B,2C,3D`, `'a':1B,2C,3`},
+		{new(TSVector), `'Joe''s' 'a\\b' 'x y'`, ""},
+		{new(TSVector), `'b' 'a'`, `'a' 'b'`},
+		{new(TSVector), `  'a'  `, `'a'`},
+	})
+}
+
+func TestTSVectorScan(t *testing.T) {
+	var v TSVector
+	if err := v.Scan(`'a':1A,2 'it''s' 'fat':16383D`); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]TSPosition{
+		"a":    {{Pos: 1, Weight: 'A'}, {Pos: 2, Weight: 'D'}},
+		"it's": nil,
+		"fat":  {{Pos: 16383, Weight: 'D'}},
+	}
+	if len(v.Lexemes) != len(want) {
+		t.Fatalf("Scan = %+v, want %+v", v.Lexemes, want)
+	}
+	for lexeme, positions := range want {
+		got, ok := v.Lexemes[lexeme]
+		if !ok || len(got) != len(positions) || len(got) > 0 && !reflect.DeepEqual(got, positions) {
+			t.Errorf("positions of %q = %+v, want %+v", lexeme, got, positions)
+		}
+	}
+}
+
+func TestTSVectorScanInvalid(t *testing.T) {
+	testScanInvalid(t, func() sql.Scanner { return new(TSVector) },
+		`'a`,
+		`'a':`,
+		`'a':0`,
+		`'a':1E`,
+		`'a':,1`,
+		`'a'x`,
+	)
+}
+
+func TestTSVectorValueInvalid(t *testing.T) {
+	for _, v := range []TSVector{
+		{Lexemes: map[string][]TSPosition{"": nil}},
+		{Lexemes: map[string][]TSPosition{"a": {{Pos: 0}}}},
+		{Lexemes: map[string][]TSPosition{"a": {{Pos: 16384}}}},
+		{Lexemes: map[string][]TSPosition{"a": {{Pos: 1, Weight: 'E'}}}},
+	} {
+		if _, err := v.Value(); err == nil {
+			t.Errorf("Value of %+v: no error", v)
+		}
+	}
+}
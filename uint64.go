@@ -0,0 +1,61 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Uint64 represents an unsigned 64-bit integer stored in a numeric or
+// bigint column. It is sent as a numeric literal, so that values above
+// math.MaxInt64 can be bound, and scanning checks that the value is in
+// range. Use a *Uint64 to scan nullable columns.
+type Uint64 uint64
+
+// Scan implements the sql.Scanner interface.
+func (u *Uint64) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case int64:
+		if src < 0 {
+			return fmt.Errorf("pg: value %d out of range for Uint64", src)
+		}
+		*u = Uint64(src)
+		return nil
+	case []byte:
+		return u.scanString(string(src))
+	case string:
+		return u.scanString(src)
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to Uint64", src)
+}
+
+func (u *Uint64) scanString(src string) error {
+	s := strings.TrimSpace(src)
+	// A numeric column with a scale outputs a zero fraction.
+	if i := strings.IndexByte(s, '.'); i >= 0 && strings.Trim(s[i+1:], "0") == "" {
+		s = s[:i]
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		if e, ok := err.(*strconv.NumError); ok && e.Err == strconv.ErrRange || len(s) > 1 && s[0] == '-' && isDigits(s[1:]) {
+			return fmt.Errorf("pg: value %s out of range for Uint64", src)
+		}
+		return parseError("Uint64", src, -1, nil)
+	}
+
+	*u = Uint64(v)
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (u Uint64) Value() (driver.Value, error) {
+	return strconv.FormatUint(uint64(u), 10), nil
+}
+
+// AppendValue appends the text of u to dst, like Value but without
+// allocating a string.
+func (u Uint64) AppendValue(dst []byte) ([]byte, error) {
+	return strconv.AppendUint(dst, uint64(u), 10), nil
+}
@@ -0,0 +1,45 @@
+package pg
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestUint64ScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(Uint64), `0`, ""},
+		{new(Uint64), `42`, ""},
+		{new(Uint64), `18446744073709551615`, ""},
+		{new(Uint64), `42.000`, `42`},
+		{new(Uint64), `42.`, `42`},
+		{new(Uint64), ` 7 `, `7`},
+	})
+	testScanInvalid(t, func() sql.Scanner { return new(Uint64) },
+		``,
+		`-1`,
+		`18446744073709551616`,
+		`1.5`,
+		`1e3`,
+		`abc`,
+	)
+	if err := new(Uint64).Scan(nil); err == nil {
+		t.Error("expected error scanning NULL into Uint64")
+	}
+}
+
+func TestUint64ScanInt64(t *testing.T) {
+	var u Uint64
+	if err := u.Scan(int64(1 << 62)); err != nil || u != 1<<62 {
+		t.Errorf("Scan(int64) = %d, %v", u, err)
+	}
+	if err := u.Scan(int64(-1)); err == nil {
+		t.Error("expected error for a negative int64")
+	}
+
+	for _, src := range []string{`-1`, `18446744073709551616`} {
+		if err := u.Scan(src); err == nil || !strings.Contains(err.Error(), "out of range") {
+			t.Errorf("Scan(%s) = %v, want out of range error", src, err)
+		}
+	}
+}
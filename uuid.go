@@ -0,0 +1,115 @@
+package pg
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// UUID represents a PostgreSQL uuid value. Use a *UUID to scan nullable
+// columns.
+type UUID [16]byte
+
+// ParseUUID parses a UUID in any of the input formats accepted by the
+// server: 32 hex digits of either case, optionally enclosed in braces and
+// with a hyphen after any group of four digits, such as
+// "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11" or
+// "{a0eebc999c0b4ef8bb6d6bb9bd380a11}".
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	src := s
+	s = strings.TrimSpace(s)
+	braces := strings.HasPrefix(s, "{")
+	if braces {
+		if !strings.HasSuffix(s, "}") {
+			return u, parseErrorf("UUID", src, -1, "expected %q", '}')
+		}
+		s = s[1 : len(s)-1]
+	}
+
+	n := 0
+	for i := 0; i < len(s); {
+		if s[i] == '-' && n > 0 && n < 32 && n%4 == 0 && s[i-1] != '-' {
+			i++
+			continue
+		}
+		if n == 32 || i+2 > len(s) {
+			return u, parseErrorf("UUID", src, -1, "invalid length")
+		}
+		hi, ok1 := unhex(s[i])
+		lo, ok2 := unhex(s[i+1])
+		if !ok1 || !ok2 {
+			return u, parseErrorf("UUID", src, -1, "invalid hex digit")
+		}
+		u[n/2] = hi<<4 | lo
+		n += 2
+		i += 2
+	}
+	if n != 32 {
+		return u, parseErrorf("UUID", src, -1, "invalid length")
+	}
+
+	return u, nil
+}
+
+// Scan implements the sql.Scanner interface. Besides the text format, the
+// 16 bytes of the binary format are accepted as well.
+func (u *UUID) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		if len(src) == 16 {
+			copy(u[:], src)
+			return nil
+		}
+		return u.scanString(string(src))
+	case string:
+		return u.scanString(src)
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to UUID", src)
+}
+
+func (u *UUID) scanString(src string) error {
+	v, err := ParseUUID(src)
+	if err != nil {
+		return err
+	}
+
+	*u = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (u UUID) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+// AppendValue appends the text of u to dst, like Value but without
+// allocating a string.
+func (u UUID) AppendValue(dst []byte) ([]byte, error) {
+	return u.appendText(dst), nil
+}
+
+// String returns u in the canonical lowercase form, such as
+// "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11".
+func (u UUID) String() string {
+	var b [36]byte
+	return string(u.appendText(b[:0]))
+}
+
+func (u UUID) appendText(dst []byte) []byte {
+	n := len(dst)
+	dst = append(dst, make([]byte, 36)...)
+	b := dst[n:]
+	hex.Encode(b[0:8], u[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return dst
+}
@@ -0,0 +1,49 @@
+package pg
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestUUIDScanValue(t *testing.T) {
+	const canonical = `a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11`
+	testScanValue(t, []scanValueTest{
+		{new(UUID), canonical, ""},
+		{new(UUID), `A0EEBC99-9C0B-4EF8-BB6D-6BB9BD380A11`, canonical},
+		{new(UUID), `{a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11}`, canonical},
+		{new(UUID), `a0eebc999c0b4ef8bb6d6bb9bd380a11`, canonical},
+		{new(UUID), `a0ee-bc99-9c0b-4ef8-bb6d-6bb9-bd38-0a11`, canonical},
+		{new(UUID), `{a0eebc999c0b4ef8bb6d6bb9bd380a11}`, canonical},
+	})
+}
+
+func TestUUIDScanBinary(t *testing.T) {
+	src := []byte{
+		0xa0, 0xee, 0xbc, 0x99, 0x9c, 0x0b, 0x4e, 0xf8,
+		0xbb, 0x6d, 0x6b, 0xb9, 0xbd, 0x38, 0x0a, 0x11,
+	}
+	var u UUID
+	if err := u.Scan(src); err != nil {
+		t.Fatal(err)
+	}
+	if got := u.String(); got != "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestUUIDScanInvalid(t *testing.T) {
+	testScanInvalid(t, func() sql.Scanner { return new(UUID) },
+		``,
+		`a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a1`,
+		`a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a111`,
+		`a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a1g`,
+		`{a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11`,
+		`-a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11`,
+		`a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11-`,
+		`a0eebc99--9c0b-4ef8-bb6d-6bb9bd380a11`,
+		`a0eeb-c99-9c0b-4ef8-bb6d-6bb9bd380a11`,
+	)
+	if err := new(UUID).Scan(nil); err == nil {
+		t.Error("expected error scanning NULL into UUID")
+	}
+}
@@ -0,0 +1,68 @@
+package pg
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// XML represents a PostgreSQL xml value. Like the default XMLOPTION CONTENT
+// setting of the server, a value may be a document or a content fragment,
+// such as text with several top-level elements. A nil Bytes is NULL.
+type XML struct {
+	Bytes []byte
+}
+
+// Scan implements the sql.Scanner interface.
+func (x *XML) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		x.Bytes = append([]byte{}, src...)
+		return nil
+	case string:
+		x.Bytes = []byte(src)
+		return nil
+	case nil:
+		x.Bytes = nil
+		return nil
+	}
+
+	return fmt.Errorf("pg: cannot convert %T to XML", src)
+}
+
+// Value implements the driver.Valuer interface. It returns an error if the
+// value is not well-formed.
+func (x XML) Value() (driver.Value, error) {
+	if x.Bytes == nil {
+		return nil, nil
+	}
+	if err := checkXML(x.Bytes); err != nil {
+		return nil, fmt.Errorf("pg: invalid XML: %w", err)
+	}
+
+	return string(x.Bytes), nil
+}
+
+// Unmarshal parses the value into v using encoding/xml.
+func (x XML) Unmarshal(v interface{}) error {
+	if x.Bytes == nil {
+		return fmt.Errorf("pg: cannot unmarshal NULL XML")
+	}
+	return xml.Unmarshal(x.Bytes, v)
+}
+
+// checkXML checks that b is well-formed XML content.
+func checkXML(b []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		if _, err := d.Token(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package pg
+
+import "testing"
+
+func TestXMLScanValue(t *testing.T) {
+	testScanValue(t, []scanValueTest{
+		{new(XML), `<a/>`, ""},
+		{new(XML), `<?xml version="1.0"?><book><title>Go</title></book>`, ""},
+		{new(XML), `text <b>bold</b> and <i>italic</i>`, ""},
+		{new(XML), ``, ""},
+	})
+	testScanNull(t, new(XML))
+}
+
+func TestXMLScanCopies(t *testing.T) {
+	src := []byte(`<a/>`)
+	var x XML
+	if err := x.Scan(src); err != nil {
+		t.Fatal(err)
+	}
+	src[1] = 'b'
+	if string(x.Bytes) != `<a/>` {
+		t.Errorf("Scan did not copy its source: %s", x.Bytes)
+	}
+}
+
+func TestXMLValueInvalid(t *testing.T) {
+	for _, s := range []string{
+		`<a>`,
+		`<a></b>`,
+		`<a x=1/>`,
+		`a & b`,
+	} {
+		if v, err := (XML{Bytes: []byte(s)}).Value(); err == nil {
+			t.Errorf("Value of %s = %v, want error", s, v)
+		}
+	}
+}
+
+func TestXMLUnmarshal(t *testing.T) {
+	var book struct {
+		Title string `xml:"title"`
+	}
+	x := XML{Bytes: []byte(`<book><title>Go</title></book>`)}
+	if err := x.Unmarshal(&book); err != nil || book.Title != "Go" {
+		t.Errorf("Unmarshal = %+v, %v", book, err)
+	}
+	if err := (XML{}).Unmarshal(&book); err == nil {
+		t.Error("expected error unmarshaling NULL")
+	}
+}